@@ -56,6 +56,14 @@ const (
 	// position, zoom) (ECMA-376 §2.4.825, record ID 0x019E).
 	WorkbookView = 0x019E
 
+	// BookProtection records workbook structure and window protection with
+	// legacy password verifiers (MS-XLSB BrtBookProtection, record ID 0x0496).
+	BookProtection = 0x0496
+
+	// BookProtectionIso records workbook protection with hashed passwords
+	// (MS-XLSB BrtBookProtectionIso, record ID 0x0895).
+	BookProtectionIso = 0x0895
+
 	// ExternalReferences marks the start of the external-references collection
 	// (ECMA-376 §2.4.148, record ID 0x02E1).
 	ExternalReferences = 0x02E1
@@ -68,6 +76,28 @@ const (
 	// (ECMA-376 §2.4.147, record ID 0x02E3).
 	ExternalReference = 0x02E3
 
+	// ExternalSelf records a supporting link that refers to the current
+	// workbook; XTI entries that point at it resolve to local sheets
+	// (MS-XLSB BrtSupSelf, record ID 0x02E5).
+	ExternalSelf = 0x02E5
+
+	// ExternalSame records a supporting link that refers to the current
+	// workbook for same-sheet references (MS-XLSB BrtSupSame, record ID 0x02E6).
+	ExternalSame = 0x02E6
+
+	// ExternalSheetNames lists the sheet names of an external workbook inside
+	// an external-link part (MS-XLSB BrtSupTabs, record ID 0x02E7).
+	ExternalSheetNames = 0x02E7
+
+	// ExternSheet records the XTI table that maps the ixti operand of 3-D
+	// formula references to a supporting link and sheet range
+	// (MS-XLSB BrtExternSheet, record ID 0x02EA).
+	ExternSheet = 0x02EA
+
+	// ExternalAddin records a supporting link for add-in functions
+	// (MS-XLSB BrtSupAddin, record ID 0x059B).
+	ExternalAddin = 0x059B
+
 	// WebPublishing carries web-publishing properties for the workbook
 	// (ECMA-376 §2.4.803, record ID 0x04A9).
 	WebPublishing = 0x04A9
@@ -98,6 +128,11 @@ const (
 	// (ECMA-376 §2.4.175, record ID 0x0005).
 	Float = 0x0005
 
+	// CellSt records a cell whose value is an inline XLWideString stored in the
+	// record itself rather than in the shared-string table
+	// (MS-XLSB BrtCellSt, record ID 0x0006).
+	CellSt = 0x0006
+
 	// String records a cell whose value is an index into the shared-string table
 	// (ECMA-376 §2.4.752, record ID 0x0007).
 	String = 0x0007
@@ -118,6 +153,18 @@ const (
 	// (ECMA-376 §2.4.195, record ID 0x000B).
 	FormulaBoolErr = 0x000B
 
+	// CellRString records a cell whose value is an inline rich string (RichStr)
+	// with optional formatting runs (MS-XLSB BrtCellRString, record ID 0x003E).
+	CellRString = 0x003E
+
+	// ArrFmla records an array formula; it immediately follows the cell record
+	// of the array's top-left anchor (MS-XLSB BrtArrFmla, record ID 0x03AA).
+	ArrFmla = 0x03AA
+
+	// ShrFmla records a shared formula; it immediately follows the cell record
+	// of the shared range's top-left anchor (MS-XLSB BrtShrFmla, record ID 0x03AB).
+	ShrFmla = 0x03AB
+
 	// Col records a column-definition entry (width, style, range)
 	// (ECMA-376 §2.4.60, record ID 0x003C).
 	Col = 0x003C
@@ -162,6 +209,10 @@ const (
 	// column, 0-based) (ECMA-376 §2.4.114, record ID 0x0194).
 	Dimension = 0x0194
 
+	// Pane records the split or frozen panes of the enclosing sheet view
+	// (MS-XLSB BrtPane, record ID 0x0197).
+	Pane = 0x0197
+
 	// Selection records the currently selected cell range in a sheet view
 	// (ECMA-376 §2.4.690, record ID 0x0198).
 	Selection = 0x0198
@@ -174,6 +225,44 @@ const (
 	// (ECMA-376 §2.4.62, record ID 0x0387).
 	ColsEnd = 0x0387
 
+	// RowBreaks marks the start of the horizontal page-break collection
+	// (MS-XLSB BrtBeginRwBrk, record ID 0x0388).
+	RowBreaks = 0x0388
+
+	// RowBreaksEnd marks the end of the horizontal page-break collection
+	// (MS-XLSB BrtEndRwBrk, record ID 0x0389).
+	RowBreaksEnd = 0x0389
+
+	// ColBreaks marks the start of the vertical page-break collection
+	// (MS-XLSB BrtBeginColBrk, record ID 0x038A).
+	ColBreaks = 0x038A
+
+	// ColBreaksEnd marks the end of the vertical page-break collection
+	// (MS-XLSB BrtEndColBrk, record ID 0x038B).
+	ColBreaksEnd = 0x038B
+
+	// Break records one page break inside a RowBreaks or ColBreaks
+	// collection (MS-XLSB BrtBrk, record ID 0x038C).
+	Break = 0x038C
+
+	// SheetProtection records sheet protection with a legacy password
+	// verifier (MS-XLSB BrtSheetProtection, record ID 0x0497).
+	SheetProtection = 0x0497
+
+	// SheetProtectionIso records sheet protection with a hashed password
+	// (MS-XLSB BrtSheetProtectionIso, record ID 0x0896).
+	SheetProtectionIso = 0x0896
+
+	// RangeProtection records a range that can be edited on a protected sheet,
+	// with a legacy password verifier (MS-XLSB BrtRangeProtection, record ID
+	// 0x0498).
+	RangeProtection = 0x0498
+
+	// RangeProtectionIso records a range that can be edited on a protected
+	// sheet, with a hashed password (MS-XLSB BrtRangeProtectionIso, record ID
+	// 0x0897).
+	RangeProtectionIso = 0x0897
+
 	// ConditionalFormatting marks the start of a conditional-formatting block
 	// (ECMA-376 §2.4.80, record ID 0x03CD).
 	ConditionalFormatting = 0x03CD
@@ -251,8 +340,9 @@ const (
 	// (ECMA-376 §2.4.326, record ID 0x04A7).
 	LegacyDrawing = 0x04A7
 
-	// Color records a color value used within a conditional-formatting rule
-	// (ECMA-376 §2.4.65, record ID 0x04B4).
+	// Color records one entry of the custom indexed colour palette inside the
+	// Colors collection of the styles part (MS-XLSB BrtIndexedColor, record
+	// ID 0x04B4).
 	Color = 0x04B4
 
 	// OleObjects marks the start of the OLE-objects collection in a worksheet
@@ -0,0 +1,507 @@
+// Package cellref converts between the 0-based row/column indices used
+// throughout this module (Cell.R, Cell.C) and Excel cell references in A1
+// ("$B$3", "Sheet1!A1:C10", "A:A") and R1C1 ("R3C2", "R[-1]C") notation.
+package cellref
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Excel grid limits (0-based, inclusive).
+const (
+	// MaxRow is the last valid row index (row 1,048,576).
+	MaxRow = 0xFFFFF
+	// MaxCol is the last valid column index (column XFD).
+	MaxCol = 0x3FFF
+)
+
+// ColumnName converts a 0-based column index to its letter form (0 → "A",
+// 25 → "Z", 26 → "AA", 16383 → "XFD").  It returns "" for negative indices.
+func ColumnName(col int) string {
+	if col < 0 {
+		return ""
+	}
+	var buf [8]byte
+	i := len(buf)
+	for col >= 0 {
+		i--
+		buf[i] = byte('A' + col%26)
+		col = col/26 - 1
+	}
+	return string(buf[i:])
+}
+
+// ColumnIndex converts column letters ("A", "xfd") to a 0-based index.
+func ColumnIndex(name string) (int, error) {
+	if name == "" || len(name) > 3 {
+		return 0, fmt.Errorf("cellref: invalid column %q", name)
+	}
+	col := 0
+	for i := 0; i < len(name); i++ {
+		b := name[i] | 0x20 // ASCII lower-case
+		if b < 'a' || b > 'z' {
+			return 0, fmt.Errorf("cellref: invalid column %q", name)
+		}
+		col = col*26 + int(b-'a') + 1
+	}
+	if col-1 > MaxCol {
+		return 0, fmt.Errorf("cellref: column %q out of range", name)
+	}
+	return col - 1, nil
+}
+
+// CellName returns the relative A1 name of the cell at (row, col), e.g.
+// CellName(2, 1) == "B3".
+func CellName(row, col int) string {
+	return ColumnName(col) + strconv.Itoa(row+1)
+}
+
+// Ref is a single cell reference.  RowAbs and ColAbs record the "$" markers
+// of A1 notation (absolute references in R1C1 notation).
+type Ref struct {
+	// Row is the 0-based row index.
+	Row int
+	// Col is the 0-based column index.
+	Col int
+	// RowAbs is true when the row is absolute ("A$1").
+	RowAbs bool
+	// ColAbs is true when the column is absolute ("$A1").
+	ColAbs bool
+}
+
+// String renders the reference in A1 notation, including "$" markers.
+func (r Ref) String() string {
+	return dollar(r.ColAbs) + ColumnName(r.Col) + dollar(r.RowAbs) + strconv.Itoa(r.Row+1)
+}
+
+// R1C1 renders the reference in R1C1 notation.  Relative parts are written
+// as offsets from (baseRow, baseCol), the cell containing the reference.
+func (r Ref) R1C1(baseRow, baseCol int) string {
+	return "R" + r1c1Part(r.Row, baseRow, r.RowAbs) + "C" + r1c1Part(r.Col, baseCol, r.ColAbs)
+}
+
+// ParseRef parses a single A1 cell reference such as "B3" or "$B$3".
+func ParseRef(s string) (Ref, error) {
+	ref, hasRow, hasCol, err := parseA1Part(s)
+	if err != nil {
+		return Ref{}, err
+	}
+	if !hasRow || !hasCol {
+		return Ref{}, fmt.Errorf("cellref: %q is not a cell reference", s)
+	}
+	return ref, nil
+}
+
+// Range is a rectangular range, optionally qualified with a sheet name.
+// First is the top-left corner and Last the bottom-right corner; whole
+// columns span rows 0..MaxRow and whole rows span columns 0..MaxCol.
+type Range struct {
+	// Sheet is the sheet name without quotes; empty for an unqualified range.
+	Sheet string
+	// First is the top-left cell.
+	First Ref
+	// Last is the bottom-right cell.
+	Last Ref
+}
+
+// NewRange returns the unqualified range spanning rows r1..r2 and columns
+// c1..c2 (0-based, inclusive) with relative references.
+func NewRange(r1, c1, r2, c2 int) Range {
+	return Range{First: Ref{Row: r1, Col: c1}, Last: Ref{Row: r2, Col: c2}}
+}
+
+// WholeColumns reports whether the range spans every row ("A:C").
+func (r Range) WholeColumns() bool {
+	return r.First.Row == 0 && r.Last.Row == MaxRow
+}
+
+// WholeRows reports whether the range spans every column ("1:3").
+func (r Range) WholeRows() bool {
+	return r.First.Col == 0 && r.Last.Col == MaxCol
+}
+
+// Rows returns the number of rows in the range.
+func (r Range) Rows() int { return r.Last.Row - r.First.Row + 1 }
+
+// Cols returns the number of columns in the range.
+func (r Range) Cols() int { return r.Last.Col - r.First.Col + 1 }
+
+// Contains reports whether the cell at (row, col) lies inside the range.
+func (r Range) Contains(row, col int) bool {
+	return row >= r.First.Row && row <= r.Last.Row && col >= r.First.Col && col <= r.Last.Col
+}
+
+// Cells iterates over the cells of the range in row-major order, yielding
+// 0-based (row, col) pairs.
+//
+//	for row, col := range rng.Cells() { … }
+func (r Range) Cells() func(yield func(row, col int) bool) {
+	return func(yield func(row, col int) bool) {
+		for row := r.First.Row; row <= r.Last.Row; row++ {
+			for col := r.First.Col; col <= r.Last.Col; col++ {
+				if !yield(row, col) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// String renders the range in A1 notation: "A1" for a single cell,
+// "A1:C10" for a block, "A:C" / "1:3" for whole columns / rows, with a
+// (quoted when necessary) sheet prefix when Sheet is set.
+func (r Range) String() string {
+	var s string
+	switch {
+	case r.WholeColumns():
+		s = dollar(r.First.ColAbs) + ColumnName(r.First.Col) + ":" + dollar(r.Last.ColAbs) + ColumnName(r.Last.Col)
+	case r.WholeRows():
+		s = dollar(r.First.RowAbs) + strconv.Itoa(r.First.Row+1) + ":" + dollar(r.Last.RowAbs) + strconv.Itoa(r.Last.Row+1)
+	case r.First == r.Last:
+		s = r.First.String()
+	default:
+		s = r.First.String() + ":" + r.Last.String()
+	}
+	if r.Sheet != "" {
+		s = QuoteSheet(r.Sheet) + "!" + s
+	}
+	return s
+}
+
+// R1C1 renders the range in R1C1 notation relative to (baseRow, baseCol):
+// "R1C1:R10C3", "C1:C3" for whole columns, "R1:R3" for whole rows.
+func (r Range) R1C1(baseRow, baseCol int) string {
+	var s string
+	switch {
+	case r.WholeColumns():
+		s = "C" + r1c1Part(r.First.Col, baseCol, r.First.ColAbs)
+		if r.Last.Col != r.First.Col || r.Last.ColAbs != r.First.ColAbs {
+			s += ":C" + r1c1Part(r.Last.Col, baseCol, r.Last.ColAbs)
+		}
+	case r.WholeRows():
+		s = "R" + r1c1Part(r.First.Row, baseRow, r.First.RowAbs)
+		if r.Last.Row != r.First.Row || r.Last.RowAbs != r.First.RowAbs {
+			s += ":R" + r1c1Part(r.Last.Row, baseRow, r.Last.RowAbs)
+		}
+	case r.First == r.Last:
+		s = r.First.R1C1(baseRow, baseCol)
+	default:
+		s = r.First.R1C1(baseRow, baseCol) + ":" + r.Last.R1C1(baseRow, baseCol)
+	}
+	if r.Sheet != "" {
+		s = QuoteSheet(r.Sheet) + "!" + s
+	}
+	return s
+}
+
+// ParseRange parses an A1 range, optionally sheet-qualified:
+//
+//	"B2", "B2:D10", "$A$1:$C$3", "A:C", "3:5",
+//	"Sheet1!A1:B2", "'Q1 Sales'!A:A"
+//
+// Reversed corners ("D10:B2") are normalised so that First is the top-left.
+func ParseRange(s string) (Range, error) {
+	sheet, body, err := SplitSheet(s)
+	if err != nil {
+		return Range{}, err
+	}
+	first, last, isRange := strings.Cut(body, ":")
+	if !isRange {
+		last = first
+	}
+	a, aRow, aCol, err := parseA1Part(first)
+	if err != nil {
+		return Range{}, err
+	}
+	b, bRow, bCol, err := parseA1Part(last)
+	if err != nil {
+		return Range{}, err
+	}
+	// A column-only or row-only endpoint must be paired with one of the same
+	// kind and is only valid in a range ("A:A", not "A").
+	if aRow != bRow || aCol != bCol || ((!aRow || !aCol) && !isRange) {
+		return Range{}, fmt.Errorf("cellref: %q mixes or lacks row and column parts", s)
+	}
+	if !aRow { // whole columns
+		a.Row, b.Row = 0, MaxRow
+	}
+	if !aCol { // whole rows
+		a.Col, b.Col = 0, MaxCol
+	}
+	return normalise(Range{Sheet: sheet, First: a, Last: b}), nil
+}
+
+// ParseR1C1 parses a range in R1C1 notation, optionally sheet-qualified.
+// Relative parts ("R[-1]", "C[2]", or a bare "R" / "C") are resolved against
+// (baseRow, baseCol).  Accepted forms include "R3C2", "R[1]C[-1]",
+// "R1C1:R10C3", "C2" / "C2:C4" (whole columns) and "R5" / "R5:R7" (whole
+// rows).
+func ParseR1C1(s string, baseRow, baseCol int) (Range, error) {
+	sheet, body, err := SplitSheet(s)
+	if err != nil {
+		return Range{}, err
+	}
+	first, last, isRange := strings.Cut(body, ":")
+	if !isRange {
+		last = first
+	}
+	a, aRow, aCol, err := parseR1C1Part(first, baseRow, baseCol)
+	if err != nil {
+		return Range{}, err
+	}
+	b, bRow, bCol, err := parseR1C1Part(last, baseRow, baseCol)
+	if err != nil {
+		return Range{}, err
+	}
+	if aRow != bRow || aCol != bCol {
+		return Range{}, fmt.Errorf("cellref: %q mixes row and column parts", s)
+	}
+	if !aRow {
+		a.Row, b.Row = 0, MaxRow
+	}
+	if !aCol {
+		a.Col, b.Col = 0, MaxCol
+	}
+	return normalise(Range{Sheet: sheet, First: a, Last: b}), nil
+}
+
+// SplitSheet separates an optional sheet qualifier from a reference:
+// "Sheet1!A1" → ("Sheet1", "A1"), "'Q1 Sales'!A1" → ("Q1 Sales", "A1"),
+// "A1" → ("", "A1").  Quotes embedded in a quoted name are doubled.
+func SplitSheet(s string) (sheet, ref string, err error) {
+	if strings.HasPrefix(s, "'") {
+		var sb strings.Builder
+		for i := 1; i < len(s); i++ {
+			if s[i] != '\'' {
+				sb.WriteByte(s[i])
+				continue
+			}
+			if i+1 < len(s) && s[i+1] == '\'' {
+				sb.WriteByte('\'')
+				i++
+				continue
+			}
+			if i+1 < len(s) && s[i+1] == '!' && sb.Len() > 0 {
+				return sb.String(), s[i+2:], nil
+			}
+			break
+		}
+		return "", "", fmt.Errorf("cellref: %q has a malformed quoted sheet name", s)
+	}
+	i := strings.LastIndexByte(s, '!')
+	if i < 0 {
+		return "", s, nil
+	}
+	if i == 0 {
+		return "", "", fmt.Errorf("cellref: %q has an empty sheet name", s)
+	}
+	return s[:i], s[i+1:], nil
+}
+
+// QuoteSheet returns name as it must appear before "!" in a reference:
+// unchanged when it needs no quoting, otherwise enclosed in single quotes
+// with embedded quotes doubled.
+func QuoteSheet(name string) string {
+	if !NeedsQuote(name) {
+		return name
+	}
+	return "'" + strings.ReplaceAll(name, "'", "''") + "'"
+}
+
+// NeedsQuote reports whether a sheet name must be enclosed in single quotes
+// in a reference: anything other than letters, digits, underscores and
+// periods, a leading digit, or a name that could be read as a cell
+// reference (A1 or R1C1 style).
+func NeedsQuote(name string) bool {
+	if name == "" {
+		return false
+	}
+	if name[0] >= '0' && name[0] <= '9' {
+		return true
+	}
+	for _, r := range name {
+		switch {
+		case r == '_' || r == '.':
+		case r >= '0' && r <= '9':
+		case r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z':
+		case r > 0x7F:
+			// Non-ASCII letters are allowed unquoted by Excel.
+		default:
+			return true
+		}
+	}
+	return looksLikeCellRef(name)
+}
+
+// ── internal helpers ──────────────────────────────────────────────────────────
+
+func dollar(abs bool) string {
+	if abs {
+		return "$"
+	}
+	return ""
+}
+
+// r1c1Part renders one R1C1 component: "3" for absolute index 2, "" for a
+// zero offset, "[-1]" for a relative offset.
+func r1c1Part(v, base int, abs bool) string {
+	if abs {
+		return strconv.Itoa(v + 1)
+	}
+	if v == base {
+		return ""
+	}
+	return "[" + strconv.Itoa(v-base) + "]"
+}
+
+// normalise orders the corners of r so that First is the top-left.
+func normalise(r Range) Range {
+	if r.First.Row > r.Last.Row {
+		r.First.Row, r.Last.Row = r.Last.Row, r.First.Row
+		r.First.RowAbs, r.Last.RowAbs = r.Last.RowAbs, r.First.RowAbs
+	}
+	if r.First.Col > r.Last.Col {
+		r.First.Col, r.Last.Col = r.Last.Col, r.First.Col
+		r.First.ColAbs, r.Last.ColAbs = r.Last.ColAbs, r.First.ColAbs
+	}
+	return r
+}
+
+// parseA1Part parses one endpoint of an A1 range: "B2", "$B$2", "B" (no
+// row) or "2" (no column).
+func parseA1Part(s string) (ref Ref, hasRow, hasCol bool, err error) {
+	rest := s
+	if strings.HasPrefix(rest, "$") {
+		ref.ColAbs = true
+		rest = rest[1:]
+	}
+	i := 0
+	for i < len(rest) && ((rest[i] >= 'A' && rest[i] <= 'Z') || (rest[i] >= 'a' && rest[i] <= 'z')) {
+		i++
+	}
+	letters, digits := rest[:i], rest[i:]
+	if letters == "" {
+		// "$3" is an absolute row, not an absolute column.
+		ref.RowAbs, ref.ColAbs = ref.ColAbs, false
+	} else if strings.HasPrefix(digits, "$") {
+		ref.RowAbs = true
+		digits = digits[1:]
+	}
+	if letters == "" && digits == "" {
+		return Ref{}, false, false, fmt.Errorf("cellref: invalid reference %q", s)
+	}
+	if letters != "" {
+		if ref.Col, err = ColumnIndex(letters); err != nil {
+			return Ref{}, false, false, err
+		}
+		hasCol = true
+	}
+	if digits != "" {
+		n, err := parseIndex(digits, MaxRow)
+		if err != nil {
+			return Ref{}, false, false, fmt.Errorf("cellref: invalid row in %q", s)
+		}
+		ref.Row = n
+		hasRow = true
+	}
+	return ref, hasRow, hasCol, nil
+}
+
+// parseR1C1Part parses one endpoint of an R1C1 range.
+func parseR1C1Part(s string, baseRow, baseCol int) (ref Ref, hasRow, hasCol bool, err error) {
+	rest := strings.ToUpper(s)
+	if strings.HasPrefix(rest, "R") {
+		hasRow = true
+		ref.Row, ref.RowAbs, rest, err = parseR1C1Component(rest[1:], baseRow, MaxRow)
+		if err != nil {
+			return Ref{}, false, false, fmt.Errorf("cellref: invalid row in %q", s)
+		}
+	}
+	if strings.HasPrefix(rest, "C") {
+		hasCol = true
+		ref.Col, ref.ColAbs, rest, err = parseR1C1Component(rest[1:], baseCol, MaxCol)
+		if err != nil {
+			return Ref{}, false, false, fmt.Errorf("cellref: invalid column in %q", s)
+		}
+	}
+	if rest != "" || (!hasRow && !hasCol) {
+		return Ref{}, false, false, fmt.Errorf("cellref: invalid R1C1 reference %q", s)
+	}
+	return ref, hasRow, hasCol, nil
+}
+
+// parseR1C1Component parses the part after "R" or "C": digits (absolute),
+// "[n]" (relative offset), or nothing (offset 0).  It returns the unparsed
+// remainder.
+func parseR1C1Component(s string, base, maxIdx int) (idx int, abs bool, rest string, err error) {
+	if strings.HasPrefix(s, "[") {
+		end := strings.IndexByte(s, ']')
+		if end < 0 {
+			return 0, false, "", fmt.Errorf("unterminated offset")
+		}
+		off, err := strconv.Atoi(s[1:end])
+		if err != nil {
+			return 0, false, "", err
+		}
+		idx = base + off
+		if idx < 0 || idx > maxIdx {
+			return 0, false, "", fmt.Errorf("offset out of range")
+		}
+		return idx, false, s[end+1:], nil
+	}
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	if i == 0 {
+		return base, false, s, nil
+	}
+	idx, err = parseIndex(s[:i], maxIdx)
+	return idx, true, s[i:], err
+}
+
+// parseIndex converts a 1-based decimal index to a 0-based one, checking it
+// against maxIdx.
+func parseIndex(digits string, maxIdx int) (int, error) {
+	for i := 0; i < len(digits); i++ {
+		if digits[i] < '0' || digits[i] > '9' {
+			return 0, fmt.Errorf("cellref: invalid index %q", digits)
+		}
+	}
+	n, err := strconv.Atoi(digits)
+	if err != nil || n < 1 || n-1 > maxIdx {
+		return 0, fmt.Errorf("cellref: index %q out of range", digits)
+	}
+	return n - 1, nil
+}
+
+// looksLikeCellRef reports whether s has the shape of an A1 reference
+// (letters followed by digits) or an R1C1 reference.
+func looksLikeCellRef(s string) bool {
+	u := strings.ToUpper(s)
+	i := 0
+	for i < len(u) && u[i] >= 'A' && u[i] <= 'Z' {
+		i++
+	}
+	if i > 0 && i <= 3 && i < len(u) {
+		j := i
+		for j < len(u) && u[j] >= '0' && u[j] <= '9' {
+			j++
+		}
+		if j == len(u) {
+			return true
+		}
+	}
+	if u == "R" || u == "C" {
+		return true
+	}
+	if u[0] == 'R' || u[0] == 'C' {
+		rest := strings.TrimLeft(u[1:], "0123456789")
+		if rest == "" || (u[0] == 'R' && strings.HasPrefix(rest, "C") && strings.Trim(rest[1:], "0123456789") == "") {
+			return true
+		}
+	}
+	return false
+}
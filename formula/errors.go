@@ -0,0 +1,57 @@
+package formula
+
+import "fmt"
+
+// CellError is an Excel error value such as #DIV/0! or #N/A, identified by
+// its BErr code (MS-XLSB §2.5.97.2).  It appears as the cached result of
+// error cells and formulas (worksheet.Cell.V) and implements error so that
+// it can be returned or compared with errors.Is.
+type CellError byte
+
+// Excel error values.
+const (
+	ErrNull        CellError = 0x00 // #NULL!: intersection of ranges that do not intersect
+	ErrDiv0        CellError = 0x07 // #DIV/0!: division by zero
+	ErrValue       CellError = 0x0F // #VALUE!: wrong type of argument
+	ErrRef         CellError = 0x17 // #REF!: invalid cell reference
+	ErrName        CellError = 0x1D // #NAME?: unrecognised name
+	ErrNum         CellError = 0x24 // #NUM!: invalid numeric value
+	ErrNA          CellError = 0x2A // #N/A: value not available
+	ErrGettingData CellError = 0x2B // #GETTING_DATA: external data still loading
+)
+
+// errTexts maps BErr codes to the text Excel displays.
+var errTexts = map[CellError]string{
+	ErrNull:        "#NULL!",
+	ErrDiv0:        "#DIV/0!",
+	ErrValue:       "#VALUE!",
+	ErrRef:         "#REF!",
+	ErrName:        "#NAME?",
+	ErrNum:         "#NUM!",
+	ErrNA:          "#N/A",
+	ErrGettingData: "#GETTING_DATA",
+}
+
+// Code returns the raw BErr code.
+func (e CellError) Code() byte { return byte(e) }
+
+// String returns the error as Excel displays it, e.g. "#DIV/0!".  Codes
+// outside the defined set render as a hex fallback such as "0xff".
+func (e CellError) String() string {
+	if s, ok := errTexts[e]; ok {
+		return s
+	}
+	return fmt.Sprintf("0x%02x", byte(e))
+}
+
+// Error implements the error interface; it returns the same text as String.
+func (e CellError) Error() string { return e.String() }
+
+// errText returns the formula literal for a BErr code.  Unknown codes render
+// as #N/A so that the formula text stays valid.
+func errText(b byte) string {
+	if s, ok := errTexts[CellError(b)]; ok {
+		return s
+	}
+	return "#N/A"
+}
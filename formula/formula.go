@@ -0,0 +1,792 @@
+// Package formula decompiles BIFF12 parsed-formula token streams (Rgce plus
+// the trailing RgbExtra block) into A1-style Excel formula text such as
+// SUM(A1:B3)*Sheet2!$C$4.
+//
+// Formulas are stored in reverse-Polish order: operand tokens (references,
+// constants, names) push onto a stack and operator / function tokens pop
+// their arguments and push the combined text.  Parentheses are explicit
+// tokens (PtgParen), so the decompiler never has to infer precedence.
+package formula
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/TsubasaBE/go-xlsb/cellref"
+	"github.com/TsubasaBE/go-xlsb/record"
+)
+
+// Extern describes one XTI entry of the workbook's BrtExternSheet table: a
+// single sheet or a 3-D sheet span, optionally in an external workbook.
+type Extern struct {
+	// Book is 0 for the current workbook, or the 1-based index of the
+	// external workbook link (rendered as "[1]", "[2]", …).
+	Book int
+	// First is the name of the first sheet in the span.  It is empty for a
+	// workbook-level reference, in which case no sheet prefix is rendered.
+	First string
+	// Last is the name of the last sheet in the span.  It equals First (or is
+	// empty) for a single-sheet reference.
+	Last string
+}
+
+// Context resolves workbook-level information that a token stream cannot
+// describe on its own.  A nil Context is valid: 3-D references then render
+// as #REF! and defined names as #NAME?.
+type Context interface {
+	// Extern returns the sheet span referenced by XTI index ixti, or false
+	// when the index is unknown or refers to a deleted sheet.
+	Extern(ixti int) (Extern, bool)
+	// Name returns the defined name with the given 1-based index, or false
+	// when the index is out of range.
+	Name(idx int) (string, bool)
+}
+
+// binaryOps maps the binary operator ptgs (0x03–0x11) to their infix text.
+var binaryOps = map[byte]string{
+	0x03: "+",
+	0x04: "-",
+	0x05: "*",
+	0x06: "/",
+	0x07: "^",
+	0x08: "&",
+	0x09: "<",
+	0x0A: "<=",
+	0x0B: "=",
+	0x0C: ">=",
+	0x0D: ">",
+	0x0E: "<>",
+	0x0F: " ",
+	0x10: ",",
+	0x11: ":",
+}
+
+// Read reads a CellParsedFormula / NameParsedFormula structure from rr:
+//
+//	cce   uint32  — byte length of rgce
+//	rgce  [cce]   — the token stream
+//	cb    uint32  — byte length of the extra data
+//	rgcb  [cb]    — RgbExtra (array constants, PtgExp columns, …)
+func Read(rr *record.RecordReader) (rgce, extra []byte, err error) {
+	cce, err := rr.ReadUint32()
+	if err != nil {
+		return nil, nil, fmt.Errorf("formula: read cce: %w", err)
+	}
+	if int64(cce) > int64(rr.Len()) {
+		return nil, nil, fmt.Errorf("formula: cce %d exceeds %d remaining bytes", cce, rr.Len())
+	}
+	rgce = make([]byte, cce)
+	if err := rr.Read(rgce); err != nil {
+		return nil, nil, fmt.Errorf("formula: read rgce: %w", err)
+	}
+	cb, err := rr.ReadUint32()
+	if err != nil {
+		// Some writers omit the trailing cb field when there is no extra data.
+		return rgce, nil, nil
+	}
+	if int64(cb) > int64(rr.Len()) {
+		return nil, nil, fmt.Errorf("formula: cb %d exceeds %d remaining bytes", cb, rr.Len())
+	}
+	extra = make([]byte, cb)
+	if err := rr.Read(extra); err != nil {
+		return nil, nil, fmt.Errorf("formula: read rgcb: %w", err)
+	}
+	return rgce, extra, nil
+}
+
+// ExpAnchor reports whether rgce consists of a single PtgExp token — the
+// placeholder stored in every cell of a shared or array formula — and if so
+// returns the 0-based row and column of the anchor cell whose BrtShrFmla or
+// BrtArrFmla record holds the real token stream.  The row is stored in the
+// token itself; the column follows in the extra data (PtgExtraCol).
+func ExpAnchor(rgce, extra []byte) (row, col int, ok bool) {
+	if len(rgce) != 5 || rgce[0] != 0x01 || len(extra) < 4 {
+		return 0, 0, false
+	}
+	return int(binary.LittleEndian.Uint32(rgce[1:])), int(binary.LittleEndian.Uint32(extra)), true
+}
+
+// Decompile renders the token stream rgce (with its extra data) as formula
+// text without the leading "=".  row and col are the 0-based coordinates of
+// the cell that owns the formula; they are the base for the relative
+// offsets used by shared formulas (PtgRefN / PtgAreaN).
+//
+// An error is returned for malformed streams and for constructs the
+// decompiler does not support (PtgExp placeholders, structured table
+// references, data tables).
+func Decompile(rgce, extra []byte, row, col int, ctx Context) (string, error) {
+	d := &decoder{
+		rgce:  record.NewRecordReader(rgce),
+		extra: record.NewRecordReader(extra),
+		row:   row,
+		col:   col,
+		ctx:   ctx,
+	}
+	for d.rgce.Len() > 0 {
+		ptg, _ := d.rgce.ReadUint8()
+		if err := d.step(ptg); err != nil {
+			return "", fmt.Errorf("formula: ptg 0x%02X: %w", ptg, err)
+		}
+	}
+	if len(d.stack) != 1 {
+		return "", fmt.Errorf("formula: malformed token stream (%d operands left on stack)", len(d.stack))
+	}
+	return d.stack[0], nil
+}
+
+// ── decoder ───────────────────────────────────────────────────────────────────
+
+// decoder holds the state of one Decompile call.
+type decoder struct {
+	rgce     *record.RecordReader
+	extra    *record.RecordReader
+	row, col int
+	ctx      Context
+	stack    []string
+}
+
+func (d *decoder) push(s string) {
+	d.stack = append(d.stack, s)
+}
+
+// pop removes the top n operands and returns them in push order.
+func (d *decoder) pop(n int) ([]string, error) {
+	if n > len(d.stack) {
+		return nil, fmt.Errorf("stack underflow (need %d operands, have %d)", n, len(d.stack))
+	}
+	args := make([]string, n)
+	copy(args, d.stack[len(d.stack)-n:])
+	d.stack = d.stack[:len(d.stack)-n]
+	return args, nil
+}
+
+// step decodes one token whose ptg byte has already been consumed.
+func (d *decoder) step(ptg byte) error {
+	// Operand and function tokens (0x20–0x7F) carry a data-type class in
+	// bits 5–6; fold the three class variants onto the 0x20–0x3F range.
+	if ptg >= 0x40 {
+		ptg = ptg&0x1F | 0x20
+	}
+
+	switch ptg {
+	case 0x01: // PtgExp
+		return fmt.Errorf("shared/array formula placeholder cannot be decompiled on its own")
+	case 0x02: // PtgTbl
+		return fmt.Errorf("data table formulas are not supported")
+
+	case 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0A, 0x0B, 0x0C, 0x0D, 0x0E, 0x0F, 0x10, 0x11:
+		args, err := d.pop(2)
+		if err != nil {
+			return err
+		}
+		d.push(args[0] + binaryOps[ptg] + args[1])
+
+	case 0x12, 0x13, 0x14, 0x15: // PtgUplus, PtgUminus, PtgPercent, PtgParen
+		args, err := d.pop(1)
+		if err != nil {
+			return err
+		}
+		switch ptg {
+		case 0x12:
+			d.push("+" + args[0])
+		case 0x13:
+			d.push("-" + args[0])
+		case 0x14:
+			d.push(args[0] + "%")
+		default:
+			d.push("(" + args[0] + ")")
+		}
+
+	case 0x16: // PtgMissArg
+		d.push("")
+
+	case 0x17: // PtgStr
+		s, err := d.rgce.ReadShortString()
+		if err != nil {
+			return err
+		}
+		d.push(quoteString(s))
+
+	case 0x18: // PtgList, PtgSxName and other extended tokens
+		return fmt.Errorf("extended tokens (structured references) are not supported")
+
+	case 0x19: // PtgAttr
+		return d.attr()
+
+	case 0x1C: // PtgErr
+		b, err := d.rgce.ReadUint8()
+		if err != nil {
+			return err
+		}
+		d.push(errText(b))
+
+	case 0x1D: // PtgBool
+		b, err := d.rgce.ReadUint8()
+		if err != nil {
+			return err
+		}
+		if b != 0 {
+			d.push("TRUE")
+		} else {
+			d.push("FALSE")
+		}
+
+	case 0x1E: // PtgInt
+		v, err := d.rgce.ReadUint16()
+		if err != nil {
+			return err
+		}
+		d.push(strconv.Itoa(int(v)))
+
+	case 0x1F: // PtgNum
+		v, err := d.rgce.ReadDouble()
+		if err != nil {
+			return err
+		}
+		d.push(formatNumber(v))
+
+	case 0x20: // PtgArray
+		return d.array()
+
+	case 0x21: // PtgFunc
+		iftab, err := d.rgce.ReadUint16()
+		if err != nil {
+			return err
+		}
+		f, ok := ftab[int(iftab)]
+		if !ok {
+			return fmt.Errorf("unknown function index %d", iftab)
+		}
+		if f.args < 0 {
+			return fmt.Errorf("function %s has no fixed argument count", f.name)
+		}
+		args, err := d.pop(f.args)
+		if err != nil {
+			return err
+		}
+		d.push(f.name + "(" + strings.Join(args, ",") + ")")
+
+	case 0x22: // PtgFuncVar
+		return d.funcVar()
+
+	case 0x23: // PtgName
+		idx, err := d.rgce.ReadUint32()
+		if err != nil {
+			return err
+		}
+		d.push(d.name(int(idx)))
+
+	case 0x24: // PtgRef
+		r, c, rRel, cRel, err := d.readLoc(false)
+		if err != nil {
+			return err
+		}
+		d.push(cellText(r, c, rRel, cRel))
+
+	case 0x25: // PtgArea
+		area, err := d.readArea(false)
+		if err != nil {
+			return err
+		}
+		d.push(area)
+
+	case 0x26, 0x27, 0x28: // PtgMemArea, PtgMemErr, PtgMemNoMem
+		// The sub-expression that follows is decoded normally; only the
+		// cached-range bookkeeping is skipped.
+		if err := d.rgce.Skip(6); err != nil {
+			return err
+		}
+		if ptg == 0x26 {
+			return d.skipExtraMem()
+		}
+
+	case 0x29: // PtgMemFunc
+		return d.rgce.Skip(2)
+
+	case 0x2A: // PtgRefErr
+		if err := d.rgce.Skip(6); err != nil {
+			return err
+		}
+		d.push("#REF!")
+
+	case 0x2B: // PtgAreaErr
+		if err := d.rgce.Skip(12); err != nil {
+			return err
+		}
+		d.push("#REF!")
+
+	case 0x2C: // PtgRefN
+		r, c, rRel, cRel, err := d.readLoc(true)
+		if err != nil {
+			return err
+		}
+		d.push(cellText(r, c, rRel, cRel))
+
+	case 0x2D: // PtgAreaN
+		area, err := d.readArea(true)
+		if err != nil {
+			return err
+		}
+		d.push(area)
+
+	case 0x39: // PtgNameX
+		// External names live in external-link parts that are not loaded.
+		if err := d.rgce.Skip(6); err != nil {
+			return err
+		}
+		d.push("#NAME?")
+
+	case 0x3A: // PtgRef3d
+		prefix, err := d.readSheetPrefix()
+		if err != nil {
+			return err
+		}
+		r, c, rRel, cRel, err := d.readLoc(false)
+		if err != nil {
+			return err
+		}
+		d.push(prefix + cellText(r, c, rRel, cRel))
+
+	case 0x3B: // PtgArea3d
+		prefix, err := d.readSheetPrefix()
+		if err != nil {
+			return err
+		}
+		area, err := d.readArea(false)
+		if err != nil {
+			return err
+		}
+		d.push(prefix + area)
+
+	case 0x3C: // PtgRefErr3d
+		prefix, err := d.readSheetPrefix()
+		if err != nil {
+			return err
+		}
+		if err := d.rgce.Skip(6); err != nil {
+			return err
+		}
+		d.push(prefix + "#REF!")
+
+	case 0x3D: // PtgAreaErr3d
+		prefix, err := d.readSheetPrefix()
+		if err != nil {
+			return err
+		}
+		if err := d.rgce.Skip(12); err != nil {
+			return err
+		}
+		d.push(prefix + "#REF!")
+
+	default:
+		return fmt.Errorf("unsupported token")
+	}
+	return nil
+}
+
+// attr decodes a PtgAttr token.  Only PtgAttrSum changes the rendered text;
+// the remaining attributes (volatile, IF/CHOOSE jump tables, GOTO, spacing)
+// are evaluation hints and are skipped.
+func (d *decoder) attr() error {
+	typ, err := d.rgce.ReadUint8()
+	if err != nil {
+		return err
+	}
+	switch {
+	case typ&0x04 != 0: // PtgAttrChoose: cOffset uint16 + (cOffset+1) offsets
+		n, err := d.rgce.ReadUint16()
+		if err != nil {
+			return err
+		}
+		return d.rgce.Skip((int(n) + 1) * 2)
+	case typ&0x10 != 0: // PtgAttrSum: SUM with a single argument
+		if err := d.rgce.Skip(2); err != nil {
+			return err
+		}
+		args, err := d.pop(1)
+		if err != nil {
+			return err
+		}
+		d.push("SUM(" + args[0] + ")")
+		return nil
+	default:
+		return d.rgce.Skip(2)
+	}
+}
+
+// funcVar decodes a PtgFuncVar token:
+//
+//	cparams uint8   — argument count in bits 0–6
+//	tab     uint16  — Ftab index in bits 0–14 (bit 15: fCeFunc)
+//
+// Index 255 denotes a user-defined or future function whose name is the
+// first argument (a PtgName); Excel prefixes future functions with "_xlfn."
+// and the prefix is dropped here, as in Excel's formula bar.
+func (d *decoder) funcVar() error {
+	argc, err := d.rgce.ReadUint8()
+	if err != nil {
+		return err
+	}
+	tab, err := d.rgce.ReadUint16()
+	if err != nil {
+		return err
+	}
+	args, err := d.pop(int(argc & 0x7F))
+	if err != nil {
+		return err
+	}
+	iftab := int(tab & 0x7FFF)
+	var name string
+	if iftab == udfIndex {
+		if len(args) == 0 {
+			return fmt.Errorf("user-defined function call without a name operand")
+		}
+		name = strings.TrimPrefix(strings.TrimPrefix(args[0], "_xlfn."), "_xlws.")
+		args = args[1:]
+	} else {
+		f, ok := ftab[iftab]
+		if !ok {
+			return fmt.Errorf("unknown function index %d", iftab)
+		}
+		name = f.name
+	}
+	d.push(name + "(" + strings.Join(args, ",") + ")")
+	return nil
+}
+
+// array decodes a PtgArray token.  The token itself carries 14 unused bytes;
+// the values follow in the extra data as PtgExtraArray:
+//
+//	rows  uint32
+//	cols  uint32
+//	rows*cols SerAr entries, row-major, each prefixed by a type byte
+//	  0x00 number (8-byte double), 0x01 string (2-byte count + UTF-16LE),
+//	  0x02 boolean (1 byte), 0x04 error (1-byte BErr + 3 reserved bytes)
+func (d *decoder) array() error {
+	if err := d.rgce.Skip(14); err != nil {
+		return err
+	}
+	rows, err := d.extra.ReadUint32()
+	if err != nil {
+		return err
+	}
+	cols, err := d.extra.ReadUint32()
+	if err != nil {
+		return err
+	}
+	// Each entry is at least 2 bytes, which bounds the loop on corrupt counts.
+	if uint64(rows)*uint64(cols)*2 > uint64(d.extra.Len()) {
+		return fmt.Errorf("array constant %dx%d exceeds extra data", rows, cols)
+	}
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for r := range int(rows) {
+		if r > 0 {
+			sb.WriteByte(';')
+		}
+		for c := range int(cols) {
+			if c > 0 {
+				sb.WriteByte(',')
+			}
+			v, err := d.serAr()
+			if err != nil {
+				return err
+			}
+			sb.WriteString(v)
+		}
+	}
+	sb.WriteByte('}')
+	d.push(sb.String())
+	return nil
+}
+
+// serAr decodes one array-constant element from the extra data.
+func (d *decoder) serAr() (string, error) {
+	typ, err := d.extra.ReadUint8()
+	if err != nil {
+		return "", err
+	}
+	switch typ {
+	case 0x00:
+		v, err := d.extra.ReadDouble()
+		if err != nil {
+			return "", err
+		}
+		return formatNumber(v), nil
+	case 0x01:
+		s, err := d.extra.ReadShortString()
+		if err != nil {
+			return "", err
+		}
+		return quoteString(s), nil
+	case 0x02:
+		b, err := d.extra.ReadUint8()
+		if err != nil {
+			return "", err
+		}
+		if b != 0 {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	case 0x04:
+		b, err := d.extra.ReadUint8()
+		if err != nil {
+			return "", err
+		}
+		if err := d.extra.Skip(3); err != nil {
+			return "", err
+		}
+		return errText(b), nil
+	}
+	return "", fmt.Errorf("unknown array element type 0x%02X", typ)
+}
+
+// skipExtraMem consumes the PtgExtraMem block that accompanies PtgMemArea:
+// a uint32 count followed by that many 16-byte range descriptors.
+func (d *decoder) skipExtraMem() error {
+	n, err := d.extra.ReadUint32()
+	if err != nil {
+		return err
+	}
+	if uint64(n)*16 > uint64(d.extra.Len()) {
+		return fmt.Errorf("PtgExtraMem count %d exceeds extra data", n)
+	}
+	return d.extra.Skip(int(n) * 16)
+}
+
+// name resolves a PtgName index through the Context.
+func (d *decoder) name(idx int) string {
+	if d.ctx != nil {
+		if s, ok := d.ctx.Name(idx); ok {
+			return s
+		}
+	}
+	return "#NAME?"
+}
+
+// readSheetPrefix reads the ixti operand of a 3-D token and returns the
+// rendered sheet qualifier including the trailing "!", or "#REF!" when the
+// XTI cannot be resolved.
+func (d *decoder) readSheetPrefix() (string, error) {
+	ixti, err := d.rgce.ReadUint16()
+	if err != nil {
+		return "", err
+	}
+	if d.ctx == nil {
+		return "#REF!", nil
+	}
+	x, ok := d.ctx.Extern(int(ixti))
+	if !ok {
+		return "#REF!", nil
+	}
+	if x.First == "" {
+		return "", nil
+	}
+	return sheetPrefix(x), nil
+}
+
+// readLoc reads a RgceLoc (row uint32, column uint16 with fColRel in bit 14
+// and fRwRel in bit 15).  When relative is true the location is a RgceLocRel
+// from PtgRefN / PtgAreaN: relative components are signed offsets from the
+// owning cell and are resolved (with Excel's wrap-around) here.
+func (d *decoder) readLoc(relative bool) (row, col int, rowRel, colRel bool, err error) {
+	r, err := d.rgce.ReadUint32()
+	if err != nil {
+		return 0, 0, false, false, err
+	}
+	c, err := d.rgce.ReadUint16()
+	if err != nil {
+		return 0, 0, false, false, err
+	}
+	row, col, rowRel, colRel = d.resolveLoc(r, c, relative)
+	return row, col, rowRel, colRel, nil
+}
+
+// resolveLoc splits a raw row / column pair into coordinates and flags.
+func (d *decoder) resolveLoc(r uint32, c uint16, relative bool) (row, col int, rowRel, colRel bool) {
+	colRel = c&0x4000 != 0
+	rowRel = c&0x8000 != 0
+	row = int(r)
+	col = int(c & 0x3FFF)
+	if relative {
+		if rowRel {
+			row = wrap(d.row+int(int32(r)), cellref.MaxRow+1)
+		}
+		if colRel {
+			off := col
+			if off >= 0x2000 {
+				off -= 0x4000 // 14-bit two's complement
+			}
+			col = wrap(d.col+off, cellref.MaxCol+1)
+		}
+	}
+	return row, col, rowRel, colRel
+}
+
+// readArea reads a RgceArea (rowFirst, rowLast uint32; colFirst, colLast
+// uint16) and renders it, collapsing whole-column ("A:B") and whole-row
+// ("1:3") ranges the way Excel does.
+func (d *decoder) readArea(relative bool) (string, error) {
+	var raw [4]uint32
+	for i := range 2 {
+		v, err := d.rgce.ReadUint32()
+		if err != nil {
+			return "", err
+		}
+		raw[i] = v
+	}
+	for i := 2; i < 4; i++ {
+		v, err := d.rgce.ReadUint16()
+		if err != nil {
+			return "", err
+		}
+		raw[i] = uint32(v)
+	}
+	r1, c1, r1Rel, c1Rel := d.resolveLoc(raw[0], uint16(raw[2]), relative)
+	r2, c2, r2Rel, c2Rel := d.resolveLoc(raw[1], uint16(raw[3]), relative)
+
+	switch {
+	case r1 == 0 && r2 == cellref.MaxRow:
+		return dollar(!c1Rel) + cellref.ColumnName(c1) + ":" + dollar(!c2Rel) + cellref.ColumnName(c2), nil
+	case c1 == 0 && c2 == cellref.MaxCol:
+		return dollar(!r1Rel) + strconv.Itoa(r1+1) + ":" + dollar(!r2Rel) + strconv.Itoa(r2+1), nil
+	}
+	return cellText(r1, c1, r1Rel, c1Rel) + ":" + cellText(r2, c2, r2Rel, c2Rel), nil
+}
+
+// ── rendering helpers ─────────────────────────────────────────────────────────
+
+// wrap reduces v into [0, n).
+func wrap(v, n int) int {
+	return ((v % n) + n) % n
+}
+
+func dollar(abs bool) string {
+	if abs {
+		return "$"
+	}
+	return ""
+}
+
+// cellText renders a single A1 reference, adding "$" to absolute parts.
+func cellText(row, col int, rowRel, colRel bool) string {
+	return cellref.Ref{Row: row, Col: col, RowAbs: !rowRel, ColAbs: !colRel}.String()
+}
+
+// formatNumber renders a numeric constant the way Excel displays it in the
+// formula bar: plain decimal notation, switching to exponent form only for
+// very large or very small magnitudes.
+func formatNumber(v float64) string {
+	a := math.Abs(v)
+	if a != 0 && (a >= 1e15 || a < 1e-9) {
+		return strconv.FormatFloat(v, 'E', -1, 64)
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// quoteString renders a string constant, doubling embedded quotes.
+func quoteString(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+// sheetPrefix renders the "Sheet!" qualifier for an XTI entry, quoting it
+// when any sheet name contains characters that require it.
+func sheetPrefix(x Extern) string {
+	s := x.First
+	quote := cellref.NeedsQuote(x.First)
+	if x.Last != "" && x.Last != x.First {
+		s += ":" + x.Last
+		quote = quote || cellref.NeedsQuote(x.Last)
+	}
+	if x.Book > 0 {
+		s = "[" + strconv.Itoa(x.Book) + "]" + s
+	}
+	if quote {
+		return "'" + strings.ReplaceAll(s, "'", "''") + "'!"
+	}
+	return s + "!"
+}
+
+// ── reference extraction ──────────────────────────────────────────────────────
+
+// SheetArea is a rectangular range referenced through an XTI index, as found
+// in the formulas of range names.  Bounds are 0-based and inclusive.
+type SheetArea struct {
+	// Ixti is the index into the workbook's BrtExternSheet table (see
+	// Context.Extern).
+	Ixti int
+	// R1 and C1 are the first row and column; R2 and C2 the last.
+	R1, C1, R2, C2 int
+}
+
+// SheetAreas reports whether rgce is a plain list of 3-D references — a
+// single PtgRef3d or PtgArea3d token, or several joined by the union
+// operator — and returns them in order.  This is the shape of range names,
+// print areas, and print titles.  Reference flags (absolute / relative) are
+// ignored; any other token makes the formula non-simple and ok is false.
+func SheetAreas(rgce []byte) (areas []SheetArea, ok bool) {
+	rr := record.NewRecordReader(rgce)
+	depth := 0 // operands on the RPN stack
+	for rr.Len() > 0 {
+		ptg, _ := rr.ReadUint8()
+		if ptg >= 0x40 {
+			ptg = ptg&0x1F | 0x20
+		}
+		switch ptg {
+		case 0x3A, 0x3B: // PtgRef3d, PtgArea3d
+			var raw [5]uint32 // ixti, r1, r2, c1, c2
+			ixti, err := rr.ReadUint16()
+			if err != nil {
+				return nil, false
+			}
+			raw[0] = uint32(ixti)
+			nRows := 1
+			if ptg == 0x3B {
+				nRows = 2
+			}
+			for i := range nRows {
+				v, err := rr.ReadUint32()
+				if err != nil {
+					return nil, false
+				}
+				raw[1+i] = v
+			}
+			for i := range nRows {
+				v, err := rr.ReadUint16()
+				if err != nil {
+					return nil, false
+				}
+				raw[3+i] = uint32(v & 0x3FFF)
+			}
+			a := SheetArea{Ixti: int(raw[0]), R1: int(raw[1]), C1: int(raw[3])}
+			a.R2, a.C2 = a.R1, a.C1
+			if ptg == 0x3B {
+				a.R2, a.C2 = int(raw[2]), int(raw[4])
+			}
+			areas = append(areas, a)
+			depth++
+		case 0x10: // PtgUnion
+			if depth < 2 {
+				return nil, false
+			}
+			depth--
+		case 0x15: // PtgParen
+			if depth < 1 {
+				return nil, false
+			}
+		case 0x29: // PtgMemFunc: the sub-expression follows
+			if err := rr.Skip(2); err != nil {
+				return nil, false
+			}
+		default:
+			return nil, false
+		}
+	}
+	if depth != 1 {
+		return nil, false
+	}
+	return areas, true
+}
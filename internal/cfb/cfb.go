@@ -0,0 +1,340 @@
+// Package cfb reads streams from a Compound File Binary container
+// (MS-CFB), the OLE2 format that wraps encrypted OOXML packages and legacy
+// .xls files.
+//
+// Only reading root-level streams is supported, which is all that
+// MS-OFFCRYPTO decryption needs.
+package cfb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+)
+
+// Signature is the 8-byte magic number at the start of every compound file.
+var Signature = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
+// Special sector numbers (MS-CFB §2.1).
+const (
+	maxRegSect = 0xFFFFFFFA
+	endOfChain = 0xFFFFFFFE
+	noStream   = 0xFFFFFFFF
+)
+
+// Directory entry object types.
+const (
+	typeStream = 2
+	typeRoot   = 5
+)
+
+// ErrNotExist is returned by ReadStream when there is no such stream.
+var ErrNotExist = errors.New("cfb: stream not found")
+
+// dirEntry is a parsed 128-byte directory entry.
+type dirEntry struct {
+	name               string
+	typ                uint8
+	left, right, child uint32
+	start              uint32
+	size               uint64
+}
+
+// File is an open compound file.
+type File struct {
+	r          io.ReaderAt
+	size       int64
+	sectorSize int
+	miniSize   int
+	miniCutoff uint64
+	fat        []uint32
+	miniFAT    []uint32
+	dir        []dirEntry
+	miniStream []byte // contents of the root entry's stream, loaded on demand
+}
+
+// IsCFB reports whether header starts with the compound file signature.
+func IsCFB(header []byte) bool {
+	return bytes.HasPrefix(header, Signature)
+}
+
+// Open parses the header, allocation tables and directory of the compound
+// file in r.  size is the total byte size of the file.
+//
+// Header layout (MS-CFB §2.2), little-endian:
+//
+//	0x00 signature         [8]byte
+//	0x1E sector shift      uint16   (9 → 512-byte sectors, 12 → 4096)
+//	0x20 mini sector shift uint16   (6 → 64-byte mini sectors)
+//	0x2C FAT sector count  uint32
+//	0x30 first dir sector  uint32
+//	0x38 mini cutoff       uint32   (4096)
+//	0x3C first mini FAT    uint32
+//	0x40 mini FAT count    uint32
+//	0x44 first DIFAT       uint32
+//	0x48 DIFAT count       uint32
+//	0x4C DIFAT             [109]uint32
+func Open(r io.ReaderAt, size int64) (*File, error) {
+	var hdr [512]byte
+	if size < int64(len(hdr)) {
+		return nil, fmt.Errorf("cfb: file too short (%d bytes)", size)
+	}
+	if _, err := r.ReadAt(hdr[:], 0); err != nil {
+		return nil, fmt.Errorf("cfb: read header: %w", err)
+	}
+	if !IsCFB(hdr[:]) {
+		return nil, errors.New("cfb: not a compound file")
+	}
+	le := binary.LittleEndian
+	shift, miniShift := le.Uint16(hdr[0x1E:]), le.Uint16(hdr[0x20:])
+	if shift != 9 && shift != 12 || miniShift != 6 {
+		return nil, fmt.Errorf("cfb: unsupported sector shift %d/%d", shift, miniShift)
+	}
+	f := &File{
+		r:          r,
+		size:       size,
+		sectorSize: 1 << shift,
+		miniSize:   1 << miniShift,
+		miniCutoff: uint64(le.Uint32(hdr[0x38:])),
+	}
+	nFAT := le.Uint32(hdr[0x2C:])
+	firstDir := le.Uint32(hdr[0x30:])
+	firstMiniFAT, nMiniFAT := le.Uint32(hdr[0x3C:]), le.Uint32(hdr[0x40:])
+	difatSect, nDIFAT := le.Uint32(hdr[0x44:]), le.Uint32(hdr[0x48:])
+
+	// Every sector of the file needs one FAT entry, which bounds the counts
+	// a well-formed header can declare.
+	maxSectors := uint32(size / int64(f.sectorSize))
+	if nFAT > maxSectors || nMiniFAT > maxSectors || nDIFAT > maxSectors {
+		return nil, errors.New("cfb: header declares more sectors than the file holds")
+	}
+
+	// Collect the FAT sector numbers: 109 in the header, the rest in the
+	// DIFAT chain (each DIFAT sector ends with the number of the next).
+	fatSects := make([]uint32, 0, nFAT)
+	for i := 0; i < 109 && uint32(len(fatSects)) < nFAT; i++ {
+		fatSects = append(fatSects, le.Uint32(hdr[0x4C+4*i:]))
+	}
+	perSect := f.sectorSize / 4
+	for range nDIFAT {
+		if uint32(len(fatSects)) >= nFAT || difatSect > maxRegSect {
+			break
+		}
+		buf, err := f.sector(difatSect)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < perSect-1 && uint32(len(fatSects)) < nFAT; i++ {
+			fatSects = append(fatSects, le.Uint32(buf[4*i:]))
+		}
+		difatSect = le.Uint32(buf[4*(perSect-1):])
+	}
+	for _, s := range fatSects {
+		buf, err := f.sector(s)
+		if err != nil {
+			return nil, err
+		}
+		for i := range perSect {
+			f.fat = append(f.fat, le.Uint32(buf[4*i:]))
+		}
+	}
+
+	if nMiniFAT > 0 {
+		buf, err := f.readChain(firstMiniFAT, -1)
+		if err != nil {
+			return nil, fmt.Errorf("cfb: mini FAT: %w", err)
+		}
+		for i := 0; i+4 <= len(buf); i += 4 {
+			f.miniFAT = append(f.miniFAT, le.Uint32(buf[i:]))
+		}
+	}
+
+	buf, err := f.readChain(firstDir, -1)
+	if err != nil {
+		return nil, fmt.Errorf("cfb: directory: %w", err)
+	}
+	for off := 0; off+128 <= len(buf); off += 128 {
+		f.dir = append(f.dir, parseDirEntry(buf[off:off+128], shift == 9))
+	}
+	if len(f.dir) == 0 || f.dir[0].typ != typeRoot {
+		return nil, errors.New("cfb: missing root directory entry")
+	}
+	return f, nil
+}
+
+// parseDirEntry decodes one directory entry.  Version 3 files (512-byte
+// sectors) only use the low 32 bits of the stream size.
+func parseDirEntry(b []byte, v3 bool) dirEntry {
+	le := binary.LittleEndian
+	nameLen := int(le.Uint16(b[64:])) // in bytes, including the terminator
+	if nameLen > 64 {
+		nameLen = 64
+	}
+	u := make([]uint16, 0, 32)
+	for i := 0; i+1 < nameLen; i += 2 {
+		if c := le.Uint16(b[i:]); c != 0 {
+			u = append(u, c)
+		}
+	}
+	e := dirEntry{
+		name:  string(utf16.Decode(u)),
+		typ:   b[66],
+		left:  le.Uint32(b[68:]),
+		right: le.Uint32(b[72:]),
+		child: le.Uint32(b[76:]),
+		start: le.Uint32(b[116:]),
+		size:  le.Uint64(b[120:]),
+	}
+	if v3 {
+		e.size &= 0xFFFFFFFF
+	}
+	return e
+}
+
+// Streams returns the names of the streams stored directly under the root
+// storage.
+func (f *File) Streams() []string {
+	var names []string
+	f.walk(func(e dirEntry) bool {
+		if e.typ == typeStream {
+			names = append(names, e.name)
+		}
+		return true
+	})
+	return names
+}
+
+// ReadStream returns the contents of the root-level stream with the given
+// name.  Names are compared case-insensitively, as in MS-CFB.
+func (f *File) ReadStream(name string) ([]byte, error) {
+	var (
+		found dirEntry
+		ok    bool
+	)
+	f.walk(func(e dirEntry) bool {
+		if e.typ == typeStream && strings.EqualFold(e.name, name) {
+			found, ok = e, true
+			return false
+		}
+		return true
+	})
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrNotExist, name)
+	}
+	if found.size > uint64(f.size) {
+		return nil, fmt.Errorf("cfb: stream %q declares %d bytes in a %d-byte file", name, found.size, f.size)
+	}
+	if found.size < f.miniCutoff {
+		data, err := f.readMini(found.start, int(found.size))
+		if err != nil {
+			return nil, fmt.Errorf("cfb: stream %q: %w", name, err)
+		}
+		return data, nil
+	}
+	data, err := f.readChain(found.start, int(found.size))
+	if err != nil {
+		return nil, fmt.Errorf("cfb: stream %q: %w", name, err)
+	}
+	return data, nil
+}
+
+// walk visits the children of the root storage in tree order until visit
+// returns false.  Corrupt sibling links that form cycles are visited once.
+func (f *File) walk(visit func(dirEntry) bool) {
+	seen := make(map[uint32]bool)
+	var rec func(id uint32) bool
+	rec = func(id uint32) bool {
+		if id == noStream || int64(id) >= int64(len(f.dir)) || seen[id] {
+			return true
+		}
+		seen[id] = true
+		e := f.dir[id]
+		return rec(e.left) && visit(e) && rec(e.right)
+	}
+	rec(f.dir[0].child)
+}
+
+// sector reads one regular sector.
+func (f *File) sector(n uint32) ([]byte, error) {
+	if n > maxRegSect {
+		return nil, fmt.Errorf("cfb: invalid sector number %#x", n)
+	}
+	off := (int64(n) + 1) * int64(f.sectorSize)
+	if off >= f.size {
+		return nil, fmt.Errorf("cfb: sector %d beyond end of file", n)
+	}
+	// The last sector of a file may be truncated; the rest reads as zeros.
+	buf := make([]byte, f.sectorSize)
+	m, err := f.r.ReadAt(buf, off)
+	if err != nil && !(err == io.EOF && m > 0) {
+		return nil, fmt.Errorf("cfb: read sector %d: %w", n, err)
+	}
+	return buf, nil
+}
+
+// readChain reads the regular sector chain starting at start.  n is the
+// number of bytes wanted, or -1 to read the whole chain.
+func (f *File) readChain(start uint32, n int) ([]byte, error) {
+	var out []byte
+	if n >= 0 {
+		out = make([]byte, 0, n)
+	}
+	s := start
+	for steps := 0; s != endOfChain; steps++ {
+		if steps > len(f.fat) || int64(s) >= int64(len(f.fat)) {
+			return nil, fmt.Errorf("cfb: broken sector chain at %#x", s)
+		}
+		if n >= 0 && len(out) >= n {
+			break
+		}
+		buf, err := f.sector(s)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, buf...)
+		s = f.fat[s]
+	}
+	if n >= 0 {
+		if len(out) < n {
+			return nil, fmt.Errorf("cfb: chain holds %d of %d bytes", len(out), n)
+		}
+		out = out[:n]
+	}
+	return out, nil
+}
+
+// readMini reads n bytes of the mini sector chain starting at start.
+func (f *File) readMini(start uint32, n int) ([]byte, error) {
+	if n == 0 {
+		return []byte{}, nil
+	}
+	if f.miniStream == nil {
+		root := f.dir[0]
+		if root.size > uint64(f.size) {
+			return nil, errors.New("cfb: mini stream larger than file")
+		}
+		ms, err := f.readChain(root.start, int(root.size))
+		if err != nil {
+			return nil, fmt.Errorf("mini stream: %w", err)
+		}
+		f.miniStream = ms
+	}
+	out := make([]byte, 0, n)
+	s := start
+	for steps := 0; len(out) < n; steps++ {
+		if s == endOfChain || steps > len(f.miniFAT) || int64(s) >= int64(len(f.miniFAT)) {
+			return nil, fmt.Errorf("broken mini sector chain at %#x", s)
+		}
+		off := int(s) * f.miniSize
+		if off+f.miniSize > len(f.miniStream) {
+			return nil, fmt.Errorf("mini sector %d beyond end of mini stream", s)
+		}
+		out = append(out, f.miniStream[off:off+f.miniSize]...)
+		s = f.miniFAT[s]
+	}
+	return out[:n], nil
+}
@@ -0,0 +1,65 @@
+// Package isopassword decodes the IsoPasswordData structure shared by the
+// BrtBookProtectionIso, BrtSheetProtectionIso and BrtRangeProtectionIso
+// records.
+//
+// It exists so that workbook/ and worksheet/ can share the decoder without
+// exporting a record-level reader from either package.
+package isopassword
+
+import (
+	"fmt"
+
+	"github.com/TsubasaBE/go-xlsb/record"
+)
+
+// Data is a decoded IsoPasswordData structure.  The spin count is stored
+// outside the structure, in the enclosing record.
+type Data struct {
+	// Algorithm is the hash algorithm name, e.g. "SHA-512".
+	Algorithm string
+	// Hash and Salt are the password hash and its salt.
+	Hash, Salt []byte
+}
+
+// Read reads an IsoPasswordData structure (hash, salt and algorithm name)
+// from rr.
+//
+//	cbHash    = read_uint32()
+//	rgbHash   = cbHash bytes
+//	cbSalt    = read_uint32()
+//	rgbSalt   = cbSalt bytes
+//	szAlgName = read_string()
+func Read(rr *record.RecordReader) (Data, error) {
+	hash, err := ReadBlob(rr)
+	if err != nil {
+		return Data{}, err
+	}
+	salt, err := ReadBlob(rr)
+	if err != nil {
+		return Data{}, err
+	}
+	alg, err := rr.ReadString()
+	if err != nil {
+		return Data{}, err
+	}
+	return Data{Algorithm: alg, Hash: hash, Salt: salt}, nil
+}
+
+// ReadBlob reads a uint32 length followed by that many bytes.
+func ReadBlob(rr *record.RecordReader) ([]byte, error) {
+	n, err := rr.ReadUint32()
+	if err != nil {
+		return nil, err
+	}
+	if int64(n) > int64(rr.Len()) {
+		return nil, fmt.Errorf("blob of %d bytes exceeds the %d bytes left", n, rr.Len())
+	}
+	if n == 0 {
+		return nil, nil
+	}
+	b := make([]byte, n)
+	if err := rr.Read(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
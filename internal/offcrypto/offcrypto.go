@@ -0,0 +1,444 @@
+// Package offcrypto decrypts OOXML packages protected with an open password
+// (MS-OFFCRYPTO).  An encrypted .xlsb is a compound file holding two
+// streams: EncryptionInfo, which describes the cipher and how the key is
+// derived from the password, and EncryptedPackage, the encrypted ZIP.
+//
+// Agile encryption (Office 2010 and later; AES with SHA-1/SHA-2 key
+// derivation) and Standard encryption (Office 2007; AES-ECB with SHA-1) are
+// supported.  The HMAC of Agile packages is not verified: a wrong key is
+// detected by the password verifier before decryption, and a damaged
+// package is reported by the ZIP reader.
+package offcrypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/TsubasaBE/go-xlsb/internal/cfb"
+)
+
+// DefaultPassword is the password Excel uses when a file is encrypted only
+// to enforce "read-only recommended" or write protection; such files open
+// without prompting.
+const DefaultPassword = "VelvetSweatshop"
+
+// ErrPassword is returned when the password does not match the verifier
+// stored in the file.
+var ErrPassword = errors.New("offcrypto: wrong password")
+
+// ErrUnsupported is returned for encryption schemes this package does not
+// implement (RC4, extensible encryption, non-AES ciphers).
+var ErrUnsupported = errors.New("offcrypto: unsupported encryption")
+
+// Stream names inside the compound file.
+const (
+	infoStream    = "EncryptionInfo"
+	packageStream = "EncryptedPackage"
+)
+
+// IsEncryptedPackage reports whether f holds an encrypted OOXML package.
+// Stream names are compared case-insensitively, as in cfb.File.ReadStream.
+func IsEncryptedPackage(f *cfb.File) bool {
+	var info, pkg bool
+	for _, name := range f.Streams() {
+		switch {
+		case strings.EqualFold(name, infoStream):
+			info = true
+		case strings.EqualFold(name, packageStream):
+			pkg = true
+		}
+	}
+	return info && pkg
+}
+
+// Decrypt reads the EncryptionInfo and EncryptedPackage streams of f and
+// returns the decrypted package (a ZIP archive).
+func Decrypt(f *cfb.File, password string) ([]byte, error) {
+	info, err := f.ReadStream(infoStream)
+	if err != nil {
+		return nil, fmt.Errorf("offcrypto: %w", err)
+	}
+	pkg, err := f.ReadStream(packageStream)
+	if err != nil {
+		return nil, fmt.Errorf("offcrypto: %w", err)
+	}
+	if len(info) < 8 {
+		return nil, errors.New("offcrypto: EncryptionInfo too short")
+	}
+	major, minor := binary.LittleEndian.Uint16(info), binary.LittleEndian.Uint16(info[2:])
+	switch {
+	case major == 4 && minor == 4:
+		return decryptAgile(info[8:], pkg, password)
+	case (major == 3 || major == 4) && minor == 2:
+		return decryptStandard(info[4:], pkg, password)
+	}
+	return nil, fmt.Errorf("%w: EncryptionInfo version %d.%d", ErrUnsupported, major, minor)
+}
+
+// packageSize splits the EncryptedPackage stream into its declared plain
+// text size and the encrypted bytes.
+func packageSize(pkg []byte) (int, []byte, error) {
+	if len(pkg) < 8 {
+		return 0, nil, errors.New("offcrypto: EncryptedPackage too short")
+	}
+	size := binary.LittleEndian.Uint64(pkg)
+	if size > uint64(len(pkg)-8) {
+		return 0, nil, fmt.Errorf("offcrypto: EncryptedPackage declares %d bytes but holds %d", size, len(pkg)-8)
+	}
+	return int(size), pkg[8:], nil
+}
+
+// passwordBytes encodes the password as UTF-16LE, as all key derivations
+// require.
+func passwordBytes(password string) []byte {
+	u := utf16.Encode([]rune(password))
+	b := make([]byte, 2*len(u))
+	for i, c := range u {
+		binary.LittleEndian.PutUint16(b[2*i:], c)
+	}
+	return b
+}
+
+// le32 encodes v as 4 little-endian bytes.
+func le32(v uint32) []byte {
+	return binary.LittleEndian.AppendUint32(nil, v)
+}
+
+// ── Standard encryption ──────────────────────────────────────────────────────
+
+// Standard encryption algorithm identifiers (MS-OFFCRYPTO §2.3.2).
+const (
+	algAES128  = 0x660E
+	algAES192  = 0x660F
+	algAES256  = 0x6610
+	algHashSHA = 0x8004
+	flagAES    = 0x20
+)
+
+// decryptStandard decrypts a package protected with Standard encryption.
+//
+//	flags        = read_uint32()
+//	headerSize   = read_uint32()
+//	EncryptionHeader (headerSize bytes):
+//	  flags, sizeExtra, algID, algIDHash, keySize (bits), providerType,
+//	  reserved1, reserved2 = 8 × read_uint32()
+//	  CSPName      = UTF-16 string
+//	EncryptionVerifier:
+//	  saltSize     = read_uint32()  // 16
+//	  salt         = 16 bytes
+//	  encryptedVerifier     = 16 bytes
+//	  verifierHashSize      = read_uint32()  // 20
+//	  encryptedVerifierHash = 32 bytes
+func decryptStandard(info, pkg []byte, password string) ([]byte, error) {
+	le := binary.LittleEndian
+	if len(info) < 8 {
+		return nil, errors.New("offcrypto: standard EncryptionInfo too short")
+	}
+	flags, hdrSize := le.Uint32(info), le.Uint32(info[4:])
+	if hdrSize < 32 || uint64(hdrSize) > uint64(len(info)-8) {
+		return nil, fmt.Errorf("offcrypto: invalid EncryptionHeader size %d", hdrSize)
+	}
+	hdr, ver := info[8:8+hdrSize], info[8+hdrSize:]
+	algID, algHash, keyBits := le.Uint32(hdr[8:]), le.Uint32(hdr[12:]), le.Uint32(hdr[16:])
+	if flags&flagAES == 0 || algID != algAES128 && algID != algAES192 && algID != algAES256 {
+		return nil, fmt.Errorf("%w: standard encryption with algorithm %#x", ErrUnsupported, algID)
+	}
+	if algHash != 0 && algHash != algHashSHA {
+		return nil, fmt.Errorf("%w: standard encryption with hash %#x", ErrUnsupported, algHash)
+	}
+	if keyBits != 128 && keyBits != 192 && keyBits != 256 {
+		return nil, fmt.Errorf("offcrypto: invalid AES key size %d", keyBits)
+	}
+	if len(ver) < 4+16+16+4+32 || le.Uint32(ver) != 16 {
+		return nil, errors.New("offcrypto: invalid EncryptionVerifier")
+	}
+	salt, encVerifier, encVerifierHash := ver[4:20], ver[20:36], ver[40:72]
+
+	key := standardKey(passwordBytes(password), salt, int(keyBits/8))
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	verifier := decryptECB(block, encVerifier)
+	verifierHash := decryptECB(block, encVerifierHash)
+	sum := sha1.Sum(verifier)
+	if !bytes.Equal(sum[:], verifierHash[:sha1.Size]) {
+		return nil, ErrPassword
+	}
+
+	size, enc, err := packageSize(pkg)
+	if err != nil {
+		return nil, err
+	}
+	enc = enc[:len(enc)/aes.BlockSize*aes.BlockSize]
+	if len(enc) < size {
+		return nil, fmt.Errorf("offcrypto: EncryptedPackage holds %d of %d bytes", len(enc), size)
+	}
+	return decryptECB(block, enc)[:size], nil
+}
+
+// standardKey derives the AES key of Standard encryption (MS-OFFCRYPTO
+// §2.3.4.7): 50,000 rounds of SHA-1 over the salted password, then the
+// CryptDeriveKey expansion.
+func standardKey(password, salt []byte, keyLen int) []byte {
+	h := iterateHash(sha1.New, salt, password, 50000)
+	h = hashOf(sha1.New, h, le32(0))
+	var buf1, buf2 [64]byte
+	for i := range buf1 {
+		buf1[i], buf2[i] = 0x36, 0x5C
+	}
+	for i, b := range h {
+		buf1[i] ^= b
+		buf2[i] ^= b
+	}
+	x := append(hashOf(sha1.New, buf1[:]), hashOf(sha1.New, buf2[:])...)
+	return x[:keyLen]
+}
+
+// decryptECB decrypts data (a whole number of blocks) in ECB mode.
+func decryptECB(block cipher.Block, data []byte) []byte {
+	out := make([]byte, len(data))
+	bs := block.BlockSize()
+	for i := 0; i+bs <= len(data); i += bs {
+		block.Decrypt(out[i:i+bs], data[i:i+bs])
+	}
+	return out
+}
+
+// ── Agile encryption ─────────────────────────────────────────────────────────
+
+// agileParams are the attributes shared by keyData and encryptedKey.
+type agileParams struct {
+	SaltSize        int    `xml:"saltSize,attr"`
+	BlockSize       int    `xml:"blockSize,attr"`
+	KeyBits         int    `xml:"keyBits,attr"`
+	HashSize        int    `xml:"hashSize,attr"`
+	CipherAlgorithm string `xml:"cipherAlgorithm,attr"`
+	CipherChaining  string `xml:"cipherChaining,attr"`
+	HashAlgorithm   string `xml:"hashAlgorithm,attr"`
+	SaltValue       string `xml:"saltValue,attr"`
+}
+
+// agileInfo is the XML descriptor of Agile encryption (MS-OFFCRYPTO
+// §2.3.4.10).  Only the password key encryptor is read.
+type agileInfo struct {
+	KeyData       agileParams `xml:"keyData"`
+	KeyEncryptors []struct {
+		URI          string `xml:"uri,attr"`
+		EncryptedKey struct {
+			agileParams
+			SpinCount                  int    `xml:"spinCount,attr"`
+			EncryptedVerifierHashInput string `xml:"encryptedVerifierHashInput,attr"`
+			EncryptedVerifierHashValue string `xml:"encryptedVerifierHashValue,attr"`
+			EncryptedKeyValue          string `xml:"encryptedKeyValue,attr"`
+		} `xml:"encryptedKey"`
+	} `xml:"keyEncryptors>keyEncryptor"`
+}
+
+const passwordEncryptorURI = "http://schemas.microsoft.com/office/2006/keyEncryptor/password"
+
+// Block keys that separate the keys derived from one password hash
+// (MS-OFFCRYPTO §2.3.4.13).
+var (
+	blockVerifierInput = []byte{0xFE, 0xA7, 0xD2, 0x76, 0x3B, 0x4B, 0x9E, 0x79}
+	blockVerifierValue = []byte{0xD7, 0xAA, 0x0F, 0x6D, 0x30, 0x61, 0x34, 0x4E}
+	blockKeyValue      = []byte{0x14, 0x6E, 0x0B, 0xE7, 0xAB, 0xAC, 0xD0, 0xD6}
+)
+
+// agileSegment is the size of the independently encrypted package segments.
+const agileSegment = 4096
+
+// maxSpinCount is the largest spin count MS-OFFCRYPTO allows.
+const maxSpinCount = 10_000_000
+
+// decryptAgile decrypts a package protected with Agile encryption.  info is
+// the XML descriptor that follows the version and reserved fields.
+func decryptAgile(info, pkg []byte, password string) ([]byte, error) {
+	var ai agileInfo
+	if err := xml.Unmarshal(info, &ai); err != nil {
+		return nil, fmt.Errorf("offcrypto: parse EncryptionInfo: %w", err)
+	}
+	idx := -1
+	for i, ke := range ai.KeyEncryptors {
+		if ke.URI == passwordEncryptorURI {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return nil, fmt.Errorf("%w: no password key encryptor", ErrUnsupported)
+	}
+	ek := ai.KeyEncryptors[idx].EncryptedKey
+	if ek.SpinCount < 0 || ek.SpinCount > maxSpinCount {
+		return nil, fmt.Errorf("offcrypto: invalid spin count %d", ek.SpinCount)
+	}
+	for _, p := range []agileParams{ai.KeyData, ek.agileParams} {
+		if p.CipherAlgorithm != "AES" || p.CipherChaining != "ChainingModeCBC" {
+			return nil, fmt.Errorf("%w: %s/%s", ErrUnsupported, p.CipherAlgorithm, p.CipherChaining)
+		}
+		if p.KeyBits != 128 && p.KeyBits != 192 && p.KeyBits != 256 || p.BlockSize != aes.BlockSize {
+			return nil, fmt.Errorf("offcrypto: invalid AES parameters (%d-bit key, %d-byte block)", p.KeyBits, p.BlockSize)
+		}
+	}
+	newHash, err := hashFunc(ek.HashAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	newDataHash, err := hashFunc(ai.KeyData.HashAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	salt, err := base64.StdEncoding.DecodeString(ek.SaltValue)
+	if err != nil {
+		return nil, fmt.Errorf("offcrypto: saltValue: %w", err)
+	}
+	dataSalt, err := base64.StdEncoding.DecodeString(ai.KeyData.SaltValue)
+	if err != nil {
+		return nil, fmt.Errorf("offcrypto: saltValue: %w", err)
+	}
+	var enc [3][]byte
+	for i, s := range []string{ek.EncryptedVerifierHashInput, ek.EncryptedVerifierHashValue, ek.EncryptedKeyValue} {
+		if enc[i], err = base64.StdEncoding.DecodeString(s); err != nil {
+			return nil, fmt.Errorf("offcrypto: encrypted key: %w", err)
+		}
+	}
+
+	// Derive the three password keys and check the verifier.
+	h := iterateHash(newHash, salt, passwordBytes(password), ek.SpinCount)
+	keyLen := ek.KeyBits / 8
+	decrypt := func(blockKey, data []byte) ([]byte, error) {
+		key := fitSize(hashOf(newHash, h, blockKey), keyLen)
+		return decryptCBC(key, fitSize(salt, aes.BlockSize), data)
+	}
+	input, err := decrypt(blockVerifierInput, enc[0])
+	if err != nil {
+		return nil, err
+	}
+	value, err := decrypt(blockVerifierValue, enc[1])
+	if err != nil {
+		return nil, err
+	}
+	hashSize := newHash().Size()
+	if len(input) < ek.SaltSize || len(value) < hashSize || ek.SaltSize <= 0 {
+		return nil, errors.New("offcrypto: invalid password verifier")
+	}
+	if !bytes.Equal(hashOf(newHash, input[:ek.SaltSize]), value[:hashSize]) {
+		return nil, ErrPassword
+	}
+	secret, err := decrypt(blockKeyValue, enc[2])
+	if err != nil {
+		return nil, err
+	}
+	dataKeyLen := ai.KeyData.KeyBits / 8
+	if len(secret) < dataKeyLen {
+		return nil, errors.New("offcrypto: encrypted key too short")
+	}
+	secret = secret[:dataKeyLen]
+
+	// Decrypt the package one 4096-byte segment at a time; each segment's IV
+	// is the hash of the key-data salt and the segment index.
+	size, data, err := packageSize(pkg)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, len(data))
+	for i := 0; len(out) < size; i++ {
+		start := i * agileSegment
+		if start >= len(data) {
+			return nil, fmt.Errorf("offcrypto: EncryptedPackage holds %d of %d bytes", len(out), size)
+		}
+		seg := data[start:min(start+agileSegment, len(data))]
+		seg = seg[:len(seg)/aes.BlockSize*aes.BlockSize]
+		iv := fitSize(hashOf(newDataHash, dataSalt, le32(uint32(i))), aes.BlockSize)
+		plain, err := decryptCBC(secret, iv, seg)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, plain...)
+	}
+	return out[:size], nil
+}
+
+// hashFunc maps an Agile hashAlgorithm name to its implementation.
+func hashFunc(name string) (func() hash.Hash, error) {
+	switch name {
+	case "SHA1":
+		return sha1.New, nil
+	case "SHA256":
+		return sha256.New, nil
+	case "SHA384":
+		return sha512.New384, nil
+	case "SHA512":
+		return sha512.New, nil
+	case "MD5":
+		return md5.New, nil
+	}
+	return nil, fmt.Errorf("%w: hash algorithm %q", ErrUnsupported, name)
+}
+
+// decryptCBC decrypts data in CBC mode.  Trailing bytes that do not fill a
+// block are ignored.
+func decryptCBC(key, iv, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("offcrypto: %w", err)
+	}
+	data = data[:len(data)/aes.BlockSize*aes.BlockSize]
+	out := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(out, data)
+	return out, nil
+}
+
+// ── Key derivation helpers ───────────────────────────────────────────────────
+
+// hashOf returns the hash of the concatenated parts.
+func hashOf(newHash func() hash.Hash, parts ...[]byte) []byte {
+	h := newHash()
+	for _, p := range parts {
+		h.Write(p)
+	}
+	return h.Sum(nil)
+}
+
+// iterateHash computes H(salt + password) and then rehashes it n times,
+// prefixing the iteration number each time (MS-OFFCRYPTO §2.3.4.7 and
+// §2.3.4.11).
+func iterateHash(newHash func() hash.Hash, salt, password []byte, n int) []byte {
+	h := hashOf(newHash, salt, password)
+	hh := newHash()
+	var iter [4]byte
+	for i := range n {
+		binary.LittleEndian.PutUint32(iter[:], uint32(i))
+		hh.Reset()
+		hh.Write(iter[:])
+		hh.Write(h)
+		h = hh.Sum(h[:0])
+	}
+	return h
+}
+
+// fitSize truncates b to n bytes or pads it with 0x36 bytes.
+func fitSize(b []byte, n int) []byte {
+	if len(b) >= n {
+		return b[:n]
+	}
+	out := make([]byte, n)
+	copy(out, b)
+	for i := len(b); i < n; i++ {
+		out[i] = 0x36
+	}
+	return out
+}
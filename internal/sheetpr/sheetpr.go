@@ -0,0 +1,62 @@
+// Package sheetpr decodes the fields of a SHEETPR record (MS-XLSB BrtWsProp).
+//
+// It exists so that workbook/ can read a sheet's code name from the start of
+// the sheet part without a second copy of the record layout, while
+// worksheet/ turns the same fields into its public SheetProperties.
+package sheetpr
+
+import (
+	"github.com/TsubasaBE/go-xlsb/record"
+	"github.com/TsubasaBE/go-xlsb/styles"
+)
+
+// Record holds the raw fields of a SHEETPR record.
+type Record struct {
+	// Flags holds the 3 flag bytes as a little-endian value.
+	Flags uint32
+	// TabColor is the sheet tab colour.
+	TabColor styles.Color
+	// RwSync and ColSync are the synchronised scroll position, 0xFFFFFFFF
+	// when scrolling is not synchronised.
+	RwSync, ColSync uint32
+	// CodeName is the VBA code name of the sheet.
+	CodeName string
+}
+
+// Parse decodes a SHEETPR record.
+//
+//	flags    = 3 bytes
+//	tabColor = Color (8 bytes)
+//	rwSync   = read_uint32()
+//	colSync  = read_uint32()
+//	codeName = read_string()
+func Parse(data []byte) (Record, error) {
+	rr := record.NewRecordReader(data)
+	var b [3]byte
+	if err := rr.Read(b[:]); err != nil {
+		return Record{}, err
+	}
+	tab, err := styles.ReadColor(rr)
+	if err != nil {
+		return Record{}, err
+	}
+	rwSync, err := rr.ReadUint32()
+	if err != nil {
+		return Record{}, err
+	}
+	colSync, err := rr.ReadUint32()
+	if err != nil {
+		return Record{}, err
+	}
+	codeName, err := rr.ReadString()
+	if err != nil {
+		return Record{}, err
+	}
+	return Record{
+		Flags:    uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16,
+		TabColor: tab,
+		RwSync:   rwSync,
+		ColSync:  colSync,
+		CodeName: codeName,
+	}, nil
+}
@@ -28,7 +28,9 @@ import (
 //   - date1904 should match [workbook.Workbook.Date1904].
 //
 // The dynamic type of v must be one of: nil, string, bool, float64.
-// Any other type falls back to [fmt.Sprint].
+// Any other type falls back to [fmt.Sprint], so error values such as
+// worksheet.CellError render as their error text ("#DIV/0!") whatever the
+// format.
 func FormatValue(v any, numFmtID int, fmtStr string, date1904 bool) string {
 	// Resolve the effective format string.
 	effective := resolveFormat(numFmtID, fmtStr)
@@ -26,6 +26,11 @@ func (r *RecordReader) remaining() int {
 	return len(r.data) - r.pos
 }
 
+// Len returns the number of unread bytes.
+func (r *RecordReader) Len() int {
+	return r.remaining()
+}
+
 // Skip advances the read position by n bytes.
 func (r *RecordReader) Skip(n int) error {
 	if n < 0 {
@@ -150,6 +155,35 @@ func (r *RecordReader) ReadString() (string, error) {
 	return decodeUTF16LE(raw), nil
 }
 
+// ReadNullableString reads an XLNullableWideString: the same layout as
+// ReadString, except that a character count of 0xFFFFFFFF denotes a null
+// string, which is returned as "".
+func (r *RecordReader) ReadNullableString() (string, error) {
+	if r.remaining() >= 4 && binary.LittleEndian.Uint32(r.data[r.pos:]) == 0xFFFFFFFF {
+		r.pos += 4
+		return "", nil
+	}
+	return r.ReadString()
+}
+
+// ReadShortString reads a 2-byte little-endian character count followed by
+// that many UTF-16LE code units.  This is the string layout used inside
+// formula token streams (PtgStr, SerStr), where the 4-byte XLWideString
+// count is not used.
+func (r *RecordReader) ReadShortString() (string, error) {
+	charCount, err := r.ReadUint16()
+	if err != nil {
+		return "", err
+	}
+	byteCount := int(charCount) * 2
+	if r.remaining() < byteCount {
+		return "", io.ErrUnexpectedEOF
+	}
+	raw := r.data[r.pos : r.pos+byteCount]
+	r.pos += byteCount
+	return decodeUTF16LE(raw), nil
+}
+
 // decodeUTF16LE converts a byte slice of UTF-16 little-endian code units into
 // a UTF-8 Go string. Invalid code units are replaced with the Unicode
 // replacement character (U+FFFD), matching Python's errors='replace' behaviour.
@@ -6,6 +6,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"sort"
 
 	"github.com/TsubasaBE/go-xlsb/biff12"
 	"github.com/TsubasaBE/go-xlsb/record"
@@ -14,6 +15,7 @@ import (
 // StringTable holds the shared strings parsed from xl/sharedStrings.bin.
 type StringTable struct {
 	strings []string
+	rich    map[int]*RichString // entries with runs or phonetic data, by index
 }
 
 // New reads all shared string entries from r and returns a populated
@@ -33,12 +35,16 @@ func New(r io.ReadSeeker) (*StringTable, error) {
 
 		switch recID {
 		case biff12.Si:
-			s, err := parseSI(data)
-			if err != nil {
-				// Treat malformed SI as empty string rather than aborting.
-				s = ""
+			// A malformed SI keeps whatever was decoded before the damage
+			// (an empty string at worst) rather than aborting.
+			rs, _ := parseSI(data)
+			if len(rs.Runs) > 0 || rs.Phonetic != nil {
+				if st.rich == nil {
+					st.rich = make(map[int]*RichString)
+				}
+				st.rich[len(st.strings)] = &rs
 			}
-			st.strings = append(st.strings, s)
+			st.strings = append(st.strings, rs.Text)
 		case biff12.SstEnd:
 			return st, nil
 		}
@@ -52,6 +58,25 @@ func (st *StringTable) Get(idx int) string {
 	return st.strings[idx]
 }
 
+// GetRich returns the formatting runs and phonetic data of the shared string
+// at index idx, or nil when the string is plain text without either.  The
+// returned value is shared and must not be modified.  It panics if idx is out
+// of range, like Get.
+func (st *StringTable) GetRich(idx int) *RichString {
+	_ = st.strings[idx]
+	return st.rich[idx]
+}
+
+// GetPhonetic returns the phonetic guide (furigana) of the shared string at
+// index idx.  The boolean is false when the string has no phonetic data.  It
+// panics if idx is out of range, like Get.
+func (st *StringTable) GetPhonetic(idx int) (Phonetic, bool) {
+	if rs := st.GetRich(idx); rs != nil && rs.Phonetic != nil {
+		return *rs.Phonetic, true
+	}
+	return Phonetic{}, false
+}
+
 // Len returns the total number of shared strings loaded.
 func (st *StringTable) Len() int {
 	return len(st.strings)
@@ -59,49 +84,246 @@ func (st *StringTable) Len() int {
 
 // parseSI decodes a single SI (string instance) record payload.
 //
-// BrtSi layout per MS-XLSB §2.4.726:
-//
-//	flags    uint8   — bit 0: fRichStr (rich-text run data follows string)
-//	                   bit 1: fExtStr  (phonetic/extended data follows)
-//	if fRichStr: crun uint32  — number of rich-text run entries
-//	if fExtStr:  sz   uint32  — byte size of phonetic data
-//	string   XLWideString    — the actual text (4-byte char count + UTF-16LE)
-//
-// (Rich-text run records and phonetic data follow the string in the record
-// stream, not the record payload, so we do not need to skip them here.)
-func parseSI(data []byte) (string, error) {
+// BrtSSTItem is a RichStr (MS-XLSB §2.4.726, §2.5.121); see ReadRichString
+// for the layout.  The rich-text runs follow the string inside the record
+// payload, so they must be read after it, not before.
+func parseSI(data []byte) (RichString, error) {
 	if len(data) == 0 {
-		return "", nil
+		return RichString{}, nil
 	}
-	rr := record.NewRecordReader(data)
+	rs, err := ReadRichString(record.NewRecordReader(data))
+	if err != nil {
+		return rs, fmt.Errorf("parseSI: %w", err)
+	}
+	return rs, nil
+}
+
+// Run is one formatting run of a rich string.  The run covers the characters
+// from Start up to the Start of the next run (or the end of the text).
+type Run struct {
+	// Start is the 0-based rune offset into the text where the run begins.
+	Start int
+	// Font is the 0-based index into the workbook's font table (the BrtFont
+	// records of xl/styles.bin).
+	Font int
+}
+
+// RichString is a string value together with its formatting runs.
+type RichString struct {
+	// Text is the plain text of the string.
+	Text string
+	// Runs lists the formatting runs in ascending Start order.  It is empty
+	// when the string carries no rich-text formatting.
+	Runs []Run
+	// Phonetic holds the phonetic guide (furigana) of the string, or nil when
+	// the string has none.
+	Phonetic *Phonetic
+}
 
-	// Read the flag byte.
+// PhoneticType is the character set of phonetic text (the phType field of
+// PhRun).
+type PhoneticType uint8
+
+const (
+	PhoneticHalfwidthKatakana PhoneticType = 0
+	PhoneticFullwidthKatakana PhoneticType = 1
+	PhoneticHiragana          PhoneticType = 2
+	PhoneticNoConversion      PhoneticType = 3
+)
+
+// PhoneticAlignment is the horizontal alignment of phonetic text above its
+// base text (the alcH field of PhRun).
+type PhoneticAlignment uint8
+
+const (
+	PhoneticAlignNoControl   PhoneticAlignment = 0
+	PhoneticAlignLeft        PhoneticAlignment = 1
+	PhoneticAlignCenter      PhoneticAlignment = 2
+	PhoneticAlignDistributed PhoneticAlignment = 3
+)
+
+// Phonetic is the phonetic guide of a string: the reading as a whole plus the
+// runs that tie parts of the reading to the base characters they annotate.
+type Phonetic struct {
+	// Text is the complete phonetic reading, e.g. "トウキョウ" for "東京".
+	// It is the value to sort or search by.
+	Text string
+	// Runs maps segments of Text onto the base text, in ascending order.
+	Runs []PhoneticRun
+}
+
+// PhoneticRun ties one segment of the phonetic text to a span of the base
+// text.  The segment runs from Start up to the Start of the next run (or the
+// end of the phonetic text).  All offsets and lengths are in runes.
+type PhoneticRun struct {
+	// Start is the offset into Phonetic.Text where the segment begins.
+	Start int
+	// BaseStart is the offset into the base text of the first annotated
+	// character.
+	BaseStart int
+	// BaseLen is the number of annotated base characters.
+	BaseLen int
+	// Font is the 0-based font index used to display the phonetic text.
+	Font int
+	// Type is the character set of the phonetic text.
+	Type PhoneticType
+	// Alignment is the alignment of the phonetic text over its base.
+	Alignment PhoneticAlignment
+}
+
+// ReadRichString reads a RichStr structure (MS-XLSB §2.5.121) from rr:
+//
+//	flags  uint8         — bit 0: fRichStr, bit 1: fExtStr
+//	str    XLWideString
+//	if fRichStr: dwSizeStrRun uint32, then that many StrRun entries
+//	             (ich uint16 — UTF-16 offset, ifnt uint16 — font index)
+//	if fExtStr:  phoneticStr XLWideString
+//	             dwPhoneticRun uint32, then that many PhRun entries:
+//	               ichFirst uint16 — UTF-16 offset into phoneticStr
+//	               ichMom   uint16 — UTF-16 offset into str
+//	               cchMom   uint16 — UTF-16 length in str
+//	               ifnt     uint16
+//	               flags    uint16 — bits 0–1: phType, bits 2–3: alcH
+//
+// A truncated run array yields the text with the data read so far and an
+// error.
+func ReadRichString(rr *record.RecordReader) (RichString, error) {
 	flags, err := rr.ReadUint8()
 	if err != nil {
-		// No data at all — treat as empty string.
-		return "", nil
+		return RichString{}, fmt.Errorf("rich string: read flags: %w", err)
 	}
-	fRichStr := (flags & 0x01) != 0
-	fExtStr := (flags & 0x02) != 0
-
-	// If fRichStr is set, a 4-byte crun count follows before the string.
-	if fRichStr {
-		if _, err := rr.ReadUint32(); err != nil {
-			return "", fmt.Errorf("parseSI: read crun: %w", err)
+	text, err := rr.ReadString()
+	if err != nil {
+		return RichString{}, fmt.Errorf("rich string: %w", err)
+	}
+	rs := RichString{Text: text}
+	// The offset table is only needed for runs and phonetics; plain strings,
+	// the bulk of a large SST, skip it.
+	var offsets func(int) int
+	if flags&0x01 != 0 {
+		n, err := rr.ReadUint32()
+		if err != nil {
+			return rs, fmt.Errorf("rich string: read run count: %w", err)
+		}
+		// Each StrRun is 4 bytes; reject counts the payload cannot hold before
+		// allocating.
+		if uint64(n)*4 > uint64(rr.Len()) {
+			return rs, fmt.Errorf("rich string: run count %d exceeds record length", n)
+		}
+		offsets = utf16ToRuneOffsets(text)
+		rs.Runs = make([]Run, 0, n)
+		for range n {
+			ich, _ := rr.ReadUint16()
+			ifnt, _ := rr.ReadUint16()
+			rs.Runs = append(rs.Runs, Run{Start: offsets(int(ich)), Font: int(ifnt)})
 		}
 	}
-	// If fExtStr is set, a 4-byte phonetic-data size follows.
-	if fExtStr {
-		if _, err := rr.ReadUint32(); err != nil {
-			return "", fmt.Errorf("parseSI: read extStr size: %w", err)
+	if flags&0x02 != 0 {
+		if offsets == nil {
+			offsets = utf16ToRuneOffsets(text)
+		}
+		ph, err := readPhonetic(rr, offsets)
+		if err != nil {
+			return rs, fmt.Errorf("rich string: %w", err)
 		}
+		rs.Phonetic = ph
 	}
+	return rs, nil
+}
 
-	s, err := rr.ReadString()
+// readPhonetic reads the phonetic part of a RichStr.  baseOffsets maps UTF-16
+// offsets of the base text to rune offsets.
+func readPhonetic(rr *record.RecordReader, baseOffsets func(int) int) (*Phonetic, error) {
+	text, err := rr.ReadString()
+	if err != nil {
+		return nil, fmt.Errorf("read phonetic text: %w", err)
+	}
+	ph := &Phonetic{Text: text}
+	n, err := rr.ReadUint32()
 	if err != nil {
-		return "", fmt.Errorf("parseSI: %w", err)
+		return ph, fmt.Errorf("read phonetic run count: %w", err)
+	}
+	// Each PhRun is 10 bytes.
+	if uint64(n)*10 > uint64(rr.Len()) {
+		return ph, fmt.Errorf("phonetic run count %d exceeds record length", n)
+	}
+	offsets := utf16ToRuneOffsets(text)
+	ph.Runs = make([]PhoneticRun, 0, n)
+	for range n {
+		ichFirst, _ := rr.ReadUint16()
+		ichMom, _ := rr.ReadUint16()
+		cchMom, _ := rr.ReadUint16()
+		ifnt, _ := rr.ReadUint16()
+		bits, _ := rr.ReadUint16()
+		baseStart := baseOffsets(int(ichMom))
+		ph.Runs = append(ph.Runs, PhoneticRun{
+			Start:     offsets(int(ichFirst)),
+			BaseStart: baseStart,
+			BaseLen:   baseOffsets(int(ichMom)+int(cchMom)) - baseStart,
+			Font:      int(ifnt),
+			Type:      PhoneticType(bits & 0x03),
+			Alignment: PhoneticAlignment(bits >> 2 & 0x03),
+		})
+	}
+	return ph, nil
+}
+
+// utf16ToRuneOffsets returns a function mapping a UTF-16 code-unit offset
+// into s (as stored in StrRun.ich) to the corresponding rune offset.  Offsets
+// past the end of s map to the rune length of s.
+func utf16ToRuneOffsets(s string) func(int) int {
+	// units[i] is the UTF-16 offset at which rune i starts.
+	units := make([]int, 0, len(s))
+	u := 0
+	for _, r := range s {
+		units = append(units, u)
+		if r >= 0x10000 {
+			u += 2
+		} else {
+			u++
+		}
+	}
+	return func(ich int) int {
+		return sort.SearchInts(units, ich)
+	}
+}
+
+// Segment is a contiguous piece of a rich string rendered in a single font.
+type Segment struct {
+	// Text is the text of the segment.
+	Text string
+	// Font is the 0-based font index, or -1 for text that precedes the first
+	// run and therefore uses the cell's own font.
+	Font int
+}
+
+// Segments splits the text into one Segment per formatting run, which is
+// the form needed to render the string (e.g. as HTML spans).  Empty
+// segments are omitted.  A string without runs yields a single segment with
+// Font -1.
+func (rs RichString) Segments() []Segment {
+	runes := []rune(rs.Text)
+	var segs []Segment
+	add := func(from, to, font int) {
+		from = min(max(from, 0), len(runes))
+		to = min(max(to, from), len(runes))
+		if to > from {
+			segs = append(segs, Segment{Text: string(runes[from:to]), Font: font})
+		}
+	}
+	if len(rs.Runs) == 0 {
+		add(0, len(runes), -1)
+		return segs
+	}
+	add(0, rs.Runs[0].Start, -1)
+	for i, r := range rs.Runs {
+		end := len(runes)
+		if i+1 < len(rs.Runs) {
+			end = rs.Runs[i+1].Start
+		}
+		add(r.Start, end, r.Font)
 	}
-	return s, nil
+	return segs
 }
 
 // NewFromBytes is a convenience wrapper that builds a StringTable from an
@@ -0,0 +1,81 @@
+package styles
+
+// HAlign is the horizontal alignment of cell contents (the alc field of
+// BrtXF).
+type HAlign uint8
+
+const (
+	// HAlignGeneral aligns text left, numbers and dates right, and booleans
+	// and errors centred.
+	HAlignGeneral          HAlign = 0
+	HAlignLeft             HAlign = 1
+	HAlignCenter           HAlign = 2
+	HAlignRight            HAlign = 3
+	HAlignFill             HAlign = 4 // repeat the contents to fill the cell
+	HAlignJustify          HAlign = 5
+	HAlignCenterContinuous HAlign = 6 // centre across empty cells to the right
+	HAlignDistributed      HAlign = 7
+)
+
+// VAlign is the vertical alignment of cell contents (the alcv field of
+// BrtXF).
+type VAlign uint8
+
+const (
+	VAlignTop         VAlign = 0
+	VAlignCenter      VAlign = 1
+	VAlignBottom      VAlign = 2
+	VAlignJustify     VAlign = 3
+	VAlignDistributed VAlign = 4
+)
+
+// ReadingOrder is the text direction of a cell.
+type ReadingOrder uint8
+
+const (
+	// ReadingOrderContext takes the direction from the first strong
+	// character of the text.
+	ReadingOrderContext     ReadingOrder = 0
+	ReadingOrderLeftToRight ReadingOrder = 1
+	ReadingOrderRightToLeft ReadingOrder = 2
+)
+
+// RotationStacked is the Alignment.Rotation value for vertical text with the
+// letters stacked top to bottom.
+const RotationStacked = 255
+
+// Alignment holds the text layout settings of a cell format.
+type Alignment struct {
+	// Horizontal is the horizontal alignment.
+	Horizontal HAlign
+	// Vertical is the vertical alignment; VAlignBottom by default.
+	Vertical VAlign
+	// WrapText breaks the text into lines at the column width.
+	WrapText bool
+	// JustifyLastLine justifies the last line of distributed text.
+	JustifyLastLine bool
+	// ShrinkToFit reduces the font size until the text fits the cell.
+	ShrinkToFit bool
+	// Indent is the indentation level; one level is the width of three
+	// characters.
+	Indent int
+	// Rotation is the text angle as stored: 0–90 is counter-clockwise in
+	// degrees, 91–180 is 1–90 degrees clockwise, and RotationStacked is
+	// stacked vertical text.  Use Angle for a signed value.
+	Rotation int
+	// ReadingOrder is the text direction.
+	ReadingOrder ReadingOrder
+}
+
+// Angle returns the text rotation in degrees from -90 (clockwise) to 90
+// (counter-clockwise).  It returns 0 for stacked text; check Rotation for
+// RotationStacked.
+func (a Alignment) Angle() int {
+	switch {
+	case a.Rotation <= 90:
+		return a.Rotation
+	case a.Rotation <= 180:
+		return 90 - a.Rotation
+	}
+	return 0
+}
@@ -0,0 +1,45 @@
+package styles
+
+// BorderStyle is the line style of a border edge (the dg field of Blxf).
+type BorderStyle uint8
+
+const (
+	BorderNone             BorderStyle = 0
+	BorderThin             BorderStyle = 1
+	BorderMedium           BorderStyle = 2
+	BorderDashed           BorderStyle = 3
+	BorderDotted           BorderStyle = 4
+	BorderThick            BorderStyle = 5
+	BorderDouble           BorderStyle = 6
+	BorderHair             BorderStyle = 7
+	BorderMediumDashed     BorderStyle = 8
+	BorderDashDot          BorderStyle = 9
+	BorderMediumDashDot    BorderStyle = 10
+	BorderDashDotDot       BorderStyle = 11
+	BorderMediumDashDotDot BorderStyle = 12
+	BorderSlantDashDot     BorderStyle = 13
+)
+
+// BorderEdge is one edge of a border.
+type BorderEdge struct {
+	// Style is the line style; BorderNone when the edge is not drawn.
+	Style BorderStyle
+	// Color is the line colour.
+	Color Color
+}
+
+// Border is a border definition from the Borders table of xl/styles.bin.
+// Cell formats refer to borders by their 0-based position in that table.
+//
+// Inside (horizontal and vertical) edges only occur in differential formats
+// and are not part of a cell border.
+type Border struct {
+	Top, Bottom, Left, Right BorderEdge
+	// Diagonal is the line drawn by DiagonalUp and DiagonalDown.
+	Diagonal BorderEdge
+	// DiagonalUp draws Diagonal from the bottom-left to the top-right corner.
+	DiagonalUp bool
+	// DiagonalDown draws Diagonal from the top-left to the bottom-right
+	// corner.
+	DiagonalDown bool
+}
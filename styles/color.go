@@ -0,0 +1,218 @@
+package styles
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/TsubasaBE/go-xlsb/record"
+)
+
+// ColorType identifies how a Color value is specified (the xColorType field
+// of the BIFF12 Color structure, MS-XLSB §2.5.52).
+type ColorType uint8
+
+const (
+	// ColorAuto is the automatic (system-defined) colour.
+	ColorAuto ColorType = 0
+	// ColorIndexed refers to an entry of the indexed colour palette.
+	ColorIndexed ColorType = 1
+	// ColorRGB is an explicit ARGB value.
+	ColorRGB ColorType = 2
+	// ColorTheme refers to a colour of the workbook theme.
+	ColorTheme ColorType = 3
+)
+
+// Color is a BIFF12 colour reference as stored in styles.bin.
+type Color struct {
+	// Type specifies which of the remaining fields identifies the colour.
+	Type ColorType
+	// Index is the palette index (ColorIndexed) or the theme colour index
+	// (ColorTheme).  It is 0 for the other types.
+	Index int
+	// Tint lightens (positive) or darkens (negative) the colour; the range is
+	// -1.0 to 1.0 and 0 means no change.
+	Tint float64
+	// RGB is the colour as 0xAARRGGBB.  It is meaningful for ColorRGB and
+	// may also carry a cached value for the other types.
+	RGB uint32
+}
+
+// ReadColor decodes a BIFF12 Color structure (MS-XLSB §2.5.52):
+//
+//	flags          uint8  (bit 0: fValidRGB, bits 1–7: xColorType)
+//	index          uint8
+//	nTintAndShade  int16  (tint × 32767)
+//	bRed, bGreen, bBlue, bAlpha  uint8
+func ReadColor(rr *record.RecordReader) (Color, error) {
+	var b [8]byte
+	if err := rr.Read(b[:]); err != nil {
+		return Color{}, err
+	}
+	c := Color{
+		Type: ColorType(b[0] >> 1),
+		Tint: float64(int16(binary.LittleEndian.Uint16(b[2:4]))) / 32767,
+		RGB:  uint32(b[7])<<24 | uint32(b[4])<<16 | uint32(b[5])<<8 | uint32(b[6]),
+	}
+	if c.Type == ColorIndexed || c.Type == ColorTheme {
+		c.Index = int(b[1])
+	}
+	return c, nil
+}
+
+// System colour indices of the indexed palette.
+const (
+	// IndexSystemForeground is the window text colour (black).
+	IndexSystemForeground = 64
+	// IndexSystemBackground is the window background colour (white).
+	IndexSystemBackground = 65
+)
+
+// DefaultPalette is the built-in indexed colour palette (indices 0–63) as
+// 0xAARRGGBB.  A workbook may replace it with its own (Workbook.Palette).
+var DefaultPalette = [64]uint32{
+	0xFF000000, 0xFFFFFFFF, 0xFFFF0000, 0xFF00FF00, 0xFF0000FF, 0xFFFFFF00, 0xFFFF00FF, 0xFF00FFFF,
+	0xFF000000, 0xFFFFFFFF, 0xFFFF0000, 0xFF00FF00, 0xFF0000FF, 0xFFFFFF00, 0xFFFF00FF, 0xFF00FFFF,
+	0xFF800000, 0xFF008000, 0xFF000080, 0xFF808000, 0xFF800080, 0xFF008080, 0xFFC0C0C0, 0xFF808080,
+	0xFF9999FF, 0xFF993366, 0xFFFFFFCC, 0xFFCCFFFF, 0xFF660066, 0xFFFF8080, 0xFF0066CC, 0xFFCCCCFF,
+	0xFF000080, 0xFFFF00FF, 0xFFFFFF00, 0xFF00FFFF, 0xFF800080, 0xFF800000, 0xFF008080, 0xFF0000FF,
+	0xFF00CCFF, 0xFFCCFFFF, 0xFFCCFFCC, 0xFFFFFF99, 0xFF99CCFF, 0xFFFF99CC, 0xFFCC99FF, 0xFFFFCC99,
+	0xFF3366FF, 0xFF33CCCC, 0xFF99CC00, 0xFFFFCC00, 0xFFFF9900, 0xFFFF6600, 0xFF666699, 0xFF969696,
+	0xFF003366, 0xFF339966, 0xFF003300, 0xFF333300, 0xFF993300, 0xFF993366, 0xFF333399, 0xFF333333,
+}
+
+// ResolveColor returns c as a concrete 0xAARRGGBB value with its tint
+// applied.
+//
+//   - ColorAuto resolves to the system foreground colour (black); callers
+//     drawing a background should treat ColorAuto as "no colour" instead.
+//   - ColorIndexed looks the index up in palette, falling back to
+//     DefaultPalette for indices the palette does not cover.  Indices 64 and
+//     65 are the system foreground and background colours.
+//   - ColorRGB uses c.RGB.
+//   - ColorTheme looks the index up in theme.Colors, or DefaultThemeColors
+//     when theme is nil.  As in Excel, indices 0–3 select light 1, dark 1,
+//     light 2 and dark 2 (the scheme order swaps each pair).
+//
+// palette and theme may be nil.
+func ResolveColor(c Color, theme *Theme, palette []uint32) uint32 {
+	var argb uint32
+	switch c.Type {
+	case ColorIndexed:
+		argb = indexedColor(c.Index, palette)
+	case ColorRGB:
+		argb = c.RGB
+	case ColorTheme:
+		colors := &DefaultThemeColors
+		if theme != nil {
+			colors = &theme.Colors
+		}
+		idx := c.Index
+		if idx < 4 {
+			idx ^= 1 // bg1/tx1/bg2/tx2 map to lt1/dk1/lt2/dk2
+		}
+		if idx < len(colors) {
+			argb = colors[idx]
+		} else {
+			argb = colors[ThemeDark1]
+		}
+	default:
+		argb = DefaultPalette[0]
+	}
+	return ApplyTint(argb, c.Tint)
+}
+
+// indexedColor returns entry i of the indexed palette.
+func indexedColor(i int, palette []uint32) uint32 {
+	switch {
+	case i >= 0 && i < len(palette):
+		return palette[i]
+	case i >= 0 && i < len(DefaultPalette):
+		return DefaultPalette[i]
+	case i == IndexSystemBackground:
+		return 0xFFFFFFFF
+	}
+	return 0xFF000000
+}
+
+// ApplyTint lightens (tint > 0) or darkens (tint < 0) an 0xAARRGGBB colour
+// by moving its HLS luminance towards white or black, as Excel does.  The
+// alpha channel is kept.
+func ApplyTint(argb uint32, tint float64) uint32 {
+	if tint == 0 {
+		return argb
+	}
+	tint = max(-1, min(1, tint))
+	r := float64(argb>>16&0xFF) / 255
+	g := float64(argb>>8&0xFF) / 255
+	b := float64(argb&0xFF) / 255
+	h, l, s := rgbToHLS(r, g, b)
+	if tint < 0 {
+		l *= 1 + tint
+	} else {
+		l = l*(1-tint) + tint
+	}
+	r, g, b = hlsToRGB(h, l, s)
+	to8 := func(v float64) uint32 { return uint32(math.Round(max(0, min(1, v)) * 255)) }
+	return argb&0xFF000000 | to8(r)<<16 | to8(g)<<8 | to8(b)
+}
+
+// rgbToHLS converts RGB components in [0, 1] to hue (in [0, 1)), luminance
+// and saturation.
+func rgbToHLS(r, g, b float64) (h, l, s float64) {
+	hi, lo := max(r, g, b), min(r, g, b)
+	l = (hi + lo) / 2
+	if hi == lo {
+		return 0, l, 0
+	}
+	d := hi - lo
+	if l <= 0.5 {
+		s = d / (hi + lo)
+	} else {
+		s = d / (2 - hi - lo)
+	}
+	switch hi {
+	case r:
+		h = (g - b) / d
+	case g:
+		h = 2 + (b-r)/d
+	default:
+		h = 4 + (r-g)/d
+	}
+	h /= 6
+	if h < 0 {
+		h++
+	}
+	return h, l, s
+}
+
+// hlsToRGB is the inverse of rgbToHLS.
+func hlsToRGB(h, l, s float64) (r, g, b float64) {
+	if s == 0 {
+		return l, l, l
+	}
+	var m2 float64
+	if l <= 0.5 {
+		m2 = l * (1 + s)
+	} else {
+		m2 = l + s - l*s
+	}
+	m1 := 2*l - m2
+	return hueToRGB(m1, m2, h+1.0/3), hueToRGB(m1, m2, h), hueToRGB(m1, m2, h-1.0/3)
+}
+
+func hueToRGB(m1, m2, h float64) float64 {
+	if h < 0 {
+		h++
+	} else if h > 1 {
+		h--
+	}
+	switch {
+	case h < 1.0/6:
+		return m1 + (m2-m1)*6*h
+	case h < 0.5:
+		return m2
+	case h < 2.0/3:
+		return m1 + (m2-m1)*(2.0/3-h)*6
+	}
+	return m1
+}
@@ -0,0 +1,78 @@
+package styles
+
+// FillPattern is the pattern of a cell fill (the fls field of BrtFill).
+type FillPattern uint8
+
+const (
+	FillNone            FillPattern = 0
+	FillSolid           FillPattern = 1
+	FillMediumGray      FillPattern = 2
+	FillDarkGray        FillPattern = 3
+	FillLightGray       FillPattern = 4
+	FillDarkHorizontal  FillPattern = 5
+	FillDarkVertical    FillPattern = 6
+	FillDarkDown        FillPattern = 7
+	FillDarkUp          FillPattern = 8
+	FillDarkGrid        FillPattern = 9
+	FillDarkTrellis     FillPattern = 10
+	FillLightHorizontal FillPattern = 11
+	FillLightVertical   FillPattern = 12
+	FillLightDown       FillPattern = 13
+	FillLightUp         FillPattern = 14
+	FillLightGrid       FillPattern = 15
+	FillLightTrellis    FillPattern = 16
+	FillGray125         FillPattern = 17
+	FillGray0625        FillPattern = 18
+	// FillGradient marks a gradient fill; Fill.Gradient holds its stops.
+	FillGradient FillPattern = 0x28
+)
+
+// GradientType is the shape of a gradient fill.
+type GradientType uint8
+
+const (
+	// GradientLinear blends the stops along a line at Gradient.Degree.
+	GradientLinear GradientType = 0
+	// GradientPath blends the stops outward from the rectangle given by
+	// Gradient.Left, Right, Top and Bottom.
+	GradientPath GradientType = 1
+)
+
+// GradientStop is one colour of a gradient.
+type GradientStop struct {
+	// Position is where the colour sits along the gradient, from 0 to 1.
+	Position float64
+	// Color is the colour at Position.
+	Color Color
+}
+
+// Gradient describes a gradient fill.
+type Gradient struct {
+	// Type is linear or path.
+	Type GradientType
+	// Degree is the angle of a linear gradient, in degrees.
+	Degree float64
+	// Left, Right, Top and Bottom bound the inner rectangle of a path
+	// gradient as fractions of the cell, from 0 to 1.
+	Left, Right, Top, Bottom float64
+	// Stops are the colours of the gradient in file order.
+	Stops []GradientStop
+}
+
+// Fill is a fill definition from the Fills table of xl/styles.bin.  Cell
+// formats refer to fills by their 0-based position in that table; Excel
+// always writes FillNone at index 0 and FillGray125 at index 1.
+//
+// For FillSolid the cell background is FgColor.  For the other patterns the
+// pattern is drawn in FgColor over BgColor.
+type Fill struct {
+	// Pattern is the fill pattern, or FillGradient.
+	Pattern FillPattern
+	// FgColor is the pattern (foreground) colour.
+	FgColor Color
+	// BgColor is the colour behind the pattern.
+	BgColor Color
+	// Gradient describes a gradient fill; it is nil unless Pattern is
+	// FillGradient.
+	Gradient *Gradient
+}
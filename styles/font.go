@@ -0,0 +1,85 @@
+package styles
+
+// Underline is the underline style of a font (the uls field of BrtFont).
+type Underline uint8
+
+const (
+	UnderlineNone             Underline = 0x00
+	UnderlineSingle           Underline = 0x01
+	UnderlineDouble           Underline = 0x02
+	UnderlineSingleAccounting Underline = 0x21
+	UnderlineDoubleAccounting Underline = 0x22
+)
+
+// VertAlign is the vertical position of text relative to the baseline (the
+// sss field of BrtFont).
+type VertAlign uint8
+
+const (
+	// VertAlignBaseline is normal text.
+	VertAlignBaseline VertAlign = 0
+	// VertAlignSuperscript raises the text and reduces its size.
+	VertAlignSuperscript VertAlign = 1
+	// VertAlignSubscript lowers the text and reduces its size.
+	VertAlignSubscript VertAlign = 2
+)
+
+// FontFamily is the font family classification used to pick a substitute
+// when the typeface is not installed (the bFamily field of BrtFont).
+type FontFamily uint8
+
+const (
+	FontFamilyNone       FontFamily = 0
+	FontFamilyRoman      FontFamily = 1 // proportional serif, e.g. Times New Roman
+	FontFamilySwiss      FontFamily = 2 // proportional sans-serif, e.g. Arial
+	FontFamilyModern     FontFamily = 3 // monospace, e.g. Courier New
+	FontFamilyScript     FontFamily = 4
+	FontFamilyDecorative FontFamily = 5
+)
+
+// FontScheme says whether a font follows the workbook theme (the
+// bFontScheme field of BrtFont).
+type FontScheme uint8
+
+const (
+	// FontSchemeNone is a font chosen explicitly.
+	FontSchemeNone FontScheme = 0
+	// FontSchemeMajor is the theme's heading font.
+	FontSchemeMajor FontScheme = 1
+	// FontSchemeMinor is the theme's body font.
+	FontSchemeMinor FontScheme = 2
+)
+
+// Font is a font definition from the Fonts table of xl/styles.bin.  Cell
+// formats and the formatting runs of rich strings refer to fonts by their
+// 0-based position in that table.
+type Font struct {
+	// Name is the typeface name, e.g. "Calibri".
+	Name string
+	// Size is the font size in points.
+	Size float64
+	// Bold is true when the font weight is bold (700) or heavier.
+	Bold bool
+	// Weight is the font weight from 100 to 1000; 400 is normal and 700
+	// bold.
+	Weight int
+	// Italic is true for an italic font.
+	Italic bool
+	// Underline is the underline style; UnderlineNone when not underlined.
+	Underline Underline
+	// Strike is true for struck-through text.
+	Strike bool
+	// Outline and Shadow are the Macintosh outline and shadow effects.
+	Outline, Shadow bool
+	// VertAlign is superscript, subscript or VertAlignBaseline.
+	VertAlign VertAlign
+	// Color is the font colour.
+	Color Color
+	// Family is the font family used for substitution.
+	Family FontFamily
+	// Charset is the Windows character set (0 ANSI, 1 default, 2 symbol,
+	// 128 Shift-JIS, 134 GB2312, …).
+	Charset int
+	// Scheme says whether the font is the theme's major or minor font.
+	Scheme FontScheme
+}
@@ -16,6 +16,40 @@ type XFStyle struct {
 	// FormatStr is the raw format string from the corresponding BrtFmt record.
 	// It is empty for built-in IDs that have no custom override.
 	FormatStr string
+	// FontID is the 0-based index of the cell font in the font table.
+	FontID int
+	// Font is the font at FontID, shared with the workbook's font table.  It
+	// is nil when FontID is out of range.
+	Font *Font
+	// FillID is the 0-based index of the cell fill in the fill table.
+	FillID int
+	// Fill is the fill at FillID, shared with the workbook's fill table.  It
+	// is nil when FillID is out of range.
+	Fill *Fill
+	// BorderID is the 0-based index of the cell border in the border table.
+	BorderID int
+	// Border is the border at BorderID, shared with the workbook's border
+	// table.  It is nil when BorderID is out of range.
+	Border *Border
+	// Alignment holds the horizontal and vertical alignment, wrapping,
+	// indentation, rotation and text direction.
+	Alignment Alignment
+	// Locked is true when the cell cannot be edited while its sheet is
+	// protected.  It is the default for cells; the flag has no effect on an
+	// unprotected sheet.
+	Locked bool
+	// Hidden is true when the cell's formula is hidden in the formula bar
+	// while its sheet is protected.
+	Hidden bool
+	// QuotePrefix is true when the cell text was entered with a leading
+	// apostrophe, forcing a number-like entry to stay text.
+	QuotePrefix bool
+	// PivotButton is true when the cell shows a PivotTable drop-down button.
+	PivotButton bool
+	// The Apply fields are true when the format overrides the corresponding
+	// part of its parent cell style instead of inheriting it.
+	ApplyNumberFormat, ApplyFont, ApplyAlignment bool
+	ApplyBorder, ApplyFill, ApplyProtection      bool
 }
 
 // StyleTable maps XF index → XFStyle.  The slice index is the 0-based XF
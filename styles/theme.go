@@ -0,0 +1,57 @@
+package styles
+
+// Theme colour slots, in the order of the theme's colour scheme.  They index
+// Theme.Colors.  Note that a Color of type ColorTheme numbers the first four
+// slots differently; see ResolveColor.
+const (
+	ThemeDark1 = iota
+	ThemeLight1
+	ThemeDark2
+	ThemeLight2
+	ThemeAccent1
+	ThemeAccent2
+	ThemeAccent3
+	ThemeAccent4
+	ThemeAccent5
+	ThemeAccent6
+	ThemeHyperlink
+	ThemeFollowedHyperlink
+)
+
+// Theme is the workbook theme from xl/theme/theme1.xml: the colour scheme
+// that theme colours refer to and the major and minor fonts that theme fonts
+// (Font.Scheme) refer to.
+type Theme struct {
+	// Name is the theme name, e.g. "Office Theme".
+	Name string
+	// ColorSchemeName is the name of the colour scheme.
+	ColorSchemeName string
+	// Colors are the twelve scheme colours as 0xAARRGGBB, indexed by the
+	// ThemeDark1 … ThemeFollowedHyperlink constants.  Slots missing from the
+	// file hold the default Office colours.
+	Colors [12]uint32
+	// FontSchemeName is the name of the font scheme.
+	FontSchemeName string
+	// MajorFont is the heading font (FontSchemeMajor).
+	MajorFont ThemeFont
+	// MinorFont is the body font (FontSchemeMinor).
+	MinorFont ThemeFont
+}
+
+// ThemeFont is the major or minor font of a theme.
+type ThemeFont struct {
+	// Latin, EastAsian and Complex are the typefaces for Latin, East Asian
+	// and complex-script text.  EastAsian and Complex are often empty.
+	Latin, EastAsian, Complex string
+	// Scripts maps script codes such as "Jpan" or "Hang" to the typeface
+	// used for that script.
+	Scripts map[string]string
+}
+
+// DefaultThemeColors are the scheme colours of the default Office theme,
+// used when a workbook has no theme part.
+var DefaultThemeColors = [12]uint32{
+	0xFF000000, 0xFFFFFFFF, 0xFF44546A, 0xFFE7E6E6,
+	0xFF4472C4, 0xFFED7D31, 0xFFA5A5A5, 0xFFFFC000, 0xFF5B9BD5, 0xFF70AD47,
+	0xFF0563C1, 0xFF954F72,
+}
@@ -0,0 +1,134 @@
+package workbook
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/TsubasaBE/go-xlsb/internal/cfb"
+	"github.com/TsubasaBE/go-xlsb/internal/offcrypto"
+)
+
+// Sentinel errors returned (wrapped) by Open and OpenReader.  Test for them
+// with errors.Is.
+var (
+	// ErrNotXLSB is returned when the input is not an .xlsb workbook: an
+	// .xlsx or .xlsm package, a legacy .xls file, another ZIP archive, or
+	// unrecognised data.
+	ErrNotXLSB = errors.New("workbook: not an .xlsb workbook")
+	// ErrEncrypted is returned for a password-protected workbook opened
+	// without its password, or encrypted with an unsupported scheme.
+	ErrEncrypted = errors.New("workbook: workbook is encrypted")
+	// ErrWrongPassword is returned when the password given with
+	// WithPassword does not match.
+	ErrWrongPassword = errors.New("workbook: wrong password")
+)
+
+// Format is the kind of file identified by Detect.
+type Format int
+
+const (
+	// FormatUnknown is data that is none of the formats below.
+	FormatUnknown Format = iota
+	// FormatXLSB is an Excel Binary Workbook (.xlsb).
+	FormatXLSB
+	// FormatXLSX is an Office Open XML workbook (.xlsx, .xlsm, .xltx).
+	FormatXLSX
+	// FormatXLS is a legacy Excel 97-2003 (BIFF8) workbook in an OLE
+	// compound file.
+	FormatXLS
+	// FormatEncrypted is a password-protected OOXML package.  Whether it
+	// holds an .xlsb or an .xlsx is only known after decryption.
+	FormatEncrypted
+	// FormatZIP is a ZIP archive that is not an Excel workbook.
+	FormatZIP
+)
+
+// String returns a short name for the format.
+func (f Format) String() string {
+	switch f {
+	case FormatXLSB:
+		return "xlsb"
+	case FormatXLSX:
+		return "xlsx"
+	case FormatXLS:
+		return "xls"
+	case FormatEncrypted:
+		return "encrypted"
+	case FormatZIP:
+		return "zip"
+	}
+	return "unknown"
+}
+
+// Detect identifies the format of the file in r from its container and
+// part names, without parsing the workbook.  size is the total byte size of
+// the data.  An error is returned only when r cannot be read; data that is
+// merely unrecognised yields FormatUnknown.
+func Detect(r io.ReaderAt, size int64) (Format, error) {
+	var sig [8]byte
+	n, err := r.ReadAt(sig[:], 0)
+	if err != nil && err != io.EOF {
+		return FormatUnknown, fmt.Errorf("workbook: detect: %w", err)
+	}
+	switch {
+	case cfb.IsCFB(sig[:n]):
+		cf, err := cfb.Open(r, size)
+		if err != nil {
+			return FormatUnknown, nil
+		}
+		return detectCFB(cf), nil
+	case bytes.HasPrefix(sig[:n], []byte("PK")):
+		zf, err := zip.NewReader(r, size)
+		if err != nil {
+			return FormatUnknown, nil
+		}
+		return detectZip(zf), nil
+	}
+	return FormatUnknown, nil
+}
+
+// detectCFB classifies a compound file by its root-level streams.
+func detectCFB(cf *cfb.File) Format {
+	if offcrypto.IsEncryptedPackage(cf) {
+		return FormatEncrypted
+	}
+	for _, name := range cf.Streams() {
+		// BIFF8 files name the stream "Workbook"; BIFF5 files "Book".
+		if strings.EqualFold(name, "Workbook") || strings.EqualFold(name, "Book") {
+			return FormatXLS
+		}
+	}
+	return FormatUnknown
+}
+
+// detectZip classifies a ZIP archive by the name of its workbook part,
+// ignoring case as Open does.
+func detectZip(zf *zip.Reader) Format {
+	f := FormatZIP
+	for _, e := range zf.File {
+		switch strings.ToLower(e.Name) {
+		case "xl/workbook.bin":
+			return FormatXLSB
+		case "xl/workbook.xml":
+			f = FormatXLSX
+		}
+	}
+	return f
+}
+
+// notXLSB returns an ErrNotXLSB error naming the format that was found.
+func notXLSB(f Format) error {
+	switch f {
+	case FormatXLSX:
+		return fmt.Errorf("%w: found an .xlsx/.xlsm package", ErrNotXLSB)
+	case FormatXLS:
+		return fmt.Errorf("%w: found a legacy .xls (BIFF8) file", ErrNotXLSB)
+	case FormatZIP:
+		return fmt.Errorf("%w: ZIP archive has no xl/workbook.bin", ErrNotXLSB)
+	}
+	return ErrNotXLSB
+}
@@ -0,0 +1,90 @@
+package workbook
+
+import (
+	"github.com/TsubasaBE/go-xlsb/internal/isopassword"
+	"github.com/TsubasaBE/go-xlsb/record"
+	"github.com/TsubasaBE/go-xlsb/worksheet"
+)
+
+// Protection holds the workbook protection settings (BrtBookProtection or
+// BrtBookProtectionIso).  Sheet-level protection is reported per sheet by
+// worksheet.Worksheet.Protection.
+type Protection struct {
+	// LockStructure is true when sheets cannot be added, removed, moved,
+	// renamed, hidden or unhidden.
+	LockStructure bool
+	// LockWindows is true when the workbook windows cannot be moved, resized
+	// or closed.
+	LockWindows bool
+	// LockRevision is true when change tracking cannot be turned off.
+	LockRevision bool
+	// Password verifies the password that lifts structure and window
+	// protection.
+	Password worksheet.PasswordHash
+	// RevisionsPassword verifies the password that lifts revision locking.
+	RevisionsPassword worksheet.PasswordHash
+}
+
+// parseBookProtectionRecord decodes a BOOKPROTECTION record (MS-XLSB
+// BrtBookProtection), or a BOOKPROTECTIONISO record (BrtBookProtectionIso)
+// when iso is true.
+//
+//	BrtBookProtection:
+//	  protpwdBook = read_uint16()
+//	  protpwdRev  = read_uint16()
+//	  flags       = read_uint16()  // bit 0 fLockStructure, 1 fLockWindow,
+//	                               // 2 fLockRevision
+//	BrtBookProtectionIso:
+//	  dwSpinCountBook = read_uint32()
+//	  dwSpinCountRev  = read_uint32()
+//	  flags           = read_uint16()
+//	  ipdBookPasswordData, ipdRevPasswordData (see readIsoPassword)
+func parseBookProtectionRecord(data []byte, iso bool) (Protection, error) {
+	rr := record.NewRecordReader(data)
+	var (
+		p                 Protection
+		spinBook, spinRev uint32
+		err               error
+	)
+	if iso {
+		if spinBook, err = rr.ReadUint32(); err != nil {
+			return Protection{}, err
+		}
+		if spinRev, err = rr.ReadUint32(); err != nil {
+			return Protection{}, err
+		}
+	} else {
+		if p.Password.Legacy, err = rr.ReadUint16(); err != nil {
+			return Protection{}, err
+		}
+		if p.RevisionsPassword.Legacy, err = rr.ReadUint16(); err != nil {
+			return Protection{}, err
+		}
+	}
+	flags, err := rr.ReadUint16()
+	if err != nil {
+		return Protection{}, err
+	}
+	p.LockStructure = flags&0x0001 != 0
+	p.LockWindows = flags&0x0002 != 0
+	p.LockRevision = flags&0x0004 != 0
+	if iso {
+		if p.Password, err = readIsoPassword(rr, spinBook); err != nil {
+			return Protection{}, err
+		}
+		if p.RevisionsPassword, err = readIsoPassword(rr, spinRev); err != nil {
+			return Protection{}, err
+		}
+	}
+	return p, nil
+}
+
+// readIsoPassword reads an IsoPasswordData structure from rr and combines
+// it with the spin count stored alongside it in the enclosing record.
+func readIsoPassword(rr *record.RecordReader, spinCount uint32) (worksheet.PasswordHash, error) {
+	d, err := isopassword.Read(rr)
+	if err != nil {
+		return worksheet.PasswordHash{}, err
+	}
+	return worksheet.PasswordHash{Algorithm: d.Algorithm, Hash: d.Hash, Salt: d.Salt, SpinCount: int(spinCount)}, nil
+}
@@ -0,0 +1,128 @@
+package workbook
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/TsubasaBE/go-xlsb/cellref"
+	"github.com/TsubasaBE/go-xlsb/worksheet"
+)
+
+// Range returns the cells of an A1-style range on one sheet as a grid
+// indexed [row][col] relative to the top-left corner of the range.  The
+// reference must be sheet-qualified; quoted sheet names and absolute markers
+// are accepted:
+//
+//	wb.Range("Sheet1!B2:D10")
+//	wb.Range("'Q1 Sales'!$A$1")
+//	wb.Range("Sheet1!A:A")   // whole column
+//	wb.Range("Sheet1!3:3")   // whole row
+//
+// The range is clamped to the sheet's used range — its Dimension, widened by
+// any cells found outside it — so "A:A" or "B2:XFD1048576" does not allocate
+// a grid for the empty part of the sheet; a range lying entirely outside the
+// data yields an empty grid.  Every grid cell carries its absolute R and C.
+// Cells are reported as Rows reports them, including blank records (Kind
+// KindBlank); positions without a cell record have Kind KindNone and a nil V.
+//
+// Merged areas are not filled: as in Rows, only the anchor cell of a merge
+// carries a value and the other cells it covers have a nil V.  Use
+// Worksheet.MergeCells to propagate anchor values if needed.
+//
+// Only the rows inside the range are decoded: the row index built by
+// Worksheet.Row locates the first of them without reading the rows above.
+func (wb *Workbook) Range(ref string) ([][]worksheet.Cell, error) {
+	a, err := parseRangeRef(ref)
+	if err != nil {
+		return nil, err
+	}
+	return wb.readArea(a)
+}
+
+// NamedRange returns the cells of a range name as a grid, like Range.  The
+// name is resolved with ResolveName, so sheet-scoped names are addressed as
+// "Sheet1!Name".
+func (wb *Workbook) NamedRange(name string) ([][]worksheet.Cell, error) {
+	a, err := wb.ResolveName(name)
+	if err != nil {
+		return nil, err
+	}
+	return wb.readArea(a)
+}
+
+// readArea reads the cells of a onto a grid.
+func (wb *Workbook) readArea(a Area) ([][]worksheet.Cell, error) {
+	ws, err := wb.SheetByName(a.Sheet)
+	if err != nil {
+		return nil, err
+	}
+	infos, err := ws.RowInfos()
+	if err != nil {
+		return nil, fmt.Errorf("workbook: range %s: %w", a.Sheet, err)
+	}
+	// Collect the cells first: the used range is only known once the data
+	// has been read, since cells may lie outside the declared Dimension.
+	cells := make(map[[2]int]worksheet.Cell)
+	lastR, lastC := -1, -1
+	first, _ := slices.BinarySearchFunc(infos, a.R1, func(info worksheet.RowInfo, r int) int {
+		return cmp.Compare(info.R, r)
+	})
+	for _, info := range infos[first:] {
+		if info.R > a.R2 {
+			break
+		}
+		row, err := ws.Row(info.R)
+		if err != nil {
+			return nil, fmt.Errorf("workbook: range %s: %w", a.Sheet, err)
+		}
+		for _, c := range row[min(a.C1, len(row)):min(a.C2+1, len(row))] {
+			if c.Kind == worksheet.KindNone {
+				continue
+			}
+			cells[[2]int{c.R, c.C}] = c
+			lastR, lastC = max(lastR, c.R), max(lastC, c.C)
+		}
+	}
+	maxR, maxC := lastR, lastC
+	if d := ws.Dimension; d != nil {
+		maxR, maxC = max(maxR, d.R+d.H-1), max(maxC, d.C+d.W-1)
+	}
+	a.R2, a.C2 = min(a.R2, maxR), min(a.C2, maxC)
+	if a.R2 < a.R1 || a.C2 < a.C1 {
+		return [][]worksheet.Cell{}, nil
+	}
+
+	grid := make([][]worksheet.Cell, a.R2-a.R1+1)
+	for i := range grid {
+		grid[i] = make([]worksheet.Cell, a.C2-a.C1+1)
+		for j := range grid[i] {
+			pos := [2]int{a.R1 + i, a.C1 + j}
+			if c, ok := cells[pos]; ok {
+				grid[i][j] = c
+			} else {
+				grid[i][j] = worksheet.Cell{R: pos[0], C: pos[1]}
+			}
+		}
+	}
+	return grid, nil
+}
+
+// parseRangeRef parses a sheet-qualified A1 reference ("Sheet1!A1:B2",
+// "'My Sheet'!$A:$A", "Sheet1!3:5") into an Area.
+func parseRangeRef(ref string) (Area, error) {
+	rng, err := cellref.ParseRange(ref)
+	if err != nil {
+		return Area{}, fmt.Errorf("workbook: range %q: %w", ref, err)
+	}
+	if rng.Sheet == "" {
+		return Area{}, fmt.Errorf("workbook: range %q has no sheet name", ref)
+	}
+	return Area{
+		Sheet: rng.Sheet,
+		R1:    rng.First.Row,
+		C1:    rng.First.Col,
+		R2:    rng.Last.Row,
+		C2:    rng.Last.Col,
+	}, nil
+}
@@ -0,0 +1,108 @@
+package workbook
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+
+	"github.com/TsubasaBE/go-xlsb/styles"
+)
+
+// xmlTheme mirrors the parts of a DrawingML theme (a:theme) that are used.
+type xmlTheme struct {
+	Name     string `xml:"name,attr"`
+	Elements struct {
+		ClrScheme struct {
+			Name   string        `xml:"name,attr"`
+			Colors []xmlThemeClr `xml:",any"`
+		} `xml:"clrScheme"`
+		FontScheme struct {
+			Name  string       `xml:"name,attr"`
+			Major xmlThemeFont `xml:"majorFont"`
+			Minor xmlThemeFont `xml:"minorFont"`
+		} `xml:"fontScheme"`
+	} `xml:"themeElements"`
+}
+
+// xmlThemeClr is one slot of a colour scheme (a:dk1, a:accent1, …) holding
+// either an sRGB colour or a system colour with its last known value.
+type xmlThemeClr struct {
+	XMLName xml.Name
+	SRGB    *struct {
+		Val string `xml:"val,attr"`
+	} `xml:"srgbClr"`
+	Sys *struct {
+		LastClr string `xml:"lastClr,attr"`
+	} `xml:"sysClr"`
+}
+
+type xmlThemeFont struct {
+	Latin struct {
+		Typeface string `xml:"typeface,attr"`
+	} `xml:"latin"`
+	EA struct {
+		Typeface string `xml:"typeface,attr"`
+	} `xml:"ea"`
+	CS struct {
+		Typeface string `xml:"typeface,attr"`
+	} `xml:"cs"`
+	Fonts []struct {
+		Script   string `xml:"script,attr"`
+		Typeface string `xml:"typeface,attr"`
+	} `xml:"font"`
+}
+
+// themeSlots maps colour-scheme element names to Theme.Colors indices.
+var themeSlots = map[string]int{
+	"dk1": styles.ThemeDark1, "lt1": styles.ThemeLight1,
+	"dk2": styles.ThemeDark2, "lt2": styles.ThemeLight2,
+	"accent1": styles.ThemeAccent1, "accent2": styles.ThemeAccent2,
+	"accent3": styles.ThemeAccent3, "accent4": styles.ThemeAccent4,
+	"accent5": styles.ThemeAccent5, "accent6": styles.ThemeAccent6,
+	"hlink": styles.ThemeHyperlink, "folHlink": styles.ThemeFollowedHyperlink,
+}
+
+// parseTheme decodes a theme part (xl/theme/theme1.xml).  Colour slots that
+// are missing or unparsable keep the default Office colours.
+func parseTheme(data []byte) (*styles.Theme, error) {
+	var x xmlTheme
+	if err := xml.Unmarshal(data, &x); err != nil {
+		return nil, fmt.Errorf("parse theme XML: %w", err)
+	}
+	th := &styles.Theme{
+		Name:            x.Name,
+		ColorSchemeName: x.Elements.ClrScheme.Name,
+		Colors:          styles.DefaultThemeColors,
+		FontSchemeName:  x.Elements.FontScheme.Name,
+		MajorFont:       x.Elements.FontScheme.Major.themeFont(),
+		MinorFont:       x.Elements.FontScheme.Minor.themeFont(),
+	}
+	for _, c := range x.Elements.ClrScheme.Colors {
+		slot, ok := themeSlots[c.XMLName.Local]
+		if !ok {
+			continue
+		}
+		var hex string
+		switch {
+		case c.SRGB != nil:
+			hex = c.SRGB.Val
+		case c.Sys != nil:
+			hex = c.Sys.LastClr
+		}
+		if v, err := strconv.ParseUint(hex, 16, 32); err == nil && len(hex) == 6 {
+			th.Colors[slot] = 0xFF000000 | uint32(v)
+		}
+	}
+	return th, nil
+}
+
+func (f xmlThemeFont) themeFont() styles.ThemeFont {
+	tf := styles.ThemeFont{Latin: f.Latin.Typeface, EastAsian: f.EA.Typeface, Complex: f.CS.Typeface}
+	for _, s := range f.Fonts {
+		if tf.Scripts == nil {
+			tf.Scripts = make(map[string]string, len(f.Fonts))
+		}
+		tf.Scripts[s.Script] = s.Typeface
+	}
+	return tf
+}
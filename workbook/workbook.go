@@ -5,14 +5,21 @@ import (
 	"archive/zip"
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
+	"os"
 	"path"
 	"strings"
 
 	"github.com/TsubasaBE/go-xlsb/biff12"
+	"github.com/TsubasaBE/go-xlsb/cellref"
+	"github.com/TsubasaBE/go-xlsb/formula"
+	"github.com/TsubasaBE/go-xlsb/internal/cfb"
 	"github.com/TsubasaBE/go-xlsb/internal/dateformat"
+	"github.com/TsubasaBE/go-xlsb/internal/offcrypto"
 	"github.com/TsubasaBE/go-xlsb/internal/rels"
+	"github.com/TsubasaBE/go-xlsb/internal/sheetpr"
 	"github.com/TsubasaBE/go-xlsb/numfmt"
 	"github.com/TsubasaBE/go-xlsb/record"
 	"github.com/TsubasaBE/go-xlsb/stringtable"
@@ -41,30 +48,149 @@ type sheetEntry struct {
 	visibility int    // SheetVisible, SheetHidden, or SheetVeryHidden
 }
 
+// supBook is one supporting link (BrtSupSelf, BrtSupSame, BrtSupBookSrc or
+// BrtSupAddin) in the order the records appear in workbook.bin.  XTI entries
+// refer to supporting links by this position.
+type supBook struct {
+	self   bool     // true for links to the current workbook
+	book   int      // 1-based external-link index; 0 when self is true
+	sheets []string // sheet names of an external workbook, if known
+}
+
+// xti is one entry of the BrtExternSheet table.  first and last are sheet
+// indices within the supporting link; -2 denotes a workbook-level reference
+// and -1 a deleted sheet.
+type xti struct {
+	supBook     int
+	first, last int
+}
+
+// definedName is a parsed BrtName record.  The formula is kept as tokens and
+// decompiled on demand because it may refer to names defined after it.
+type definedName struct {
+	name    string
+	itab    int // 0-based sheet index for sheet-scoped names; -1 for workbook scope
+	hidden  bool
+	builtin bool
+	comment string
+	rgce    []byte
+	extra   []byte
+}
+
+// DefinedName is a name defined in the workbook (Formulas → Name Manager in
+// Excel), such as a named range or a named constant.
+type DefinedName struct {
+	// Name is the name as stored in the file.  Built-in names carry the
+	// "_xlnm." prefix, e.g. "_xlnm.Print_Area".
+	Name string
+	// Scope is the 0-based index into Sheets() of the sheet the name is local
+	// to, or -1 for a workbook-scoped name.
+	Scope int
+	// Hidden is true for names hidden from the Name Manager.
+	Hidden bool
+	// Builtin is true for names with a built-in meaning (print area, print
+	// titles, filter database, …).
+	Builtin bool
+	// Comment is the user-supplied comment; empty when there is none.
+	Comment string
+	// Formula is the refers-to formula including the leading "=", e.g.
+	// "=Sheet1!$B$2:$D$10".  It is empty when the formula cannot be
+	// decompiled.
+	Formula string
+}
+
+// Area is a rectangular cell range on a single worksheet.  Row and column
+// bounds are 0-based and inclusive.
+type Area struct {
+	// Sheet is the name of the worksheet.
+	Sheet string
+	// R1 and C1 are the first row and column; R2 and C2 the last.
+	R1, C1, R2, C2 int
+}
+
 // Workbook represents an open .xlsb workbook.
 type Workbook struct {
 	zr          *zip.ReadCloser      // non-nil when opened by file name
 	zf          *zip.Reader          // always non-nil
-	zipIndex    map[string]*zip.File // name → entry, built once at open time
+	zipIndex    map[string]*zip.File // lower-cased name → entry, built once at open time
 	sheets      []sheetEntry
 	stringTable *stringtable.StringTable
+	supBooks    []supBook     // supporting links, in record order
+	xtis        []xti         // BrtExternSheet entries, indexed by ixti
+	names       []definedName // BrtName records, in order (PtgName index - 1)
 	// Styles is the full XF style table parsed from xl/styles.bin.  It is
 	// exported so that callers who need low-level access to format metadata
 	// can inspect it directly; normal callers should use FormatCell.
 	Styles styles.StyleTable
+	// Fonts is the font table parsed from xl/styles.bin, indexed by the
+	// 0-based font index used by rich-text runs (stringtable.Run.Font).
+	Fonts []styles.Font
+	// Fills is the fill table parsed from xl/styles.bin, indexed by the
+	// 0-based fill index of the cell formats (XFStyle.FillID).
+	Fills []styles.Fill
+	// Borders is the border table parsed from xl/styles.bin, indexed by the
+	// 0-based border index of the cell formats (XFStyle.BorderID).
+	Borders []styles.Border
+	// Palette is the custom indexed colour palette from xl/styles.bin, or
+	// nil when the workbook uses styles.DefaultPalette.
+	Palette []uint32
+	// Theme is the workbook theme from xl/theme/theme1.xml.  It is nil when
+	// the part is absent or cannot be parsed.
+	Theme *styles.Theme
 	// Date1904 is true when the workbook uses the 1904 date system (base
 	// date 1904-01-01, serial 0 = 1904-01-01). Most workbooks use the
 	// default 1900 system (Date1904 == false). Pass this value to
 	// ConvertDateEx when converting numeric cell values to time.Time.
 	Date1904 bool
+	// Protection holds the workbook structure and window protection
+	// settings.  It is nil when the workbook is not protected.
+	Protection *Protection
+}
+
+// Option configures how a workbook is opened.
+type Option func(*openOptions)
+
+// openOptions collects the settings applied by Options.
+type openOptions struct {
+	password    string
+	hasPassword bool
+}
+
+// WithPassword supplies the open password of an encrypted workbook.  Without
+// it, encrypted files are only opened when they use Excel's default
+// password (files that are merely write-protected).
+func WithPassword(password string) Option {
+	return func(o *openOptions) {
+		o.password = password
+		o.hasPassword = true
+	}
 }
 
 // Open opens the named .xlsb file and parses its workbook metadata.
 // The caller must call Close on the returned Workbook when done to release the
 // underlying file handle.
-func Open(name string) (*Workbook, error) {
+//
+// Password-protected files (an encrypted package inside an OLE compound
+// file) are decrypted in memory; pass WithPassword to supply the password.
+func Open(name string, opts ...Option) (*Workbook, error) {
+	if f, err := os.Open(name); err == nil {
+		var sig [8]byte
+		_, _ = io.ReadFull(f, sig[:])
+		if cfb.IsCFB(sig[:]) {
+			defer f.Close()
+			st, err := f.Stat()
+			if err != nil {
+				return nil, fmt.Errorf("workbook: open %q: %w", name, err)
+			}
+			return openEncrypted(f, st.Size(), opts)
+		}
+		_ = f.Close()
+	}
 	rc, err := zip.OpenReader(name)
 	if err != nil {
+		if errors.Is(err, zip.ErrFormat) {
+			return nil, fmt.Errorf("workbook: open %q: %w: %w", name, ErrNotXLSB, err)
+		}
 		return nil, fmt.Errorf("workbook: open %q: %w", name, err)
 	}
 	wb := &Workbook{zr: rc, zf: &rc.Reader}
@@ -77,10 +203,18 @@ func Open(name string) (*Workbook, error) {
 }
 
 // OpenReader parses an .xlsb workbook from an in-memory ReaderAt.
-// size must be the total byte size of the ZIP data.
-func OpenReader(r io.ReaderAt, size int64) (*Workbook, error) {
+// size must be the total byte size of the data.  Encrypted workbooks are
+// handled as in Open.
+func OpenReader(r io.ReaderAt, size int64, opts ...Option) (*Workbook, error) {
+	var sig [8]byte
+	if n, _ := r.ReadAt(sig[:], 0); n == len(sig) && cfb.IsCFB(sig[:]) {
+		return openEncrypted(r, size, opts)
+	}
 	zf, err := zip.NewReader(r, size)
 	if err != nil {
+		if errors.Is(err, zip.ErrFormat) {
+			return nil, fmt.Errorf("workbook: open reader: %w: %w", ErrNotXLSB, err)
+		}
 		return nil, fmt.Errorf("workbook: open reader: %w", err)
 	}
 	wb := &Workbook{zf: zf}
@@ -91,6 +225,48 @@ func OpenReader(r io.ReaderAt, size int64) (*Workbook, error) {
 	return wb, nil
 }
 
+// openEncrypted decrypts the package stored in a compound file (MS-OFFCRYPTO
+// Agile or Standard encryption) and opens the resulting ZIP archive.
+func openEncrypted(r io.ReaderAt, size int64, opts []Option) (*Workbook, error) {
+	var o openOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	cf, err := cfb.Open(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("workbook: %w: %w", ErrNotXLSB, err)
+	}
+	if f := detectCFB(cf); f != FormatEncrypted {
+		return nil, notXLSB(f)
+	}
+	password := o.password
+	if !o.hasPassword {
+		password = offcrypto.DefaultPassword
+	}
+	data, err := offcrypto.Decrypt(cf, password)
+	switch {
+	case err == nil:
+	case errors.Is(err, offcrypto.ErrPassword) && !o.hasPassword:
+		return nil, fmt.Errorf("%w: open it with WithPassword", ErrEncrypted)
+	case errors.Is(err, offcrypto.ErrPassword):
+		return nil, ErrWrongPassword
+	case errors.Is(err, offcrypto.ErrUnsupported):
+		return nil, fmt.Errorf("%w: %w", ErrEncrypted, err)
+	default:
+		return nil, fmt.Errorf("workbook: decrypt: %w", err)
+	}
+	zf, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("workbook: decrypted package: %w", err)
+	}
+	wb := &Workbook{zf: zf}
+	wb.buildZipIndex()
+	if err := wb.parse(); err != nil {
+		return nil, err
+	}
+	return wb, nil
+}
+
 // Sheets returns the display names of all worksheets in order.
 func (wb *Workbook) Sheets() []string {
 	names := make([]string, len(wb.sheets))
@@ -142,6 +318,37 @@ func (wb *Workbook) SheetVisibility(name string) int {
 	return -1
 }
 
+// SheetCodeName returns the VBA code name of the named sheet
+// (case-insensitive), e.g. "Sheet1" for a tab renamed to "Summary".  The
+// code name is read from the SHEETPR record at the start of the sheet part
+// (see worksheet.SheetProperties) without decoding the rest of the sheet.
+// It returns "" when the sheet has no code name.
+func (wb *Workbook) SheetCodeName(name string) (string, error) {
+	lower := strings.ToLower(name)
+	for _, s := range wb.sheets {
+		if strings.ToLower(s.name) == lower {
+			return wb.sheetCodeName(s)
+		}
+	}
+	return "", fmt.Errorf("workbook: sheet %q not found", name)
+}
+
+// SheetByCodeName returns the worksheet whose VBA code name matches codeName
+// (case-insensitive).  It returns a non-nil error if no sheet has that code
+// name.
+func (wb *Workbook) SheetByCodeName(codeName string) (*worksheet.Worksheet, error) {
+	for _, s := range wb.sheets {
+		cn, err := wb.sheetCodeName(s)
+		if err != nil {
+			return nil, err
+		}
+		if cn != "" && strings.EqualFold(cn, codeName) {
+			return wb.openSheet(s)
+		}
+	}
+	return nil, fmt.Errorf("workbook: no sheet with code name %q", codeName)
+}
+
 // FormatCell renders the cell value v using the XF style at index styleIdx.
 // Pass cell.V as v and cell.Style as styleIdx.
 //
@@ -171,6 +378,140 @@ func (wb *Workbook) FormatCell(v any, styleIdx int) string {
 	return numfmt.FormatValue(v, s.NumFmtID, s.FormatStr, wb.Date1904)
 }
 
+// RichRun is one formatting run of a rich-text cell with its font resolved.
+type RichRun struct {
+	// Text is the text covered by the run.
+	Text string
+	// Font is the run's font from wb.Fonts.  Text before the first run uses
+	// the font of the cell's XF.  Font is nil when the index is out of range
+	// or the workbook has no styles part.
+	Font *styles.Font
+}
+
+// RichText splits a rich-text cell into formatting runs with their fonts
+// resolved against wb.Fonts.  It returns nil when cell.Rich is nil, i.e. for
+// plain strings and non-string cells.
+func (wb *Workbook) RichText(cell worksheet.Cell) []RichRun {
+	if cell.Rich == nil {
+		return nil
+	}
+	var cellFont *styles.Font
+	if cell.Style >= 0 && cell.Style < len(wb.Styles) {
+		cellFont = wb.Styles[cell.Style].Font
+	}
+	segs := cell.Rich.Segments()
+	runs := make([]RichRun, len(segs))
+	for i, seg := range segs {
+		runs[i].Text = seg.Text
+		switch {
+		case seg.Font < 0:
+			runs[i].Font = cellFont
+		case seg.Font < len(wb.Fonts):
+			runs[i].Font = &wb.Fonts[seg.Font]
+		}
+	}
+	return runs
+}
+
+// ResolveColor returns c as a concrete 0xAARRGGBB value using the workbook's
+// theme and indexed palette; see styles.ResolveColor.  It works for font,
+// fill, border and tab colours alike.
+func (wb *Workbook) ResolveColor(c styles.Color) uint32 {
+	return styles.ResolveColor(c, wb.Theme, wb.Palette)
+}
+
+// DefinedNames returns all names defined in the workbook, in file order.
+func (wb *Workbook) DefinedNames() []DefinedName {
+	ctx := formulaContext{wb}
+	out := make([]DefinedName, 0, len(wb.names))
+	for _, dn := range wb.names {
+		if dn.name == "" {
+			continue // malformed record kept only as an index placeholder
+		}
+		d := DefinedName{
+			Name:    dn.name,
+			Scope:   dn.itab,
+			Hidden:  dn.hidden,
+			Builtin: dn.builtin,
+			Comment: dn.comment,
+		}
+		if text, err := formula.Decompile(dn.rgce, dn.extra, 0, 0, ctx); err == nil {
+			d.Formula = "=" + text
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+// ResolveName returns the cell range a range name refers to.  name is
+// matched case-insensitively against workbook-scoped names; a sheet-scoped
+// name is addressed as "Sheet1!Name".  An error is returned when the name is
+// unknown or does not refer to a single rectangular range on one sheet (e.g.
+// a constant, a formula, or a multi-area reference).
+func (wb *Workbook) ResolveName(name string) (Area, error) {
+	dn, err := wb.lookupName(name)
+	if err != nil {
+		return Area{}, err
+	}
+	areas, err := wb.nameAreas(dn)
+	if err != nil {
+		return Area{}, err
+	}
+	if len(areas) != 1 {
+		return Area{}, fmt.Errorf("workbook: name %q refers to %d areas", name, len(areas))
+	}
+	return areas[0], nil
+}
+
+// lookupName finds a defined name by its (optionally sheet-qualified) text.
+func (wb *Workbook) lookupName(name string) (definedName, error) {
+	scope := -1
+	if i := strings.LastIndexByte(name, '!'); i >= 0 {
+		sheet := strings.TrimSuffix(strings.TrimPrefix(name[:i], "'"), "'")
+		sheet = strings.ReplaceAll(sheet, "''", "'")
+		scope = wb.sheetIndex(sheet)
+		if scope < 0 {
+			return definedName{}, fmt.Errorf("workbook: sheet %q not found", sheet)
+		}
+		name = name[i+1:]
+	}
+	for _, dn := range wb.names {
+		if dn.itab == scope && strings.EqualFold(dn.name, name) {
+			return dn, nil
+		}
+	}
+	return definedName{}, fmt.Errorf("workbook: name %q not found", name)
+}
+
+// sheetIndex returns the 0-based index of the named sheet (case-insensitive),
+// or -1 when there is no such sheet.
+func (wb *Workbook) sheetIndex(name string) int {
+	for i, s := range wb.sheets {
+		if strings.EqualFold(s.name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// nameAreas converts the formula of a range name into sheet areas.
+func (wb *Workbook) nameAreas(dn definedName) ([]Area, error) {
+	refs, ok := formula.SheetAreas(dn.rgce)
+	if !ok {
+		return nil, fmt.Errorf("workbook: name %q is not a simple range reference", dn.name)
+	}
+	ctx := formulaContext{wb}
+	areas := make([]Area, 0, len(refs))
+	for _, ref := range refs {
+		x, ok := ctx.Extern(ref.Ixti)
+		if !ok || x.Book != 0 || x.First == "" || x.First != x.Last {
+			return nil, fmt.Errorf("workbook: name %q does not refer to a single sheet of this workbook", dn.name)
+		}
+		areas = append(areas, Area{Sheet: x.First, R1: ref.R1, C1: ref.C1, R2: ref.R2, C2: ref.C2})
+	}
+	return areas, nil
+}
+
 // Close releases the underlying ZIP file handle.
 // It is a no-op when the workbook was opened via OpenReader (no file handle to
 // release), and always returns nil in that case.
@@ -185,6 +526,9 @@ func (wb *Workbook) Close() error {
 
 // parse reads workbook.bin, sharedStrings.bin (if present), and styles.bin.
 func (wb *Workbook) parse() error {
+	if _, ok := wb.zipEntry("xl/workbook.bin"); !ok {
+		return notXLSB(detectZip(wb.zf))
+	}
 	if err := wb.parseWorkbook(); err != nil {
 		return err
 	}
@@ -194,6 +538,7 @@ func (wb *Workbook) parse() error {
 	if err := wb.parseStyles(); err != nil {
 		return err
 	}
+	wb.parseTheme()
 	return nil
 }
 
@@ -231,17 +576,90 @@ func (wb *Workbook) parseWorkbook() error {
 				flags := binary.LittleEndian.Uint32(recData[:4])
 				wb.Date1904 = (flags & 0x08) != 0
 			}
+		case biff12.BookProtection, biff12.BookProtectionIso:
+			if p, err := parseBookProtectionRecord(recData, recID == biff12.BookProtectionIso); err == nil {
+				wb.Protection = &p
+			}
 		case biff12.Sheet:
 			entry, err := parseSheetRecord(recData, rels)
 			if err != nil {
 				return fmt.Errorf("workbook: parse SHEET record: %w", err)
 			}
 			wb.sheets = append(wb.sheets, entry)
+		case biff12.ExternalSelf, biff12.ExternalSame:
+			wb.supBooks = append(wb.supBooks, supBook{self: true})
+		case biff12.ExternalAddin:
+			wb.supBooks = append(wb.supBooks, supBook{})
+		case biff12.ExternalReference:
+			wb.supBooks = append(wb.supBooks, wb.parseExternalLink(recData, rels))
+		case biff12.ExternSheet:
+			// Only formula rendering depends on the XTI table, so a corrupt
+			// record degrades 3-D references to #REF! instead of failing Open.
+			if xtis, err := parseExternSheetRecord(recData); err == nil {
+				wb.xtis = xtis
+			}
+		case biff12.DefinedName:
+			// A malformed name still occupies its index slot so that later
+			// PtgName references resolve to the right entry.
+			dn, _ := parseNameRecord(recData)
+			wb.names = append(wb.names, dn)
 		}
 	}
 	return nil
 }
 
+// parseExternalLink decodes a BrtSupBookSrc record (strRelID as an
+// XLNullableWideString) and loads the sheet names of the external workbook
+// from its external-link part, when the part is present.  Failures are not
+// fatal: the link keeps its index so that formula references still render
+// with the right "[n]" prefix.
+func (wb *Workbook) parseExternalLink(data []byte, rels map[string]string) supBook {
+	book := 1
+	for _, sb := range wb.supBooks {
+		if sb.book > 0 {
+			book++
+		}
+	}
+	sb := supBook{book: book}
+
+	relID, err := record.NewRecordReader(data).ReadString()
+	if err != nil {
+		return sb
+	}
+	target, ok := rels[relID]
+	if !ok {
+		return sb
+	}
+	part, err := wb.readZipEntry(zipPath(target))
+	if err != nil {
+		return sb
+	}
+	rdr := record.NewReader(bytes.NewReader(part))
+	for {
+		recID, recData, err := rdr.Next()
+		if err != nil {
+			return sb
+		}
+		if recID != biff12.ExternalSheetNames {
+			continue
+		}
+		// BrtSupTabs: cTab uint32 followed by cTab XLWideStrings.
+		rr := record.NewRecordReader(recData)
+		n, err := rr.ReadUint32()
+		if err != nil {
+			return sb
+		}
+		for range n {
+			name, err := rr.ReadString()
+			if err != nil {
+				break
+			}
+			sb.sheets = append(sb.sheets, name)
+		}
+		return sb
+	}
+}
+
 // parseSharedStrings reads xl/sharedStrings.bin if it exists.
 func (wb *Workbook) parseSharedStrings() error {
 	data, err := wb.readZipEntry("xl/sharedStrings.bin")
@@ -267,34 +685,79 @@ func (wb *Workbook) parseStyles() error {
 	if err != nil {
 		return nil // optional — absent styles.bin is not an error
 	}
-	st, err := parseStyleTable(data)
+	ss, err := parseStyleTable(data)
 	if err != nil {
 		return fmt.Errorf("workbook: styles: %w", err)
 	}
-	wb.Styles = st
+	wb.Styles = ss.xfs
+	wb.Fonts = ss.fonts
+	wb.Fills = ss.fills
+	wb.Borders = ss.borders
+	wb.Palette = ss.palette
 	return nil
 }
 
+// parseTheme reads xl/theme/theme1.xml if it exists.  The theme only affects
+// colour and font resolution, so a corrupt part leaves wb.Theme nil rather
+// than failing Open.
+func (wb *Workbook) parseTheme() {
+	data, err := wb.readZipEntry("xl/theme/theme1.xml")
+	if err != nil {
+		return
+	}
+	if th, err := parseTheme(data); err == nil {
+		wb.Theme = th
+	}
+}
+
+// styleSheet holds the tables parsed from xl/styles.bin.
+type styleSheet struct {
+	xfs     styles.StyleTable
+	fonts   []styles.Font
+	fills   []styles.Fill
+	borders []styles.Border
+	palette []uint32
+}
+
 // parseStyleTable parses the BIFF12 styles stream and returns a StyleTable
-// mapping each XF index to its resolved XFStyle.
+// mapping each XF index to its resolved XFStyle, together with the font, fill
+// and border tables and the custom colour palette.
 //
 // BrtFmt record layout (MS-XLSB §2.4.697):
 //
 //	numFmtId  uint16
 //	stFmtCode ReadString (4-byte char-count + UTF-16LE)
 //
-// BrtXF record layout (MS-XLSB §2.4.674) — we only read the first two fields:
+// BrtXF record layout (MS-XLSB §2.4.674) — fields not listed are ignored:
 //
 //	ixfe      uint16   (parent XF index; ignored)
 //	numFmtId  uint16
-//	...       (remaining fields ignored)
-func parseStyleTable(data []byte) (styles.StyleTable, error) {
+//	iFont     uint16   (index into the font table)
+//	iFill     uint16   (index into the fill table)
+//	ixBorder  uint16   (index into the border table)
+//	trot      uint8    (text rotation)
+//	indent    uint8
+//	flags     uint16   (bits 0–2: alc, bits 3–5: alcv, bit 6: fWrap,
+//	                    bit 7: fJustLast, bit 8: fShrinkToFit,
+//	                    bits 10–11: iReadingOrder, bit 12: fLocked,
+//	                    bit 13: fHidden, bit 14: fSxButton, bit 15: f123Prefix)
+//	xfGrbitAtr uint8   (bits 0–5: apply number format, font, alignment,
+//	                    border, fill, protection)
+func parseStyleTable(data []byte) (styleSheet, error) {
 	// fmts maps numFmtId → format string for custom formats (id >= 164).
 	fmts := make(map[int]string)
 	var table styles.StyleTable
+	var fonts []styles.Font
+	var fills []styles.Fill
+	var borders []styles.Border
+	var palette []uint32
 
 	rdr := record.NewReader(bytes.NewReader(data))
 	inCellXfs := false
+	inFonts := false
+	inFills := false
+	inBorders := false
+	inColors := false
 
 	for {
 		recID, recData, err := rdr.Next()
@@ -302,10 +765,65 @@ func parseStyleTable(data []byte) (styles.StyleTable, error) {
 			break
 		}
 		if err != nil {
-			return nil, fmt.Errorf("workbook: styles: %w", err)
+			return styleSheet{}, fmt.Errorf("workbook: styles: %w", err)
 		}
 
 		switch recID {
+		case biff12.Fonts:
+			inFonts = true
+
+		case biff12.FontsEnd:
+			inFonts = false
+
+		case biff12.Font:
+			if !inFonts {
+				continue
+			}
+			// Keep the slot even for a malformed record so that later font
+			// indices stay aligned.
+			f, _ := parseFontRecord(recData)
+			fonts = append(fonts, f)
+
+		case biff12.Fills:
+			inFills = true
+
+		case biff12.FillsEnd:
+			inFills = false
+
+		case biff12.Fill:
+			if !inFills {
+				continue
+			}
+			f, _ := parseFillRecord(recData)
+			fills = append(fills, f)
+
+		case biff12.Borders:
+			inBorders = true
+
+		case biff12.BordersEnd:
+			inBorders = false
+
+		case biff12.Border:
+			if !inBorders {
+				continue
+			}
+			b, _ := parseBorderRecord(recData)
+			borders = append(borders, b)
+
+		case biff12.Colors:
+			inColors = true
+
+		case biff12.ColorsEnd:
+			inColors = false
+
+		case biff12.Color:
+			// BrtIndexedColor inside the colour palette: bRed, bGreen, bBlue,
+			// reserved.  Records of the MRU colour list use other IDs.
+			if !inColors || len(recData) < 3 {
+				continue
+			}
+			palette = append(palette, 0xFF000000|uint32(recData[0])<<16|uint32(recData[1])<<8|uint32(recData[2]))
+
 		case biff12.NumFmt:
 			// BrtFmt: numFmtId(uint16) + format string
 			if len(recData) < 2 {
@@ -334,13 +852,234 @@ func parseStyleTable(data []byte) (styles.StyleTable, error) {
 			// ixfe is at bytes 0–1; numFmtId is at bytes 2–3.
 			numFmtID := int(binary.LittleEndian.Uint16(recData[2:4]))
 			fmtStr := fmts[numFmtID] // empty string for built-in IDs
-			table = append(table, styles.XFStyle{
+			xf := styles.XFStyle{
 				NumFmtID:  numFmtID,
 				FormatStr: fmtStr,
-			})
+				Locked:    true,
+				Alignment: styles.Alignment{Vertical: styles.VAlignBottom},
+			}
+			if len(recData) >= 6 {
+				xf.FontID = int(binary.LittleEndian.Uint16(recData[4:6]))
+			}
+			if len(recData) >= 8 {
+				xf.FillID = int(binary.LittleEndian.Uint16(recData[6:8]))
+			}
+			if len(recData) >= 10 {
+				xf.BorderID = int(binary.LittleEndian.Uint16(recData[8:10]))
+			}
+			if len(recData) >= 12 {
+				xf.Alignment.Rotation = int(recData[10])
+				xf.Alignment.Indent = int(recData[11])
+			}
+			if len(recData) >= 14 {
+				flags := binary.LittleEndian.Uint16(recData[12:14])
+				xf.Alignment.Horizontal = styles.HAlign(flags & 0x07)
+				xf.Alignment.Vertical = styles.VAlign(flags >> 3 & 0x07)
+				xf.Alignment.WrapText = flags&0x0040 != 0
+				xf.Alignment.JustifyLastLine = flags&0x0080 != 0
+				xf.Alignment.ShrinkToFit = flags&0x0100 != 0
+				xf.Alignment.ReadingOrder = styles.ReadingOrder(flags >> 10 & 0x03)
+				xf.Locked = flags&0x1000 != 0
+				xf.Hidden = flags&0x2000 != 0
+				xf.PivotButton = flags&0x4000 != 0
+				xf.QuotePrefix = flags&0x8000 != 0
+			}
+			if len(recData) >= 15 {
+				atr := recData[14]
+				xf.ApplyNumberFormat = atr&0x01 != 0
+				xf.ApplyFont = atr&0x02 != 0
+				xf.ApplyAlignment = atr&0x04 != 0
+				xf.ApplyBorder = atr&0x08 != 0
+				xf.ApplyFill = atr&0x10 != 0
+				xf.ApplyProtection = atr&0x20 != 0
+			}
+			table = append(table, xf)
+		}
+	}
+	// Resolve fonts, fills and borders once all tables are complete; Excel writes them
+	// before the XFs, but nothing requires it.
+	for i := range table {
+		if id := table[i].FontID; id < len(fonts) {
+			table[i].Font = &fonts[id]
+		}
+		if id := table[i].FillID; id < len(fills) {
+			table[i].Fill = &fills[id]
+		}
+		if id := table[i].BorderID; id < len(borders) {
+			table[i].Border = &borders[id]
+		}
+	}
+	return styleSheet{xfs: table, fonts: fonts, fills: fills, borders: borders, palette: palette}, nil
+}
+
+// parseFontRecord decodes a BrtFont record.
+//
+// BrtFont record layout (MS-XLSB §2.4.137):
+//
+//	dyHeight    uint16  (font height in twentieths of a point)
+//	grbit       uint16  (bit 1: fItalic, bit 3: fStrikeout, bit 4: fOutline,
+//	                     bit 5: fShadow)
+//	bls         uint16  (weight: 400 normal, 700 bold)
+//	sss         uint16  (0 none, 1 superscript, 2 subscript)
+//	uls         uint8   (underline style)
+//	bFamily     uint8
+//	bCharSet    uint8
+//	unused      uint8
+//	brtColor    Color   (8 bytes)
+//	bFontScheme uint8
+//	name        XLWideString
+func parseFontRecord(data []byte) (styles.Font, error) {
+	rr := record.NewRecordReader(data)
+	var f styles.Font
+	height, err := rr.ReadUint16()
+	if err != nil {
+		return f, err
+	}
+	f.Size = float64(height) / 20
+	grbit, err := rr.ReadUint16()
+	if err != nil {
+		return f, err
+	}
+	f.Italic = grbit&0x0002 != 0
+	f.Strike = grbit&0x0008 != 0
+	f.Outline = grbit&0x0010 != 0
+	f.Shadow = grbit&0x0020 != 0
+	bls, err := rr.ReadUint16()
+	if err != nil {
+		return f, err
+	}
+	f.Weight = int(bls)
+	f.Bold = bls >= 700
+	sss, err := rr.ReadUint16()
+	if err != nil {
+		return f, err
+	}
+	f.VertAlign = styles.VertAlign(sss)
+	var b [4]byte // uls, bFamily, bCharSet, unused
+	if err := rr.Read(b[:]); err != nil {
+		return f, err
+	}
+	f.Underline = styles.Underline(b[0])
+	f.Family = styles.FontFamily(b[1])
+	f.Charset = int(b[2])
+	if f.Color, err = styles.ReadColor(rr); err != nil {
+		return f, err
+	}
+	scheme, err := rr.ReadUint8()
+	if err != nil {
+		return f, err
+	}
+	f.Scheme = styles.FontScheme(scheme)
+	f.Name, err = rr.ReadString()
+	return f, err
+}
+
+// parseFillRecord decodes a BrtFill record.
+//
+// BrtFill record layout (MS-XLSB §2.4.649):
+//
+//	fls              uint32  (pattern; 0x28 for a gradient)
+//	brtColorFore     Color   (8 bytes)
+//	brtColorBack     Color   (8 bytes)
+//	iGradientType    uint32  (0 linear, 1 path)
+//	xnumDegree       float64
+//	xnumFillToLeft   float64
+//	xnumFillToRight  float64
+//	xnumFillToTop    float64
+//	xnumFillToBottom float64
+//	cNumStop         uint32
+//	xfillGradientStop[cNumStop]: Color (8 bytes) + xnumPosition float64
+//
+// The gradient fields are present for every fill but only meaningful for
+// gradients; a record truncated after the colours is accepted.
+func parseFillRecord(data []byte) (styles.Fill, error) {
+	rr := record.NewRecordReader(data)
+	var f styles.Fill
+	fls, err := rr.ReadUint32()
+	if err != nil {
+		return f, err
+	}
+	f.Pattern = styles.FillPattern(fls)
+	if f.FgColor, err = styles.ReadColor(rr); err != nil {
+		return f, err
+	}
+	if f.BgColor, err = styles.ReadColor(rr); err != nil {
+		return f, err
+	}
+	if f.Pattern != styles.FillGradient {
+		return f, nil
+	}
+	g := &styles.Gradient{}
+	typ, err := rr.ReadUint32()
+	if err != nil {
+		return f, err
+	}
+	g.Type = styles.GradientType(typ)
+	for _, p := range []*float64{&g.Degree, &g.Left, &g.Right, &g.Top, &g.Bottom} {
+		if *p, err = rr.ReadDouble(); err != nil {
+			return f, err
 		}
 	}
-	return table, nil
+	n, err := rr.ReadUint32()
+	if err != nil {
+		return f, err
+	}
+	// Each stop is 16 bytes; reject counts the payload cannot hold.
+	if int64(n)*16 > int64(rr.Len()) {
+		return f, fmt.Errorf("fill: %d gradient stops declared in %d bytes", n, rr.Len())
+	}
+	g.Stops = make([]styles.GradientStop, n)
+	for i := range g.Stops {
+		if g.Stops[i].Color, err = styles.ReadColor(rr); err != nil {
+			return f, err
+		}
+		if g.Stops[i].Position, err = rr.ReadDouble(); err != nil {
+			return f, err
+		}
+	}
+	f.Gradient = g
+	return f, nil
+}
+
+// parseBorderRecord decodes a BrtBorder record.
+//
+// BrtBorder record layout (MS-XLSB §2.4.43):
+//
+//	flags       uint8  (bit 0: fBdrDiagDown, bit 1: fBdrDiagUp)
+//	blxfTop     Blxf
+//	blxfBottom  Blxf
+//	blxfLeft    Blxf
+//	blxfRight   Blxf
+//	blxfDiag    Blxf
+//
+// Blxf layout:
+//
+//	dg          uint8  (line style)
+//	reserved    uint8
+//	brtColor    Color  (8 bytes)
+func parseBorderRecord(data []byte) (styles.Border, error) {
+	rr := record.NewRecordReader(data)
+	var b styles.Border
+	flags, err := rr.ReadUint8()
+	if err != nil {
+		return b, err
+	}
+	b.DiagonalDown = flags&0x01 != 0
+	b.DiagonalUp = flags&0x02 != 0
+	for _, e := range []*styles.BorderEdge{&b.Top, &b.Bottom, &b.Left, &b.Right, &b.Diagonal} {
+		dg, err := rr.ReadUint8()
+		if err != nil {
+			return b, err
+		}
+		e.Style = styles.BorderStyle(dg)
+		if err := rr.Skip(1); err != nil {
+			return b, err
+		}
+		if e.Color, err = styles.ReadColor(rr); err != nil {
+			return b, err
+		}
+	}
+	return b, nil
 }
 
 // isDateFormatID is the internal counterpart of xlsb.IsDateFormat.
@@ -361,23 +1100,29 @@ func isDateFormatID(id int, formatStr string) bool {
 	return dateformat.ScanFormatStr(formatStr)
 }
 
-// openSheet reads the binary data for the given sheet entry and returns a
-// ready-to-use Worksheet.
-func (wb *Workbook) openSheet(entry sheetEntry) (*worksheet.Worksheet, error) {
-	// Resolve "worksheets/sheet1.bin" → "xl/worksheets/sheet1.bin".
+// zipPath resolves a workbook relationship target such as
+// "worksheets/sheet1.bin" to its path inside the ZIP archive
+// ("xl/worksheets/sheet1.bin").
+func zipPath(target string) string {
 	// Absolute targets (starting with "/") are used as-is after stripping the
 	// leading slash; relative targets are prefixed with "xl/".
-	target := strings.TrimPrefix(entry.target, "/")
-	var zipPath string
+	target = strings.TrimPrefix(target, "/")
+	var p string
 	if strings.HasPrefix(target, "xl/") {
-		zipPath = target
+		p = target
 	} else {
-		zipPath = "xl/" + target
+		p = "xl/" + target
 	}
 	// Normalise any ".." segments that appear in relative targets such as
 	// "../xl/worksheets/sheet1.bin" so the resulting path matches the ZIP
 	// index (which stores entries without redundant path components).
-	zipPath = path.Clean(zipPath)
+	return path.Clean(p)
+}
+
+// openSheet reads the binary data for the given sheet entry and returns a
+// ready-to-use Worksheet.
+func (wb *Workbook) openSheet(entry sheetEntry) (*worksheet.Worksheet, error) {
+	zipPath := zipPath(entry.target)
 
 	data, err := wb.readZipEntry(zipPath)
 	if err != nil {
@@ -389,13 +1134,157 @@ func (wb *Workbook) openSheet(entry sheetEntry) (*worksheet.Worksheet, error) {
 	relsPath := zipPath[:lastSlash+1] + "_rels/" + zipPath[lastSlash+1:] + ".rels"
 	relsData, _ := wb.readZipEntry(relsPath) // ignore error — it's optional
 
-	return worksheet.New(entry.name, data, relsData, wb.stringTable, wb.Styles, wb.FormatCell)
+	area, titles := wb.printNames(entry.name)
+	return worksheet.New(entry.name, data, relsData, wb.stringTable, wb.Styles, wb.FormatCell,
+		worksheet.WithFormulaContext(formulaContext{wb}),
+		worksheet.WithPrintNames(area, titles))
+}
+
+// printNames resolves the print area and print titles of a sheet from its
+// sheet-scoped built-in names.  Names that are not plain references to the
+// sheet itself are ignored.
+func (wb *Workbook) printNames(sheet string) ([]cellref.Range, worksheet.PrintTitles) {
+	var (
+		area   []cellref.Range
+		titles worksheet.PrintTitles
+	)
+	idx := wb.sheetIndex(sheet)
+	for _, dn := range wb.names {
+		if dn.itab != idx || idx < 0 {
+			continue
+		}
+		name := dn.name
+		if len(name) > 6 && strings.EqualFold(name[:6], "_xlnm.") {
+			name = name[6:]
+		}
+		isArea := strings.EqualFold(name, "Print_Area")
+		if !isArea && !strings.EqualFold(name, "Print_Titles") {
+			continue
+		}
+		areas, err := wb.nameAreas(dn)
+		if err != nil {
+			continue
+		}
+		for _, a := range areas {
+			if !strings.EqualFold(a.Sheet, sheet) {
+				continue
+			}
+			rng := cellref.Range{
+				Sheet: sheet,
+				First: cellref.Ref{Row: a.R1, Col: a.C1, RowAbs: true, ColAbs: true},
+				Last:  cellref.Ref{Row: a.R2, Col: a.C2, RowAbs: true, ColAbs: true},
+			}
+			switch {
+			case isArea:
+				area = append(area, rng)
+			case rng.WholeRows():
+				titles.Rows = &rng
+			case rng.WholeColumns():
+				titles.Cols = &rng
+			}
+		}
+	}
+	return area, titles
+}
+
+// sheetCodeName reads the code name of a sheet.  SHEETPR is one of the first
+// records of a sheet part, so only a short prefix is decompressed unless the
+// record lies further in.
+func (wb *Workbook) sheetCodeName(entry sheetEntry) (string, error) {
+	zipPath := zipPath(entry.target)
+	f, ok := wb.zipEntry(zipPath)
+	if !ok {
+		return "", fmt.Errorf("workbook: sheet %q: %q not found in archive", entry.name, zipPath)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return "", fmt.Errorf("workbook: sheet %q: %w", entry.name, err)
+	}
+	prefix := make([]byte, 4096)
+	n, err := io.ReadFull(rc, prefix)
+	_ = rc.Close()
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", fmt.Errorf("workbook: sheet %q: %w", entry.name, err)
+	}
+	if name, done := scanCodeName(prefix[:n]); done || n < len(prefix) {
+		return name, nil
+	}
+	data, err := wb.readZipEntry(zipPath)
+	if err != nil {
+		return "", fmt.Errorf("workbook: sheet %q: %w", entry.name, err)
+	}
+	name, _ := scanCodeName(data)
+	return name, nil
+}
+
+// scanCodeName returns the code name from the SHEETPR record of a sheet
+// stream, decoded by sheetpr.Parse as the worksheet does.  done is false when
+// data ends before either SHEETPR or the start of the cell data is reached.
+func scanCodeName(data []byte) (name string, done bool) {
+	rdr := record.NewReader(bytes.NewReader(data))
+	for {
+		recID, recData, err := rdr.Next()
+		if err != nil {
+			return "", false
+		}
+		switch recID {
+		case biff12.SheetPr:
+			rec, err := sheetpr.Parse(recData)
+			if err != nil {
+				return "", true
+			}
+			return rec.CodeName, true
+		case biff12.SheetData:
+			return "", true
+		}
+	}
+}
+
+// ── formula context ──────────────────────────────────────────────────────────
+
+// formulaContext adapts the workbook's supporting-link, XTI and defined-name
+// tables to the formula.Context interface used when decompiling formulas.
+type formulaContext struct {
+	wb *Workbook
+}
+
+// Extern implements formula.Context.
+func (fc formulaContext) Extern(ixti int) (formula.Extern, bool) {
+	wb := fc.wb
+	if ixti < 0 || ixti >= len(wb.xtis) {
+		return formula.Extern{}, false
+	}
+	x := wb.xtis[ixti]
+	if x.supBook < 0 || x.supBook >= len(wb.supBooks) {
+		return formula.Extern{}, false
+	}
+	sb := wb.supBooks[x.supBook]
+	if x.first == -2 {
+		// Workbook-level reference (e.g. a name scoped to the whole book).
+		return formula.Extern{Book: sb.book}, true
+	}
+	names := sb.sheets
+	if sb.self {
+		names = wb.Sheets()
+	}
+	if x.first < 0 || x.first >= len(names) || x.last < x.first || x.last >= len(names) {
+		return formula.Extern{}, false
+	}
+	return formula.Extern{Book: sb.book, First: names[x.first], Last: names[x.last]}, true
+}
+
+// Name implements formula.Context.
+func (fc formulaContext) Name(idx int) (string, bool) {
+	if idx < 1 || idx > len(fc.wb.names) || fc.wb.names[idx-1].name == "" {
+		return "", false
+	}
+	return fc.wb.names[idx-1].name, true
 }
 
 // readZipEntry reads the full contents of a named entry from the ZIP archive.
 // It uses the pre-built zipIndex for O(1) lookup instead of a linear scan.
 func (wb *Workbook) readZipEntry(name string) ([]byte, error) {
-	f, ok := wb.zipIndex[name]
+	f, ok := wb.zipEntry(name)
 	if !ok {
 		return nil, fmt.Errorf("%q not found in archive", name)
 	}
@@ -419,13 +1308,21 @@ func (wb *Workbook) readZipEntry(name string) ([]byte, error) {
 // buildZipIndex constructs wb.zipIndex from wb.zf.File, enabling O(1) lookups
 // in readZipEntry.  When duplicate names exist, the last entry wins; this
 // matches ZIP tools that append updated entries at the end of the archive.
+// Names are lower-cased because OPC part names are case-insensitive; Detect
+// matches xl/workbook.bin the same way.
 func (wb *Workbook) buildZipIndex() {
 	wb.zipIndex = make(map[string]*zip.File, len(wb.zf.File))
 	for _, f := range wb.zf.File {
-		wb.zipIndex[f.Name] = f
+		wb.zipIndex[strings.ToLower(f.Name)] = f
 	}
 }
 
+// zipEntry looks up a part by name, ignoring ASCII case.
+func (wb *Workbook) zipEntry(name string) (*zip.File, bool) {
+	f, ok := wb.zipIndex[strings.ToLower(name)]
+	return f, ok
+}
+
 // readRels parses a .rels XML file and returns a map of Id → Target.
 func (wb *Workbook) readRels(name string) (map[string]string, error) {
 	data, err := wb.readZipEntry(name)
@@ -472,3 +1369,74 @@ func parseSheetRecord(data []byte, rels map[string]string) (sheetEntry, error) {
 	}
 	return sheetEntry{name: name, target: target, visibility: visibility}, nil
 }
+
+// ── EXTERNSHEET / NAME record parsing ─────────────────────────────────────────
+
+// parseExternSheetRecord decodes a BrtExternSheet record.
+//
+//	cXti  uint32
+//	rgXti [cXti] of:
+//	    iSupBook  uint32  — index into the supporting-link list
+//	    itabFirst int32   — first sheet index (-2 workbook-level, -1 deleted)
+//	    itabLast  int32   — last sheet index
+func parseExternSheetRecord(data []byte) ([]xti, error) {
+	rr := record.NewRecordReader(data)
+	n, err := rr.ReadUint32()
+	if err != nil {
+		return nil, fmt.Errorf("read cXti: %w", err)
+	}
+	// Each XTI is 12 bytes; reject counts the payload cannot hold before
+	// allocating.
+	if uint64(n)*12 > uint64(rr.Len()) {
+		return nil, fmt.Errorf("cXti %d exceeds record length", n)
+	}
+	xtis := make([]xti, 0, n)
+	for range n {
+		sb, _ := rr.ReadUint32()
+		first, _ := rr.ReadInt32()
+		last, _ := rr.ReadInt32()
+		xtis = append(xtis, xti{supBook: int(sb), first: int(first), last: int(last)})
+	}
+	return xtis, nil
+}
+
+// parseNameRecord decodes a BrtName record (MS-XLSB §2.4.647).
+//
+//	flags    uint32  (bit 0: fHidden, bit 5: fBuiltin)
+//	chKey    uint8   (keyboard shortcut; ignored)
+//	itab     uint32  (0xFFFFFFFF: workbook scope, else sheet index)
+//	name     XLWideString
+//	formula  NameParsedFormula (see formula.Read)
+//	comment  XLNullableWideString
+//	…        (macro-only fields; ignored)
+//
+// Fields decoded before an error are kept, so a name whose formula is
+// truncated still occupies its slot with the right text.
+func parseNameRecord(data []byte) (definedName, error) {
+	rr := record.NewRecordReader(data)
+	dn := definedName{itab: -1}
+	flags, err := rr.ReadUint32()
+	if err != nil {
+		return dn, err
+	}
+	dn.hidden = flags&0x01 != 0
+	dn.builtin = flags&0x20 != 0
+	if err := rr.Skip(1); err != nil {
+		return dn, err
+	}
+	itab, err := rr.ReadUint32()
+	if err != nil {
+		return dn, err
+	}
+	if itab != 0xFFFFFFFF && itab <= 0xFFFF {
+		dn.itab = int(itab)
+	}
+	if dn.name, err = rr.ReadString(); err != nil {
+		return dn, err
+	}
+	if dn.rgce, dn.extra, err = formula.Read(rr); err != nil {
+		return dn, err
+	}
+	dn.comment, err = rr.ReadNullableString()
+	return dn, err
+}
@@ -0,0 +1,97 @@
+package worksheet
+
+import "github.com/TsubasaBE/go-xlsb/biff12"
+
+// CellKind identifies the kind of cell record a Cell was decoded from.  It
+// tells apart values that look the same in Cell.V: a typed number from a
+// formula result, a blank record from a cell with no record at all, and a
+// shared string from an inline one.
+type CellKind int
+
+const (
+	// KindNone is a position with no cell record, e.g. a padding cell of a
+	// dense row.
+	KindNone CellKind = iota
+	// KindBlank is a cell with a format but no value (BrtCellBlank).
+	KindBlank
+	// KindRK is a number stored in the compact RK encoding (BrtCellRk).
+	KindRK
+	// KindNumber is a number stored as a full double (BrtCellReal).
+	KindNumber
+	// KindBool is a boolean constant (BrtCellBool).
+	KindBool
+	// KindError is an error constant (BrtCellError).
+	KindError
+	// KindSharedString is an index into the shared-string table (BrtCellIsst).
+	KindSharedString
+	// KindInlineString is a string stored in the cell record (BrtCellSt).
+	KindInlineString
+	// KindRichString is a rich string stored in the cell record
+	// (BrtCellRString).
+	KindRichString
+	// KindFormulaNumber is a formula with a numeric result (BrtFmlaNum).
+	KindFormulaNumber
+	// KindFormulaString is a formula with a string result (BrtFmlaString).
+	KindFormulaString
+	// KindFormulaBool is a formula with a boolean result (BrtFmlaBool).
+	KindFormulaBool
+	// KindFormulaError is a formula with an error result (BrtFmlaError).
+	KindFormulaError
+)
+
+// cellKinds maps cell record IDs to their CellKind.
+var cellKinds = map[int]CellKind{
+	biff12.Blank:          KindBlank,
+	biff12.Num:            KindRK,
+	biff12.Float:          KindNumber,
+	biff12.Bool:           KindBool,
+	biff12.BoolErr:        KindError,
+	biff12.String:         KindSharedString,
+	biff12.CellSt:         KindInlineString,
+	biff12.CellRString:    KindRichString,
+	biff12.FormulaFloat:   KindFormulaNumber,
+	biff12.FormulaString:  KindFormulaString,
+	biff12.FormulaBool:    KindFormulaBool,
+	biff12.FormulaBoolErr: KindFormulaError,
+}
+
+var cellKindNames = [...]string{
+	KindNone:          "none",
+	KindBlank:         "blank",
+	KindRK:            "rk",
+	KindNumber:        "number",
+	KindBool:          "bool",
+	KindError:         "error",
+	KindSharedString:  "shared-string",
+	KindInlineString:  "inline-string",
+	KindRichString:    "rich-string",
+	KindFormulaNumber: "formula-number",
+	KindFormulaString: "formula-string",
+	KindFormulaBool:   "formula-bool",
+	KindFormulaError:  "formula-error",
+}
+
+// String returns a short lower-case name for the kind, e.g. "formula-number".
+func (k CellKind) String() string {
+	if k >= 0 && int(k) < len(cellKindNames) {
+		return cellKindNames[k]
+	}
+	return "unknown"
+}
+
+// IsFormula reports whether the cell holds a formula; Cell.V is then its
+// cached result.
+func (k CellKind) IsFormula() bool {
+	return k >= KindFormulaNumber && k <= KindFormulaError
+}
+
+// IsNumber reports whether the cell holds a numeric constant (RK or double).
+func (k CellKind) IsNumber() bool {
+	return k == KindRK || k == KindNumber
+}
+
+// IsString reports whether the cell holds a string constant, shared or
+// inline.
+func (k CellKind) IsString() bool {
+	return k == KindSharedString || k == KindInlineString || k == KindRichString
+}
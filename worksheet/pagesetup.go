@@ -0,0 +1,588 @@
+package worksheet
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TsubasaBE/go-xlsb/cellref"
+	"github.com/TsubasaBE/go-xlsb/record"
+)
+
+// Orientation is the page orientation of a PageSetup.
+type Orientation int
+
+const (
+	// OrientationDefault leaves the orientation to the printer default
+	// (usually portrait).
+	OrientationDefault Orientation = 0
+	// OrientationPortrait prints pages upright.
+	OrientationPortrait Orientation = 1
+	// OrientationLandscape prints pages sideways.
+	OrientationLandscape Orientation = 2
+)
+
+// PrintComments says how cell comments are printed.
+type PrintComments int
+
+const (
+	// PrintCommentsNone does not print comments.
+	PrintCommentsNone PrintComments = 0
+	// PrintCommentsAtEnd prints comments on a separate page at the end.
+	PrintCommentsAtEnd PrintComments = 1
+	// PrintCommentsAsDisplayed prints comments where they appear on the sheet.
+	PrintCommentsAsDisplayed PrintComments = 2
+)
+
+// PrintErrors says how cells containing errors are printed.
+type PrintErrors int
+
+const (
+	// PrintErrorsDisplayed prints errors as shown ("#DIV/0!").
+	PrintErrorsDisplayed PrintErrors = 0
+	// PrintErrorsBlank prints error cells blank.
+	PrintErrorsBlank PrintErrors = 1
+	// PrintErrorsDash prints error cells as "--".
+	PrintErrorsDash PrintErrors = 2
+	// PrintErrorsNA prints error cells as "#N/A".
+	PrintErrorsNA PrintErrors = 3
+)
+
+// PageSetup holds the printing settings of a sheet (BrtPageSetup).
+type PageSetup struct {
+	// PaperSize is the Excel paper-size code, e.g. 1 (Letter) or 9 (A4).
+	PaperSize int
+	// Orientation is the page orientation.
+	Orientation Orientation
+	// Scale is the print scale in percent (10-400).  It applies when
+	// SheetProperties.FitToPage is false.
+	Scale int
+	// FitToWidth and FitToHeight are the number of pages the sheet is fitted
+	// to across and down when SheetProperties.FitToPage is true; 0 means
+	// as many as needed in that direction.
+	FitToWidth, FitToHeight int
+	// FirstPageNumber is the number of the first page; it applies only when
+	// UseFirstPageNumber is true (otherwise numbering is automatic).
+	FirstPageNumber int
+	// UseFirstPageNumber is true when FirstPageNumber is set.
+	UseFirstPageNumber bool
+	// Copies is the number of copies to print.
+	Copies int
+	// HorizontalDPI and VerticalDPI are the print resolution.
+	HorizontalDPI, VerticalDPI int
+	// OverThenDown is true when pages are numbered across before down.
+	OverThenDown bool
+	// BlackAndWhite is true when the sheet is printed without colour.
+	BlackAndWhite bool
+	// Draft is true for draft-quality printing.
+	Draft bool
+	// Comments says how cell comments are printed.
+	Comments PrintComments
+	// Errors says how error values are printed.
+	Errors PrintErrors
+	// PrinterSettingsRID is the relationship ID of the printer settings
+	// part, or "" when there is none.
+	PrinterSettingsRID string
+}
+
+// PageMargins holds the print margins of a sheet in inches (BrtMargins).
+type PageMargins struct {
+	Left, Right, Top, Bottom float64
+	// Header and Footer are the distances from the page edge to the header
+	// and footer.
+	Header, Footer float64
+}
+
+// PrintOptions holds the print options of a sheet (BrtPrintOptions).
+type PrintOptions struct {
+	// HorizontalCentered and VerticalCentered center the printed area on
+	// the page.
+	HorizontalCentered, VerticalCentered bool
+	// Headings is true when row and column headings are printed.
+	Headings bool
+	// GridLines is true when gridlines are printed.
+	GridLines bool
+}
+
+// HeaderFooter holds the page headers and footers of a sheet
+// (BrtBeginHeaderFooter).  The strings are stored in Excel's raw format
+// with "&" codes; use ParseHeaderFooter to split them into sections.
+type HeaderFooter struct {
+	// DifferentOddEven is true when even pages use EvenHeader/EvenFooter.
+	DifferentOddEven bool
+	// DifferentFirst is true when the first page uses FirstHeader and
+	// FirstFooter.
+	DifferentFirst bool
+	// ScaleWithDoc is true when headers and footers scale with the sheet.
+	ScaleWithDoc bool
+	// AlignWithMargins is true when headers and footers align with the page
+	// margins.
+	AlignWithMargins bool
+	// OddHeader and OddFooter apply to all pages, or to odd pages when
+	// DifferentOddEven is set.
+	OddHeader, OddFooter string
+	// EvenHeader and EvenFooter apply to even pages.
+	EvenHeader, EvenFooter string
+	// FirstHeader and FirstFooter apply to the first page.
+	FirstHeader, FirstFooter string
+}
+
+// PrintTitles are the rows and columns repeated on every printed page
+// (the built-in name _xlnm.Print_Titles).
+type PrintTitles struct {
+	// Rows is the block of whole rows repeated at the top of each page
+	// ("$1:$2"), or nil.
+	Rows *cellref.Range
+	// Cols is the block of whole columns repeated at the left of each page
+	// ("$A:$B"), or nil.
+	Cols *cellref.Range
+}
+
+// PageBreak is a manual or automatic page break (BrtBrk).
+type PageBreak struct {
+	// At is the 0-based index of the row (column) the break precedes: a row
+	// break falls above row At, a column break left of column At.
+	At int
+	// Min and Max are the 0-based first and last column (row) the break
+	// spans.
+	Min, Max int
+	// Manual is true for a break inserted by the user.
+	Manual bool
+	// Pivot is true for a break created by a PivotTable.
+	Pivot bool
+}
+
+// PageBreaks lists the page breaks of a sheet.
+type PageBreaks struct {
+	// Rows are the horizontal breaks, between rows.
+	Rows []PageBreak
+	// Cols are the vertical breaks, between columns.
+	Cols []PageBreak
+}
+
+// WithPrintNames sets the print area and print titles of the sheet, which
+// are stored in the workbook as the built-in names _xlnm.Print_Area and
+// _xlnm.Print_Titles.  The workbook supplies them when opening a sheet.
+func WithPrintNames(area []cellref.Range, titles PrintTitles) Option {
+	return func(ws *Worksheet) {
+		ws.printArea = area
+		ws.printTitles = titles
+	}
+}
+
+// PrintArea returns the ranges printed for the sheet, or nil when the whole
+// used range is printed.  A print area may consist of several ranges, each
+// printed on separate pages.
+func (ws *Worksheet) PrintArea() []cellref.Range {
+	return ws.printArea
+}
+
+// PrintTitles returns the rows and columns repeated on every printed page.
+func (ws *Worksheet) PrintTitles() PrintTitles {
+	return ws.printTitles
+}
+
+// PageBreaks returns the manual and automatic page breaks of the sheet in
+// file order.
+func (ws *Worksheet) PageBreaks() PageBreaks {
+	return ws.breaks
+}
+
+// HFField identifies what a header/footer part contains.
+type HFField int
+
+const (
+	// HFText is literal text.
+	HFText HFField = iota
+	// HFPageNumber is the page number (&P).
+	HFPageNumber
+	// HFPageCount is the total number of pages (&N).
+	HFPageCount
+	// HFDate is the current date (&D).
+	HFDate
+	// HFTime is the current time (&T).
+	HFTime
+	// HFSheetName is the sheet name (&A).
+	HFSheetName
+	// HFFileName is the workbook file name (&F).
+	HFFileName
+	// HFFilePath is the workbook file path (&Z).
+	HFFilePath
+	// HFPicture is a picture placeholder (&G).
+	HFPicture
+)
+
+// HFFont is the text formatting in effect for a header/footer part.  Zero
+// values mean the default header/footer font.
+type HFFont struct {
+	// Name and Style are set by &"name,style" (e.g. "Arial", "Bold").  A
+	// name of "-" keeps the current font.
+	Name, Style string
+	// Size is the font size in points set by &nn; 0 means the default.
+	Size float64
+	// Color is the colour set by &K: six hex digits (RRGGBB) or a theme
+	// colour with tint ("04+025").
+	Color string
+	// Bold (&B), Italic (&I), Underline (&U), DoubleUnderline (&E),
+	// Strike (&S), Superscript (&X) and Subscript (&Y) are toggles.
+	Bold, Italic, Underline, DoubleUnderline, Strike, Superscript, Subscript bool
+}
+
+// HFPart is one run of a header/footer section: literal text or a field.
+type HFPart struct {
+	// Field is the kind of part.
+	Field HFField
+	// Text is the literal text of an HFText part.
+	Text string
+	// Offset is added to the page number of an HFPageNumber part ("&P+1").
+	Offset int
+	// Font is the formatting in effect for the part.
+	Font HFFont
+}
+
+// HFSections is a header or footer split into its left (&L), center (&C)
+// and right (&R) sections.
+type HFSections struct {
+	Left, Center, Right []HFPart
+}
+
+// ParseHeaderFooter splits a raw header or footer string into sections
+// and parts.  Text before the first section code belongs to the center
+// section, as in Excel.  Formatting resets at each section code.  "&&" is a
+// literal ampersand; unknown codes are kept as literal text.
+func ParseHeaderFooter(s string) HFSections {
+	var out HFSections
+	section := &out.Center
+	var font HFFont
+	var text strings.Builder
+
+	flush := func() {
+		if text.Len() == 0 {
+			return
+		}
+		*section = append(*section, HFPart{Field: HFText, Text: text.String(), Font: font})
+		text.Reset()
+	}
+	field := func(f HFField) {
+		flush()
+		*section = append(*section, HFPart{Field: f, Font: font})
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '&' || i+1 >= len(s) {
+			text.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch c := s[i]; c {
+		case '&':
+			text.WriteByte('&')
+		case 'L', 'C', 'R':
+			flush()
+			switch c {
+			case 'L':
+				section = &out.Left
+			case 'C':
+				section = &out.Center
+			default:
+				section = &out.Right
+			}
+			font = HFFont{}
+		case 'P':
+			field(HFPageNumber)
+			// "&P+1" / "&P-1" offset the page number.
+			if j := i + 1; j+1 < len(s) && (s[j] == '+' || s[j] == '-') && isDigit(s[j+1]) {
+				k := j + 1
+				for k < len(s) && isDigit(s[k]) {
+					k++
+				}
+				n, _ := strconv.Atoi(s[j+1 : k])
+				if s[j] == '-' {
+					n = -n
+				}
+				(*section)[len(*section)-1].Offset = n
+				i = k - 1
+			}
+		case 'N':
+			field(HFPageCount)
+		case 'D':
+			field(HFDate)
+		case 'T':
+			field(HFTime)
+		case 'A':
+			field(HFSheetName)
+		case 'F':
+			field(HFFileName)
+		case 'Z':
+			field(HFFilePath)
+		case 'G':
+			field(HFPicture)
+		case 'B', 'I', 'U', 'E', 'S', 'X', 'Y':
+			flush()
+			switch c {
+			case 'B':
+				font.Bold = !font.Bold
+			case 'I':
+				font.Italic = !font.Italic
+			case 'U':
+				font.Underline = !font.Underline
+			case 'E':
+				font.DoubleUnderline = !font.DoubleUnderline
+			case 'S':
+				font.Strike = !font.Strike
+			case 'X':
+				font.Superscript = !font.Superscript
+			default:
+				font.Subscript = !font.Subscript
+			}
+		case '"':
+			end := strings.IndexByte(s[i+1:], '"')
+			if end < 0 {
+				text.WriteString(s[i-1:])
+				i = len(s)
+				break
+			}
+			flush()
+			spec := s[i+1 : i+1+end]
+			font.Name, font.Style, _ = strings.Cut(spec, ",")
+			i += end + 1
+		case 'K':
+			if i+6 < len(s) {
+				flush()
+				font.Color = s[i+1 : i+7]
+				i += 6
+			} else {
+				text.WriteString("&K")
+			}
+		default:
+			if isDigit(c) {
+				k := i
+				for k < len(s) && isDigit(s[k]) {
+					k++
+				}
+				flush()
+				n, _ := strconv.Atoi(s[i:k])
+				font.Size = float64(n)
+				i = k - 1
+				break
+			}
+			text.WriteByte('&')
+			text.WriteByte(c)
+		}
+	}
+	flush()
+	return out
+}
+
+func isDigit(b byte) bool { return b >= '0' && b <= '9' }
+
+// HFValues supplies the values of header/footer fields when rendering.
+type HFValues struct {
+	// Page and Pages are the current page number and the page count.
+	Page, Pages int
+	// Time is the print date and time.
+	Time time.Time
+	// Sheet, File and Path are the sheet name, workbook file name and the
+	// directory of the workbook.
+	Sheet, File, Path string
+}
+
+// Render returns the plain text of parts with fields replaced by the
+// values in v.  Dates are rendered as YYYY-MM-DD and times as HH:MM;
+// pictures render as "".
+func (v HFValues) Render(parts []HFPart) string {
+	var sb strings.Builder
+	for _, p := range parts {
+		switch p.Field {
+		case HFText:
+			sb.WriteString(p.Text)
+		case HFPageNumber:
+			sb.WriteString(strconv.Itoa(v.Page + p.Offset))
+		case HFPageCount:
+			sb.WriteString(strconv.Itoa(v.Pages))
+		case HFDate:
+			sb.WriteString(v.Time.Format("2006-01-02"))
+		case HFTime:
+			sb.WriteString(v.Time.Format("15:04"))
+		case HFSheetName:
+			sb.WriteString(v.Sheet)
+		case HFFileName:
+			sb.WriteString(v.File)
+		case HFFilePath:
+			sb.WriteString(v.Path)
+		}
+	}
+	return sb.String()
+}
+
+// ── record decoders ───────────────────────────────────────────────────────────
+
+// parsePageMarginsRecord decodes a PAGEMARGINS record (MS-XLSB BrtMargins):
+// six doubles — left, right, top, bottom, header, footer — in inches.
+func parsePageMarginsRecord(data []byte) (PageMargins, error) {
+	rr := record.NewRecordReader(data)
+	var v [6]float64
+	for i := range v {
+		f, err := rr.ReadDouble()
+		if err != nil {
+			return PageMargins{}, err
+		}
+		v[i] = f
+	}
+	return PageMargins{Left: v[0], Right: v[1], Top: v[2], Bottom: v[3], Header: v[4], Footer: v[5]}, nil
+}
+
+// parsePrintOptionsRecord decodes a PRINTOPTIONS record (MS-XLSB
+// BrtPrintOptions).
+//
+//	flags = read_uint16()  // bit 0 fHCenter, 1 fVCenter, 2 fPrintHeaders, 3 fPrintGrid
+func parsePrintOptionsRecord(data []byte) (PrintOptions, error) {
+	rr := record.NewRecordReader(data)
+	flags, err := rr.ReadUint16()
+	if err != nil {
+		return PrintOptions{}, err
+	}
+	return PrintOptions{
+		HorizontalCentered: flags&0x0001 != 0,
+		VerticalCentered:   flags&0x0002 != 0,
+		Headings:           flags&0x0004 != 0,
+		GridLines:          flags&0x0008 != 0,
+	}, nil
+}
+
+// parsePageSetupRecord decodes a PAGESETUP record (MS-XLSB BrtPageSetup).
+//
+//	iPaperSize, iScale, iRes, iVRes, iCopies, iPageStart,
+//	iFitWidth, iFitHeight = read_uint32() × 8
+//	flags    = read_uint16()  // bit 0 fLeftToRight, 1 fLandscape, 3 fNoColor,
+//	                          // 4 fDraft, 5 fNotes, 6 fNoOrient, 7 fUsePage,
+//	                          // 8 fEndNotes, bits 9-10 iErrors
+//	szRelID  = read_nullable_string()
+//
+// The bits follow BIFF8 SETUP except fEndNotes, which moved down to bit 8;
+// comments are printed only when fNotes is set, at the end of the sheet
+// when fEndNotes is also set.
+func parsePageSetupRecord(data []byte) (PageSetup, error) {
+	rr := record.NewRecordReader(data)
+	var v [8]uint32
+	for i := range v {
+		u, err := rr.ReadUint32()
+		if err != nil {
+			return PageSetup{}, err
+		}
+		v[i] = u
+	}
+	flags, err := rr.ReadUint16()
+	if err != nil {
+		return PageSetup{}, err
+	}
+	var rid string
+	if rr.Len() > 0 {
+		if rid, err = rr.ReadNullableString(); err != nil {
+			return PageSetup{}, err
+		}
+	}
+	for i, u := range v {
+		if u > 0x7FFFFFFF {
+			return PageSetup{}, fmt.Errorf("pagesetup: field %d (%d) out of range", i, u)
+		}
+	}
+	ps := PageSetup{
+		PaperSize:          int(v[0]),
+		Scale:              int(v[1]),
+		HorizontalDPI:      int(v[2]),
+		VerticalDPI:        int(v[3]),
+		Copies:             int(v[4]),
+		FirstPageNumber:    int(v[5]),
+		FitToWidth:         int(v[6]),
+		FitToHeight:        int(v[7]),
+		OverThenDown:       flags&0x0001 != 0,
+		BlackAndWhite:      flags&0x0008 != 0,
+		Draft:              flags&0x0010 != 0,
+		UseFirstPageNumber: flags&0x0080 != 0,
+		Errors:             PrintErrors(flags >> 9 & 0x03),
+		PrinterSettingsRID: rid,
+	}
+	switch {
+	case flags&0x0020 == 0: // fNotes
+		ps.Comments = PrintCommentsNone
+	case flags&0x0100 != 0: // fEndNotes
+		ps.Comments = PrintCommentsAtEnd
+	default:
+		ps.Comments = PrintCommentsAsDisplayed
+	}
+	switch {
+	case flags&0x0040 != 0: // fNoOrient
+		ps.Orientation = OrientationDefault
+	case flags&0x0002 != 0:
+		ps.Orientation = OrientationLandscape
+	default:
+		ps.Orientation = OrientationPortrait
+	}
+	return ps, nil
+}
+
+// parseBreakRecord decodes a BRK record (MS-XLSB BrtBrk).  maxIdx is the
+// largest valid index for At (MaxRow for row breaks, MaxCol for column
+// breaks); maxSpan that for Min and Max.
+//
+//	unRwCol     = read_uint32()
+//	unColRwStrt = read_uint32()
+//	unColRwEnd  = read_uint32()
+//	fMan        = read_uint32()
+//	fPivot      = read_uint32()
+func parseBreakRecord(data []byte, maxIdx, maxSpan int) (PageBreak, error) {
+	rr := record.NewRecordReader(data)
+	var v [5]uint32
+	for i := range v {
+		u, err := rr.ReadUint32()
+		if err != nil {
+			return PageBreak{}, err
+		}
+		v[i] = u
+	}
+	if int64(v[0]) > int64(maxIdx)+1 || v[1] > v[2] || int64(v[2]) > int64(maxSpan) {
+		return PageBreak{}, fmt.Errorf("brk: break %d spanning %d-%d out of range", v[0], v[1], v[2])
+	}
+	return PageBreak{
+		At:     int(v[0]),
+		Min:    int(v[1]),
+		Max:    int(v[2]),
+		Manual: v[3] != 0,
+		Pivot:  v[4] != 0,
+	}, nil
+}
+
+// parseHeaderFooterRecord decodes a HEADERFOOTER record (MS-XLSB
+// BrtBeginHeaderFooter).
+//
+//	flags = read_uint16()  // bit 0 fHFDiffOddEven, 1 fHFDiffFirst,
+//	                       // 2 fHFScaleWithDoc, 3 fHFAlignMargins
+//	stHeader, stFooter, stHeaderEven, stFooterEven,
+//	stHeaderFirst, stFooterFirst = read_nullable_string() × 6
+func parseHeaderFooterRecord(data []byte) (HeaderFooter, error) {
+	rr := record.NewRecordReader(data)
+	flags, err := rr.ReadUint16()
+	if err != nil {
+		return HeaderFooter{}, err
+	}
+	var st [6]string
+	for i := range st {
+		if st[i], err = rr.ReadNullableString(); err != nil {
+			return HeaderFooter{}, err
+		}
+	}
+	return HeaderFooter{
+		DifferentOddEven: flags&0x0001 != 0,
+		DifferentFirst:   flags&0x0002 != 0,
+		ScaleWithDoc:     flags&0x0004 != 0,
+		AlignWithMargins: flags&0x0008 != 0,
+		OddHeader:        st[0],
+		OddFooter:        st[1],
+		EvenHeader:       st[2],
+		EvenFooter:       st[3],
+		FirstHeader:      st[4],
+		FirstFooter:      st[5],
+	}, nil
+}
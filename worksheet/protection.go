@@ -0,0 +1,213 @@
+package worksheet
+
+import (
+	"fmt"
+
+	"github.com/TsubasaBE/go-xlsb/cellref"
+	"github.com/TsubasaBE/go-xlsb/internal/isopassword"
+	"github.com/TsubasaBE/go-xlsb/record"
+)
+
+// PasswordHash describes how a protection password is verified.  Files
+// written by current Excel versions store a salted, iterated hash
+// (Algorithm, Hash, Salt, SpinCount); older files store a 16-bit verifier
+// computed with Excel's XOR scheme (Legacy).  Neither form can be turned back
+// into the password.
+type PasswordHash struct {
+	// Legacy is the 16-bit verifier of the legacy scheme, or 0 when there is
+	// none.
+	Legacy uint16
+	// Algorithm is the hash algorithm name, e.g. "SHA-512"; empty for the
+	// legacy scheme.
+	Algorithm string
+	// Hash and Salt are the password hash and its salt.
+	Hash, Salt []byte
+	// SpinCount is the number of times the hash was iterated.
+	SpinCount int
+}
+
+// IsSet reports whether a password is required to lift the protection.
+func (p PasswordHash) IsSet() bool {
+	return p.Legacy != 0 || len(p.Hash) > 0
+}
+
+// readIsoPassword reads an IsoPasswordData structure from rr and combines
+// it with the spin count stored alongside it in the enclosing record.
+func readIsoPassword(rr *record.RecordReader, spinCount uint32) (PasswordHash, error) {
+	d, err := isopassword.Read(rr)
+	if err != nil {
+		return PasswordHash{}, err
+	}
+	return PasswordHash{Algorithm: d.Algorithm, Hash: d.Hash, Salt: d.Salt, SpinCount: int(spinCount)}, nil
+}
+
+// SheetProtection holds the protection settings of a sheet
+// (BrtSheetProtection or BrtSheetProtectionIso).  The Allow fields say which
+// actions remain available to users while the sheet is protected.
+type SheetProtection struct {
+	// Protected is true when the sheet contents are locked.  A record may be
+	// present with Protected false when protection was configured and then
+	// turned off.
+	Protected bool
+	// Password verifies the password that lifts the protection.
+	Password PasswordHash
+
+	AllowEditObjects         bool
+	AllowEditScenarios       bool
+	AllowFormatCells         bool
+	AllowFormatColumns       bool
+	AllowFormatRows          bool
+	AllowInsertColumns       bool
+	AllowInsertRows          bool
+	AllowInsertHyperlinks    bool
+	AllowDeleteColumns       bool
+	AllowDeleteRows          bool
+	AllowSelectLockedCells   bool
+	AllowSort                bool
+	AllowAutoFilter          bool
+	AllowPivotTables         bool
+	AllowSelectUnlockedCells bool
+}
+
+// ProtectedRange is a range users may edit on a protected sheet, possibly
+// after entering its own password (BrtRangeProtection or
+// BrtRangeProtectionIso).
+type ProtectedRange struct {
+	// Name is the title shown in the Allow Edit Ranges dialog.
+	Name string
+	// Ranges are the cells the entry unlocks.
+	Ranges []cellref.Range
+	// Password verifies the password of the range.
+	Password PasswordHash
+	// SecurityDescriptor is the raw Windows security descriptor listing the
+	// users who may edit the range without a password, or nil.
+	SecurityDescriptor []byte
+}
+
+// parseSheetProtectionRecord decodes a SHEETPROTECTION record (MS-XLSB
+// BrtSheetProtection), or a SHEETPROTECTIONISO record (BrtSheetProtectionIso)
+// when iso is true.
+//
+//	BrtSheetProtection:
+//	  protpwd     = read_uint16()
+//	  flags       = 16 × read_uint32()  // fLocked, fObjects, fScenarios,
+//	                                    // fFormatCells, fFormatColumns,
+//	                                    // fFormatRows, fInsertColumns,
+//	                                    // fInsertRows, fInsertHyperlinks,
+//	                                    // fDeleteColumns, fDeleteRows,
+//	                                    // fSelLockedCells, fSort, fAutoFilter,
+//	                                    // fPivotTables, fSelUnlockedCells
+//	BrtSheetProtectionIso:
+//	  dwSpinCount = read_uint32()
+//	  flags       = 16 × read_uint32()
+//	  ipdPasswordData (see readIsoPassword)
+//
+// A set action flag means the action is blocked.
+func parseSheetProtectionRecord(data []byte, iso bool) (SheetProtection, error) {
+	rr := record.NewRecordReader(data)
+	var (
+		pwd  PasswordHash
+		spin uint32
+		err  error
+	)
+	if iso {
+		spin, err = rr.ReadUint32()
+	} else {
+		pwd.Legacy, err = rr.ReadUint16()
+	}
+	if err != nil {
+		return SheetProtection{}, err
+	}
+	var f [16]uint32
+	for i := range f {
+		if f[i], err = rr.ReadUint32(); err != nil {
+			return SheetProtection{}, err
+		}
+	}
+	if iso {
+		if pwd, err = readIsoPassword(rr, spin); err != nil {
+			return SheetProtection{}, err
+		}
+	}
+	return SheetProtection{
+		Protected:                f[0] != 0,
+		Password:                 pwd,
+		AllowEditObjects:         f[1] == 0,
+		AllowEditScenarios:       f[2] == 0,
+		AllowFormatCells:         f[3] == 0,
+		AllowFormatColumns:       f[4] == 0,
+		AllowFormatRows:          f[5] == 0,
+		AllowInsertColumns:       f[6] == 0,
+		AllowInsertRows:          f[7] == 0,
+		AllowInsertHyperlinks:    f[8] == 0,
+		AllowDeleteColumns:       f[9] == 0,
+		AllowDeleteRows:          f[10] == 0,
+		AllowSelectLockedCells:   f[11] == 0,
+		AllowSort:                f[12] == 0,
+		AllowAutoFilter:          f[13] == 0,
+		AllowPivotTables:         f[14] == 0,
+		AllowSelectUnlockedCells: f[15] == 0,
+	}, nil
+}
+
+// parseRangeProtectionRecord decodes a RANGEPROTECTION record (MS-XLSB
+// BrtRangeProtection), or a RANGEPROTECTIONISO record
+// (BrtRangeProtectionIso) when iso is true.
+//
+//	BrtRangeProtection:
+//	  protpwd     = read_uint16()
+//	  sqRfX       = crfx uint32, crfx × (rwFirst, rwLast, colFirst, colLast uint32)
+//	  rgchTitle   = read_string()
+//	  cbSD        = read_uint32()
+//	  rgbSD       = cbSD bytes
+//	BrtRangeProtectionIso:
+//	  sqRfX, rgchTitle, cbSD, rgbSD as above
+//	  dwSpinCount = read_uint32()
+//	  ipdPasswordData (see readIsoPassword)
+func parseRangeProtectionRecord(data []byte, iso bool) (ProtectedRange, error) {
+	rr := record.NewRecordReader(data)
+	var p ProtectedRange
+	if !iso {
+		legacy, err := rr.ReadUint16()
+		if err != nil {
+			return ProtectedRange{}, err
+		}
+		p.Password.Legacy = legacy
+	}
+	n, err := rr.ReadUint32()
+	if err != nil {
+		return ProtectedRange{}, err
+	}
+	// Each range is 16 bytes; reject counts the payload cannot hold.
+	if int64(n)*16 > int64(rr.Len()) {
+		return ProtectedRange{}, fmt.Errorf("rangeprotection: %d ranges declared in %d bytes", n, rr.Len())
+	}
+	for range n {
+		var f [4]uint32
+		for i := range f {
+			if f[i], err = rr.ReadUint32(); err != nil {
+				return ProtectedRange{}, err
+			}
+		}
+		if f[1] < f[0] || f[3] < f[2] || f[1] > cellref.MaxRow || f[3] > cellref.MaxCol {
+			return ProtectedRange{}, fmt.Errorf("rangeprotection: invalid range")
+		}
+		p.Ranges = append(p.Ranges, cellref.NewRange(int(f[0]), int(f[2]), int(f[1]), int(f[3])))
+	}
+	if p.Name, err = rr.ReadString(); err != nil {
+		return ProtectedRange{}, err
+	}
+	if p.SecurityDescriptor, err = isopassword.ReadBlob(rr); err != nil {
+		return ProtectedRange{}, err
+	}
+	if iso {
+		spin, err := rr.ReadUint32()
+		if err != nil {
+			return ProtectedRange{}, err
+		}
+		if p.Password, err = readIsoPassword(rr, spin); err != nil {
+			return ProtectedRange{}, err
+		}
+	}
+	return p, nil
+}
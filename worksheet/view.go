@@ -0,0 +1,301 @@
+package worksheet
+
+import (
+	"fmt"
+
+	"github.com/TsubasaBE/go-xlsb/cellref"
+	"github.com/TsubasaBE/go-xlsb/record"
+)
+
+// ViewType is the display mode of a sheet view (the xlView field of
+// BrtBeginWsView).
+type ViewType int
+
+const (
+	// ViewNormal is the normal view.
+	ViewNormal ViewType = 0
+	// ViewPageBreakPreview shows page breaks over the grid.
+	ViewPageBreakPreview ViewType = 1
+	// ViewPageLayout shows the sheet as printed pages.
+	ViewPageLayout ViewType = 2
+)
+
+// PaneType identifies one of the up to four panes of a split or frozen view.
+type PaneType int
+
+const (
+	// PaneBottomRight is the bottom-right pane; it is the only pane when
+	// the view is split both ways or not at all.
+	PaneBottomRight PaneType = 0
+	// PaneTopRight is the top-right pane.
+	PaneTopRight PaneType = 1
+	// PaneBottomLeft is the bottom-left pane.
+	PaneBottomLeft PaneType = 2
+	// PaneTopLeft is the top-left pane.
+	PaneTopLeft PaneType = 3
+)
+
+// SheetView is one window view of a worksheet (BrtBeginWsView with the
+// BrtPane and BrtSel records it encloses).
+type SheetView struct {
+	// WorkbookView is the 0-based index of the workbook window this view
+	// belongs to.
+	WorkbookView int
+	// Type is the display mode.
+	Type ViewType
+	// TopRow and LeftCol are the 0-based row and column of the top-left
+	// visible cell (of the top-left pane when the view is split).
+	TopRow, LeftCol int
+	// Zoom is the current zoom percentage (10-400).
+	Zoom int
+	// ZoomNormal, ZoomPageBreakPreview and ZoomPageLayout are the zoom
+	// percentages remembered for each display mode; 0 means the default.
+	ZoomNormal, ZoomPageBreakPreview, ZoomPageLayout int
+	// ShowFormulas is true when cells display formulas instead of values.
+	ShowFormulas bool
+	// ShowGridLines is true when gridlines are displayed.
+	ShowGridLines bool
+	// ShowHeaders is true when row and column headings are displayed.
+	ShowHeaders bool
+	// ShowZeros is true when zero values are displayed (rather than blank).
+	ShowZeros bool
+	// ShowRuler is true when the ruler is displayed in page layout view.
+	ShowRuler bool
+	// ShowOutlineSymbols is true when outline (grouping) symbols are shown.
+	ShowOutlineSymbols bool
+	// ShowWhitespace is false when the white space around pages is hidden
+	// in page layout view.
+	ShowWhitespace bool
+	// RightToLeft is true when columns run from right to left.
+	RightToLeft bool
+	// TabSelected is true when the sheet tab is selected.
+	TabSelected bool
+	// WindowProtected is true when the window is protected.
+	WindowProtected bool
+	// GridColor is the indexed colour of the gridlines, or -1 when the
+	// default colour is used.
+	GridColor int
+	// Pane describes split or frozen panes; it is nil for a single pane.
+	Pane *Pane
+	// Selections holds the selection of each pane.
+	Selections []Selection
+}
+
+// FrozenRows returns the number of rows frozen at the top of the view, or 0
+// when no rows are frozen.
+func (v SheetView) FrozenRows() int {
+	if v.Pane == nil || !v.Pane.Frozen {
+		return 0
+	}
+	return int(v.Pane.YSplit)
+}
+
+// FrozenCols returns the number of columns frozen at the left of the view,
+// or 0 when no columns are frozen.
+func (v SheetView) FrozenCols() int {
+	if v.Pane == nil || !v.Pane.Frozen {
+		return 0
+	}
+	return int(v.Pane.XSplit)
+}
+
+// Pane describes how a sheet view is split (BrtPane).
+type Pane struct {
+	// XSplit and YSplit are the horizontal and vertical split positions.
+	// For frozen panes they are the number of columns and rows frozen; for
+	// split panes they are the positions in 1/20 of a point.  0 means no
+	// split in that direction.
+	XSplit, YSplit float64
+	// TopRow and LeftCol are the 0-based row and column of the top-left
+	// visible cell of the bottom-right pane.
+	TopRow, LeftCol int
+	// Active is the pane that has the focus.
+	Active PaneType
+	// Frozen is true when the panes are frozen rather than split.
+	Frozen bool
+	// FrozenNoSplit is true when the panes were frozen without being split
+	// first, so unfreezing leaves a single pane.
+	FrozenNoSplit bool
+}
+
+// Selection is the selection of one pane of a sheet view (BrtSel).
+type Selection struct {
+	// Pane is the pane the selection belongs to.
+	Pane PaneType
+	// ActiveRow and ActiveCol are the 0-based row and column of the active
+	// cell.
+	ActiveRow, ActiveCol int
+	// ActiveRange is the index into Ranges of the range that contains the
+	// active cell.
+	ActiveRange int
+	// Ranges are the selected ranges.
+	Ranges []cellref.Range
+}
+
+// parseSheetViewRecord decodes a SHEETVIEW record (MS-XLSB BrtBeginWsView).
+//
+//	flags        = read_uint16()  // bit 0 fWnProt, 1 fDspFmla, 2 fDspGrid,
+//	                              // 3 fDspRwCol, 4 fDspZeros, 5 fRightToLeft,
+//	                              // 6 fSelected, 7 fDspRuler, 8 fDspGuts,
+//	                              // 9 fDefaultHdr, 10 fWhitespaceHidden
+//	xlView       = read_uint32()
+//	rwTop        = read_uint32()
+//	colLeft      = read_uint32()
+//	icvHdr       = read_uint8()   // gridline colour index
+//	reserved     = 3 bytes
+//	wScale       = read_uint16()  // current zoom; 0 means 100
+//	wScaleNormal = read_uint16()
+//	wScaleSLV    = read_uint16()  // page break preview
+//	wScalePLV    = read_uint16()  // page layout
+//	iWbkView     = read_uint32()
+func parseSheetViewRecord(data []byte) (SheetView, error) {
+	rr := record.NewRecordReader(data)
+	flags, err := rr.ReadUint16()
+	if err != nil {
+		return SheetView{}, err
+	}
+	var u [3]uint32 // xlView, rwTop, colLeft
+	for i := range u {
+		if u[i], err = rr.ReadUint32(); err != nil {
+			return SheetView{}, err
+		}
+	}
+	icvHdr, err := rr.ReadUint8()
+	if err != nil {
+		return SheetView{}, err
+	}
+	if err := rr.Skip(3); err != nil {
+		return SheetView{}, err
+	}
+	var scale [4]uint16 // wScale, wScaleNormal, wScaleSLV, wScalePLV
+	for i := range scale {
+		if scale[i], err = rr.ReadUint16(); err != nil {
+			return SheetView{}, err
+		}
+	}
+	wbView, err := rr.ReadUint32()
+	if err != nil {
+		return SheetView{}, err
+	}
+	if u[1] > cellref.MaxRow || u[2] > cellref.MaxCol {
+		return SheetView{}, fmt.Errorf("sheetview: top-left cell (%d, %d) out of range", u[1], u[2])
+	}
+	v := SheetView{
+		WorkbookView:         int(wbView),
+		Type:                 ViewType(u[0]),
+		TopRow:               int(u[1]),
+		LeftCol:              int(u[2]),
+		Zoom:                 int(scale[0]),
+		ZoomNormal:           int(scale[1]),
+		ZoomPageBreakPreview: int(scale[2]),
+		ZoomPageLayout:       int(scale[3]),
+		WindowProtected:      flags&0x0001 != 0,
+		ShowFormulas:         flags&0x0002 != 0,
+		ShowGridLines:        flags&0x0004 != 0,
+		ShowHeaders:          flags&0x0008 != 0,
+		ShowZeros:            flags&0x0010 != 0,
+		RightToLeft:          flags&0x0020 != 0,
+		TabSelected:          flags&0x0040 != 0,
+		ShowRuler:            flags&0x0080 != 0,
+		ShowOutlineSymbols:   flags&0x0100 != 0,
+		ShowWhitespace:       flags&0x0400 == 0,
+		GridColor:            int(icvHdr),
+	}
+	if v.Zoom == 0 {
+		v.Zoom = 100
+	}
+	if flags&0x0200 != 0 { // fDefaultHdr
+		v.GridColor = -1
+	}
+	return v, nil
+}
+
+// parsePaneRecord decodes a PANE record (MS-XLSB BrtPane).
+//
+//	xnumXSplit = read_double()
+//	xnumYSplit = read_double()
+//	rwTop      = read_uint32()
+//	colLeft    = read_uint32()
+//	pnnAct     = read_uint32()
+//	flags      = read_uint8()   // bit 0 fFrozen, 1 fFrozenNoSplit
+func parsePaneRecord(data []byte) (Pane, error) {
+	rr := record.NewRecordReader(data)
+	x, err := rr.ReadDouble()
+	if err != nil {
+		return Pane{}, err
+	}
+	y, err := rr.ReadDouble()
+	if err != nil {
+		return Pane{}, err
+	}
+	var u [3]uint32 // rwTop, colLeft, pnnAct
+	for i := range u {
+		if u[i], err = rr.ReadUint32(); err != nil {
+			return Pane{}, err
+		}
+	}
+	flags, err := rr.ReadUint8()
+	if err != nil {
+		return Pane{}, err
+	}
+	if u[0] > cellref.MaxRow || u[1] > cellref.MaxCol || u[2] > uint32(PaneTopLeft) {
+		return Pane{}, fmt.Errorf("pane: invalid top-left cell or active pane")
+	}
+	return Pane{
+		XSplit:        x,
+		YSplit:        y,
+		TopRow:        int(u[0]),
+		LeftCol:       int(u[1]),
+		Active:        PaneType(u[2]),
+		Frozen:        flags&0x01 != 0,
+		FrozenNoSplit: flags&0x02 != 0,
+	}, nil
+}
+
+// parseSelectionRecord decodes a SELECTION record (MS-XLSB BrtSel).
+//
+//	pnn     = read_uint32()
+//	rwAct   = read_uint32()
+//	colAct  = read_uint32()
+//	irefAct = read_uint32()
+//	crfx    = read_uint32()
+//	rgrfx   = crfx × (rwFirst, rwLast, colFirst, colLast uint32)
+func parseSelectionRecord(data []byte) (Selection, error) {
+	rr := record.NewRecordReader(data)
+	var u [5]uint32
+	for i := range u {
+		v, err := rr.ReadUint32()
+		if err != nil {
+			return Selection{}, err
+		}
+		u[i] = v
+	}
+	if u[0] > uint32(PaneTopLeft) || u[1] > cellref.MaxRow || u[2] > cellref.MaxCol {
+		return Selection{}, fmt.Errorf("selection: invalid pane or active cell")
+	}
+	// Each range is 16 bytes; reject counts the payload cannot hold.
+	if int64(u[4])*16 > int64(rr.Len()) {
+		return Selection{}, fmt.Errorf("selection: %d ranges declared in %d bytes", u[4], rr.Len())
+	}
+	sel := Selection{
+		Pane:        PaneType(u[0]),
+		ActiveRow:   int(u[1]),
+		ActiveCol:   int(u[2]),
+		ActiveRange: int(u[3]),
+	}
+	for range u[4] {
+		var f [4]uint32
+		for i := range f {
+			v, err := rr.ReadUint32()
+			if err != nil {
+				return Selection{}, err
+			}
+			f[i] = v
+		}
+		if f[1] < f[0] || f[3] < f[2] || f[1] > cellref.MaxRow || f[3] > cellref.MaxCol {
+			return Selection{}, fmt.Errorf("selection: invalid range")
+		}
+		sel.Ranges = append(sel.Ranges, cellref.NewRange(int(f[0]), int(f[2]), int(f[1]), int(f[3])))
+	}
+	return sel, nil
+}
@@ -4,11 +4,16 @@ package worksheet
 
 import (
 	"bytes"
+	"cmp"
 	"fmt"
 	"io"
+	"slices"
 
 	"github.com/TsubasaBE/go-xlsb/biff12"
+	"github.com/TsubasaBE/go-xlsb/cellref"
+	"github.com/TsubasaBE/go-xlsb/formula"
 	"github.com/TsubasaBE/go-xlsb/internal/rels"
+	"github.com/TsubasaBE/go-xlsb/internal/sheetpr"
 	"github.com/TsubasaBE/go-xlsb/record"
 	"github.com/TsubasaBE/go-xlsb/stringtable"
 	"github.com/TsubasaBE/go-xlsb/styles"
@@ -26,6 +31,12 @@ type Dimension struct {
 	W int
 }
 
+// String returns the used range in A1 notation, e.g. "A1:C10" ("B2" for a
+// single cell).
+func (d Dimension) String() string {
+	return cellref.NewRange(d.R, d.C, d.R+d.H-1, d.C+d.W-1).String()
+}
+
 // Col describes a column definition record.
 // C1 and C2 are the 0-based first and last column indices of the range this
 // definition applies to (inclusive). Width is the column width in character
@@ -41,6 +52,121 @@ type Col struct {
 	Width float64
 	// Style is the 0-based cell-format (XF) index applied to blank cells.
 	Style int
+	// Hidden is true when the columns are hidden.
+	Hidden bool
+	// CustomWidth is true when the width was set by the user rather than
+	// being the default.
+	CustomWidth bool
+	// BestFit is true when the width was set by auto-fitting the content.
+	BestFit bool
+	// ShowPhonetic is true when phonetic guides are displayed in the columns.
+	ShowPhonetic bool
+	// OutlineLevel is the outline (grouping) level, 0 to 7.
+	OutlineLevel int
+	// Collapsed is true when the outline group next to these columns is
+	// collapsed.
+	Collapsed bool
+}
+
+// RowInfo holds the properties stored in a row's BrtRowHdr record.
+type RowInfo struct {
+	// R is the 0-based row index.
+	R int
+	// Height is the row height in points.
+	Height float64
+	// CustomHeight is true when the height was set explicitly rather than
+	// derived from the row's content.
+	CustomHeight bool
+	// Hidden is true when the row is hidden (including rows hidden by
+	// collapsing an outline group or by a filter).
+	Hidden bool
+	// OutlineLevel is the outline (grouping) level, 0 to 7.
+	OutlineLevel int
+	// Collapsed is true when the outline group following this row is
+	// collapsed.
+	Collapsed bool
+	// Style is the 0-based cell-format (XF) index of the row.  It applies
+	// to empty cells of the row only when CustomStyle is true.
+	Style int
+	// CustomStyle is true when the row has its own format.
+	CustomStyle bool
+	// ThickTop and ThickBottom are true when a cell of the row has a thick
+	// or double top / bottom border, adding space above / below the text.
+	ThickTop, ThickBottom bool
+	// ShowPhonetic is true when phonetic guides are displayed in the row.
+	ShowPhonetic bool
+	// Spans lists the column ranges [C1, C2] (0-based, inclusive) of the
+	// row that may hold cells, as a hint for readers.  It may be empty.
+	Spans [][2]int
+}
+
+// SheetProperties holds the sheet-level settings of the SHEETPR record
+// (MS-XLSB BrtWsProp).
+type SheetProperties struct {
+	// CodeName is the name VBA code uses for the sheet (e.g. "Sheet1"); it is
+	// independent of the tab name.
+	CodeName string
+	// TabColor is the colour of the sheet tab, or nil when the tab has no
+	// colour.  Its Type tells whether it is an indexed, theme or RGB colour.
+	TabColor *styles.Color
+	// FilterMode is true when an AutoFilter on the sheet is filtering rows.
+	FilterMode bool
+	// SummaryBelow is true when outline summary rows are below the detail
+	// rows (Excel's default).
+	SummaryBelow bool
+	// SummaryRight is true when outline summary columns are to the right of
+	// the detail columns (Excel's default).
+	SummaryRight bool
+	// ApplyStyles is true when outline styles are applied automatically.
+	ApplyStyles bool
+	// ShowOutlineSymbols is true when outline symbols are displayed.
+	ShowOutlineSymbols bool
+	// FitToPage is true when printing scales the sheet to fit the number of
+	// pages set in the page setup.
+	FitToPage bool
+	// ShowAutoPageBreaks is true when automatic page breaks are displayed.
+	ShowAutoPageBreaks bool
+	// Published is true when the sheet is published to the web.
+	Published bool
+	// Dialog is true for an Excel 5 dialog sheet.
+	Dialog bool
+	// SyncHorizontal and SyncVertical are true when scrolling is synchronised
+	// across sheets; SyncRow and SyncCol are the 0-based anchor cell.
+	SyncHorizontal, SyncVertical bool
+	SyncRow, SyncCol             int
+	// TransitionEvaluation and TransitionEntry enable Lotus 1-2-3 formula
+	// evaluation and entry rules.
+	TransitionEvaluation, TransitionEntry bool
+	// CondFmtCalc is true when conditional formatting is recalculated on
+	// load.
+	CondFmtCalc bool
+}
+
+// SheetFormat holds the sheet-wide row and column defaults from the
+// SHEETFORMATPR record (MS-XLSB BrtWsFmtInfo).
+type SheetFormat struct {
+	// DefaultColWidth is the width, in character units, of columns without
+	// a COL record.  When the record leaves it unset it is derived from
+	// BaseColWidth the way Excel does (see Worksheet.ColumnAt).
+	DefaultColWidth float64
+	// BaseColWidth is the default column width in characters, not counting
+	// cell padding.
+	BaseColWidth int
+	// DefaultRowHeight is the height, in points, of rows without a custom
+	// height.
+	DefaultRowHeight float64
+	// CustomHeight is true when DefaultRowHeight was set explicitly rather
+	// than derived from the default font.
+	CustomHeight bool
+	// ZeroHeight is true when rows are hidden by default; only rows with a
+	// ROW record that clears the hidden flag are shown.
+	ZeroHeight bool
+	// ThickTop and ThickBottom are true when rows have a thick or double
+	// top / bottom border by default.
+	ThickTop, ThickBottom bool
+	// OutlineLevelRow and OutlineLevelCol are the highest row and column
+	// outline levels used in the sheet.
+	OutlineLevelRow, OutlineLevelCol int
 }
 
 // MergeArea describes a merged cell range.
@@ -58,6 +184,11 @@ type MergeArea struct {
 	W int
 }
 
+// String returns the merged range in A1 notation, e.g. "A1:B2".
+func (m MergeArea) String() string {
+	return cellref.NewRange(m.R, m.C, m.R+m.H-1, m.C+m.W-1).String()
+}
+
 // Cell is a single worksheet cell.
 type Cell struct {
 	// R is the 0-based row index of the cell.
@@ -66,16 +197,54 @@ type Cell struct {
 	C int
 	// V holds the typed cell value. The dynamic type is one of:
 	//   - nil          — blank / empty cell
-	//   - string       — text, formula-string result, or Excel error string (e.g. "#DIV/0!")
+	//   - string       — text or formula-string result
 	//   - float64      — numeric value, date serial, or formula-float result
 	//   - bool         — boolean value
+	//   - CellError    — Excel error value or formula-error result (e.g. ErrDiv0)
 	V any
 	// Style is the 0-based index into the workbook's cell-format (XF) table.
 	// It is 0 for cells whose record carried no explicit style or for empty
 	// padding cells emitted in dense (sparse=false) mode.
 	Style int
+	// Formula is the decompiled formula text including the leading "=",
+	// e.g. "=SUM(A1:B3)*Sheet2!$C$4"; V then holds the cached result.  Array
+	// formulas are wrapped in braces ("{=A1:A3*B1:B3}") as Excel displays
+	// them.  Formula is empty for non-formula cells and for formulas that use
+	// constructs the decompiler does not support (e.g. structured table
+	// references).
+	Formula string
+	// Rich holds the formatting runs and phonetic guide of a rich-text string
+	// cell — either an inline rich string (BrtCellRString) or a shared string
+	// whose SST entry carries runs or phonetic data; V then holds the plain
+	// text.  It is nil for all other cells and for strings with neither.  The
+	// value is shared and must not be modified.
+	Rich *stringtable.RichString
+	// Err is non-nil when the cell record is truncated or malformed; V is then
+	// ErrValue.  It distinguishes a corrupt record from a cell that genuinely
+	// holds #VALUE!.
+	Err error
+	// Kind says which kind of cell record produced the cell: a constant, a
+	// formula, a blank record, or KindNone for a position with no record.
+	// It is set from the record type even when Err is non-nil.
+	Kind CellKind
 }
 
+// CellError is an Excel error value such as #DIV/0!; it is the dynamic type
+// of Cell.V for error cells.  See formula.CellError.
+type CellError = formula.CellError
+
+// Excel error values, as stored in Cell.V.
+const (
+	ErrNull        = formula.ErrNull
+	ErrDiv0        = formula.ErrDiv0
+	ErrValue       = formula.ErrValue
+	ErrRef         = formula.ErrRef
+	ErrName        = formula.ErrName
+	ErrNum         = formula.ErrNum
+	ErrNA          = formula.ErrNA
+	ErrGettingData = formula.ErrGettingData
+)
+
 // Worksheet holds parsed metadata and provides row iteration for one sheet.
 type Worksheet struct {
 	// Name is the display name of the worksheet as it appears on the sheet tab.
@@ -86,6 +255,34 @@ type Worksheet struct {
 	// Cols contains the column-definition entries parsed from COL records.
 	// The slice may be empty if the sheet defines no explicit column widths.
 	Cols []Col
+	// Properties holds the code name, tab colour, outline and filter
+	// settings of the sheet.  It is nil if no SHEETPR record was found.
+	Properties *SheetProperties
+	// Views contains the window views of the sheet (usually one) with their
+	// panes, zoom, display options and selections.
+	Views []SheetView
+	// PageSetup holds paper size, orientation, scaling and other printing
+	// settings.  It is nil if no PAGESETUP record was found.
+	PageSetup *PageSetup
+	// PageMargins holds the print margins.  It is nil if no PAGEMARGINS
+	// record was found.
+	PageMargins *PageMargins
+	// PrintOptions holds centering, heading and gridline printing options.
+	// It is nil if no PRINTOPTIONS record was found.
+	PrintOptions *PrintOptions
+	// HeaderFooter holds the raw page header and footer strings.  It is nil
+	// if no HEADERFOOTER record was found.
+	HeaderFooter *HeaderFooter
+	// Format holds the default row height, column widths and outline levels
+	// of the sheet.  It is nil if no SHEETFORMATPR record was found.
+	Format *SheetFormat
+	// Protection holds the sheet protection settings.  It is nil if no
+	// SHEETPROTECTION record was found; check Protection.Protected to see
+	// whether the sheet is locked.
+	Protection *SheetProtection
+	// ProtectedRanges lists the ranges users may edit while the sheet is
+	// protected.
+	ProtectedRanges []ProtectedRange
 	// Hyperlinks maps each hyperlink cell coordinate [row, col] (both 0-based)
 	// to its relationship ID, which can be resolved via the workbook's .rels
 	// file to obtain the target URL.
@@ -105,6 +302,24 @@ type Worksheet struct {
 	rels         map[string]string                // relationship ID → URL (may be nil)
 	stylesTable  styles.StyleTable                // XF style table; may be nil/empty
 	formatFn     func(v any, styleIdx int) string // injected from workbook; may be nil
+	fctx         formula.Context                  // resolves 3-D refs and names; may be nil
+	printArea    []cellref.Range                  // from _xlnm.Print_Area; see WithPrintNames
+	printTitles  PrintTitles                      // from _xlnm.Print_Titles
+	breaks       PageBreaks                       // BrtBrk records
+	rowIndex     []rowOffset                      // built lazily by Row/Cell; nil until then
+	fmlas        map[[2]int]sharedFormula         // shared/array formulas, with rowIndex
+}
+
+// Option configures optional Worksheet behaviour at construction time.
+type Option func(*Worksheet)
+
+// WithFormulaContext sets the context used to resolve sheet references and
+// defined names when decompiling cell formulas.  Without it, 3-D references
+// render as #REF! and names as #NAME?.
+func WithFormulaContext(ctx formula.Context) Option {
+	return func(ws *Worksheet) {
+		ws.fctx = ctx
+	}
 }
 
 // New parses the pre-loaded binary data and optional rels XML for a worksheet.
@@ -113,7 +328,8 @@ type Worksheet struct {
 // information is unavailable.
 // formatFn is an optional closure (typically wb.FormatCell) that renders a
 // cell value to its display string; it may be nil.
-func New(name string, data []byte, relsData []byte, st *stringtable.StringTable, stylesTable styles.StyleTable, formatFn func(v any, styleIdx int) string) (*Worksheet, error) {
+// opts apply optional settings such as WithFormulaContext.
+func New(name string, data []byte, relsData []byte, st *stringtable.StringTable, stylesTable styles.StyleTable, formatFn func(v any, styleIdx int) string, opts ...Option) (*Worksheet, error) {
 	ws := &Worksheet{
 		Name:        name,
 		Hyperlinks:  make(map[[2]int]string),
@@ -122,6 +338,9 @@ func New(name string, data []byte, relsData []byte, st *stringtable.StringTable,
 		stylesTable: stylesTable,
 		formatFn:    formatFn,
 	}
+	for _, opt := range opts {
+		opt(ws)
+	}
 	if len(relsData) > 0 {
 		r, err := rels.ParseRelsXML(relsData)
 		if err == nil {
@@ -198,7 +417,8 @@ func (ws *Worksheet) Rows(sparse bool) func(yield func([]Cell) bool) {
 		dim := ws.effectiveDim()
 		rowNum := -1
 		var row []Cell
-		stopped := false // true once yield returned false
+		stopped := false                        // true once yield returned false
+		fmlas := make(map[[2]int]sharedFormula) // shared/array formulas by anchor
 
 		for {
 			recID, recData, err := rdr.Next()
@@ -246,24 +466,22 @@ func (ws *Worksheet) Rows(sparse bool) func(yield func([]Cell) bool) {
 				rowNum = r
 				row = makeEmptyRow(rowNum, dim)
 
-			case recID >= biff12.Blank && recID <= biff12.FormulaBoolErr:
+			case recID >= biff12.Blank && recID <= biff12.FormulaBoolErr, recID == biff12.CellRString:
 				if row == nil {
 					continue
 				}
-				c, err := parseCellRecord(recData, recID, ws.stringTable)
+				row = ws.putCell(row, rowNum, recID, recData, fmlas)
+
+			case recID == biff12.ShrFmla || recID == biff12.ArrFmla:
+				sf, err := parseSharedFormulaRecord(recData, recID == biff12.ArrFmla)
 				if err != nil {
 					continue
 				}
-				if c.C >= 0 {
-					// Grow the row if the cell lies beyond the declared
-					// Dimension.  This can happen when the DIMENSION record is
-					// absent, wrong, or when Excel writes data outside its own
-					// declared extent.  Growing ensures the cell is never
-					// silently dropped.
-					if c.C >= len(row) {
-						row = growRow(row, c.C)
-					}
-					row[c.C] = Cell{R: rowNum, C: c.C, V: c.V, Style: c.Style}
+				fmlas[[2]int{sf.r1, sf.c1}] = sf
+				// The anchor cell's record precedes this one, so its PtgExp
+				// placeholder could not be resolved yet; fill it in now.
+				if sf.r1 == rowNum && sf.c1 < len(row) && row[sf.c1].Formula == "" {
+					row[sf.c1].Formula = ws.sharedFormulaText(sf, rowNum, sf.c1)
 				}
 
 			case recID == biff12.SheetDataEnd:
@@ -279,8 +497,279 @@ func (ws *Worksheet) Rows(sparse bool) func(yield func([]Cell) bool) {
 	}
 }
 
+// Row returns the cells of row r (0-based) without iterating over the rows
+// before it.  The row has the same shape as one yielded by Rows(false): it
+// spans at least the Dimension's columns and positions without a cell
+// record have a nil V.  A row that has no record in the sheet is returned as
+// such an empty row.
+//
+// The first call to Row or Cell scans the sheet data once to build an index
+// of row offsets (and of the shared formulas that member cells refer to);
+// later calls decode only the requested row.  Row and Cell are not safe for
+// concurrent use.
+func (ws *Worksheet) Row(r int) ([]Cell, error) {
+	if r < 0 || r > cellref.MaxRow {
+		return nil, fmt.Errorf("worksheet: row index %d out of range", r)
+	}
+	if err := ws.buildRowIndex(); err != nil {
+		return nil, err
+	}
+	row := makeEmptyRow(r, ws.effectiveDim())
+	i, found := ws.findRow(r)
+	if !found {
+		return row, nil
+	}
+
+	rdr := record.NewReader(bytes.NewReader(ws.data))
+	if _, err := rdr.Seek(ws.rowIndex[i].off, io.SeekStart); err != nil {
+		return nil, err
+	}
+	for {
+		recID, recData, err := rdr.Next()
+		if err == io.EOF {
+			return row, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("worksheet: row %d: %w", r, err)
+		}
+		switch {
+		case recID == biff12.Row:
+			// A duplicate ROW record continues the current row (see Rows).
+			if n, err := parseRowRecord(recData); err == nil && n == r {
+				continue
+			}
+			return row, nil
+		case recID >= biff12.Blank && recID <= biff12.FormulaBoolErr, recID == biff12.CellRString:
+			row = ws.putCell(row, r, recID, recData, ws.fmlas)
+		case recID == biff12.SheetDataEnd:
+			return row, nil
+		}
+	}
+}
+
+// Cell returns the cell at row r, column c (both 0-based) using the same
+// row index as Row.  A position without a cell record yields a Cell with a
+// nil V.
+func (ws *Worksheet) Cell(r, c int) (Cell, error) {
+	if c < 0 || c > cellref.MaxCol {
+		return Cell{}, fmt.Errorf("worksheet: column index %d out of range", c)
+	}
+	row, err := ws.Row(r)
+	if err != nil {
+		return Cell{}, err
+	}
+	if c >= len(row) {
+		return Cell{R: r, C: c}, nil
+	}
+	cell := row[c]
+	cell.R, cell.C = r, c // growRow leaves gaps zero-valued
+	return cell, nil
+}
+
+// RowInfo returns the properties of row r (0-based): height, visibility,
+// outline level, row format and column spans.  ok is false when the row has
+// no ROW record, in which case the sheet defaults apply.  It shares the
+// row index built by Row and Cell.
+func (ws *Worksheet) RowInfo(r int) (info RowInfo, ok bool, err error) {
+	if err := ws.buildRowIndex(); err != nil {
+		return RowInfo{}, false, err
+	}
+	i, found := ws.findRow(r)
+	if !found {
+		return RowInfo{}, false, nil
+	}
+	return ws.rowIndex[i].info, true, nil
+}
+
+// ColumnAt returns the column definition that applies to column c (0-based).
+// When no COL record covers c, it returns a definition for c alone with the
+// sheet's default width (Format.DefaultColWidth, or Excel's default of 8.43
+// displayed characters when the sheet has no Format) and no flags set.
+func (ws *Worksheet) ColumnAt(c int) Col {
+	for _, col := range ws.Cols {
+		if c >= col.C1 && c <= col.C2 {
+			return col
+		}
+	}
+	w := baseToColWidth(8)
+	if ws.Format != nil {
+		w = ws.Format.DefaultColWidth
+	}
+	return Col{C1: c, C2: c, Width: w}
+}
+
+// ColumnWidth returns the effective width of column c (0-based) in character
+// units: the width of its COL record or the sheet default, and 0 when the
+// column is hidden.
+func (ws *Worksheet) ColumnWidth(c int) float64 {
+	col := ws.ColumnAt(c)
+	if col.Hidden {
+		return 0
+	}
+	return col.Width
+}
+
+// RowInfos returns the properties of every row that has a ROW record, in
+// row order.  Use it to find hidden rows or rebuild outline groups without
+// decoding any cells.
+func (ws *Worksheet) RowInfos() ([]RowInfo, error) {
+	if err := ws.buildRowIndex(); err != nil {
+		return nil, err
+	}
+	infos := make([]RowInfo, len(ws.rowIndex))
+	for i, e := range ws.rowIndex {
+		infos[i] = e.info
+	}
+	return infos, nil
+}
+
 // ── internal helpers ──────────────────────────────────────────────────────────
 
+// rowOffset locates the cells of one row in the sheet stream.
+type rowOffset struct {
+	info RowInfo // decoded BrtRowHdr
+	off  int64   // byte offset just past the ROW record
+}
+
+// findRow returns the position of row r in ws.rowIndex.
+func (ws *Worksheet) findRow(r int) (int, bool) {
+	return slices.BinarySearchFunc(ws.rowIndex, r, func(e rowOffset, r int) int {
+		return cmp.Compare(e.info.R, r)
+	})
+}
+
+// buildRowIndex scans SheetData once, recording the offset and properties of
+// every ROW record and collecting all shared and array formulas.  It does nothing
+// when the index has already been built.
+func (ws *Worksheet) buildRowIndex() error {
+	if ws.rowIndex != nil || !ws.hasSheetData {
+		return nil
+	}
+	rdr := record.NewReader(bytes.NewReader(ws.data))
+	if _, err := rdr.Seek(ws.dataOffset, io.SeekStart); err != nil {
+		return err
+	}
+	index := []rowOffset{}
+	fmlas := make(map[[2]int]sharedFormula)
+	sorted := true
+scan:
+	for {
+		recID, recData, err := rdr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("worksheet: indexing rows: %w", err)
+		}
+		switch recID {
+		case biff12.Row:
+			info, err := parseRowInfo(recData)
+			if err != nil {
+				// Keep the row addressable even when its properties are
+				// corrupt; only the row index is needed to locate its cells.
+				r, err := parseRowRecord(recData)
+				if err != nil {
+					continue
+				}
+				info = RowInfo{R: r}
+			}
+			if n := len(index); n > 0 {
+				if index[n-1].info.R == info.R {
+					continue // duplicate; the row continues
+				}
+				sorted = sorted && index[n-1].info.R < info.R
+			}
+			off, err := rdr.Tell()
+			if err != nil {
+				return err
+			}
+			index = append(index, rowOffset{info: info, off: off})
+		case biff12.ShrFmla, biff12.ArrFmla:
+			sf, err := parseSharedFormulaRecord(recData, recID == biff12.ArrFmla)
+			if err == nil {
+				fmlas[[2]int{sf.r1, sf.c1}] = sf
+			}
+		case biff12.SheetDataEnd:
+			break scan
+		}
+	}
+	if !sorted {
+		// Stable, so the first of several records for a row is found first.
+		slices.SortStableFunc(index, func(a, b rowOffset) int { return cmp.Compare(a.info.R, b.info.R) })
+	}
+	ws.rowIndex, ws.fmlas = index, fmlas
+	return nil
+}
+
+// putCell decodes a cell record of row rowNum into row and returns the
+// (possibly grown) row.  fmlas supplies the shared and array formulas that
+// PtgExp placeholders refer to.  Malformed records are skipped.
+func (ws *Worksheet) putCell(row []Cell, rowNum, recID int, recData []byte, fmlas map[[2]int]sharedFormula) []Cell {
+	c, err := parseCellRecord(recData, recID, ws.stringTable)
+	if err != nil || c.C < 0 {
+		return row
+	}
+	// Grow the row if the cell lies beyond the declared Dimension.  This can
+	// happen when the DIMENSION record is absent, wrong, or when Excel writes
+	// data outside its own declared extent.  Growing ensures the cell is never
+	// silently dropped.
+	if c.C >= len(row) {
+		row = growRow(row, c.C)
+	}
+	row[c.C] = Cell{R: rowNum, C: c.C, V: c.V, Style: c.Style, Rich: c.rich, Kind: c.kind}
+	if c.err != nil {
+		row[c.C].Err = fmt.Errorf("worksheet: decode cell %s: %w", cellref.CellName(rowNum, c.C), c.err)
+	}
+	if c.rgce != nil {
+		row[c.C].Formula = ws.cellFormula(c.rgce, c.extra, rowNum, c.C, fmlas)
+	}
+	return row
+}
+
+// sharedFormula is a decoded BrtShrFmla or BrtArrFmla record.  r1/c1 is the
+// anchor cell that the PtgExp placeholders of member cells point at.
+type sharedFormula struct {
+	r1, r2, c1, c2 int
+	array          bool
+	rgce, extra    []byte
+}
+
+// cellFormula renders the formula of the cell at (r, c).  A PtgExp
+// placeholder is resolved against the shared/array formulas seen so far;
+// it returns "" when the formula cannot be decompiled.
+func (ws *Worksheet) cellFormula(rgce, extra []byte, r, c int, fmlas map[[2]int]sharedFormula) string {
+	if ar, ac, ok := formula.ExpAnchor(rgce, extra); ok {
+		sf, found := fmlas[[2]int{ar, ac}]
+		if !found {
+			return ""
+		}
+		return ws.sharedFormulaText(sf, r, c)
+	}
+	text, err := formula.Decompile(rgce, extra, r, c, ws.fctx)
+	if err != nil {
+		return ""
+	}
+	return "=" + text
+}
+
+// sharedFormulaText renders sf for the member cell at (r, c).  Shared
+// formulas use relative offsets and are decompiled against the member cell;
+// array formulas hold one formula for the whole range, anchored at r1/c1.
+func (ws *Worksheet) sharedFormulaText(sf sharedFormula, r, c int) string {
+	if sf.array {
+		text, err := formula.Decompile(sf.rgce, sf.extra, sf.r1, sf.c1, ws.fctx)
+		if err != nil {
+			return ""
+		}
+		return "{=" + text + "}"
+	}
+	text, err := formula.Decompile(sf.rgce, sf.extra, r, c, ws.fctx)
+	if err != nil {
+		return ""
+	}
+	return "=" + text
+}
+
 // effectiveDim returns the dimension, or a zero-based 1×1 fallback so we
 // never have a nil pointer when building rows.
 func (ws *Worksheet) effectiveDim() *Dimension {
@@ -321,6 +810,7 @@ func growRow(row []Cell, col int) []Cell {
 // records the byte offset of the SHEETDATA payload start.
 func (ws *Worksheet) parse() error {
 	rdr := record.NewReader(bytes.NewReader(ws.data))
+	var breaks *[]PageBreak // collection the next BRK record belongs to
 	for {
 		recID, recData, err := rdr.Next()
 		if err == io.EOF {
@@ -343,6 +833,87 @@ func (ws *Worksheet) parse() error {
 				ws.Cols = append(ws.Cols, col)
 			}
 
+		case biff12.SheetPr:
+			p, err := parseSheetPrRecord(recData)
+			if err == nil {
+				ws.Properties = &p
+			}
+
+		case biff12.PageMargins:
+			m, err := parsePageMarginsRecord(recData)
+			if err == nil {
+				ws.PageMargins = &m
+			}
+
+		case biff12.PrintOptions:
+			o, err := parsePrintOptionsRecord(recData)
+			if err == nil {
+				ws.PrintOptions = &o
+			}
+
+		case biff12.PageSetup:
+			ps, err := parsePageSetupRecord(recData)
+			if err == nil {
+				ws.PageSetup = &ps
+			}
+
+		case biff12.HeaderFooter:
+			hf, err := parseHeaderFooterRecord(recData)
+			if err == nil {
+				ws.HeaderFooter = &hf
+			}
+
+		case biff12.RowBreaks:
+			breaks = &ws.breaks.Rows
+		case biff12.ColBreaks:
+			breaks = &ws.breaks.Cols
+		case biff12.RowBreaksEnd, biff12.ColBreaksEnd:
+			breaks = nil
+		case biff12.Break:
+			if breaks == nil {
+				continue
+			}
+			maxIdx, maxSpan := cellref.MaxRow, cellref.MaxCol
+			if breaks == &ws.breaks.Cols {
+				maxIdx, maxSpan = maxSpan, maxIdx
+			}
+			b, err := parseBreakRecord(recData, maxIdx, maxSpan)
+			if err == nil {
+				*breaks = append(*breaks, b)
+			}
+
+		case biff12.SheetView:
+			v, err := parseSheetViewRecord(recData)
+			if err == nil {
+				ws.Views = append(ws.Views, v)
+			}
+
+		case biff12.Pane:
+			// A PANE or SELECTION record belongs to the view that precedes it.
+			if len(ws.Views) == 0 {
+				continue
+			}
+			p, err := parsePaneRecord(recData)
+			if err == nil {
+				ws.Views[len(ws.Views)-1].Pane = &p
+			}
+
+		case biff12.Selection:
+			if len(ws.Views) == 0 {
+				continue
+			}
+			sel, err := parseSelectionRecord(recData)
+			if err == nil {
+				v := &ws.Views[len(ws.Views)-1]
+				v.Selections = append(v.Selections, sel)
+			}
+
+		case biff12.SheetFormatPr:
+			f, err := parseSheetFormatRecord(recData)
+			if err == nil {
+				ws.Format = &f
+			}
+
 		case biff12.SheetData:
 			// Record the position immediately after the SheetData marker so
 			// Rows() can seek back here quickly.
@@ -370,6 +941,18 @@ func (ws *Worksheet) parse() error {
 				}
 			}
 
+		case biff12.SheetProtection, biff12.SheetProtectionIso:
+			p, err := parseSheetProtectionRecord(recData, recID == biff12.SheetProtectionIso)
+			if err == nil {
+				ws.Protection = &p
+			}
+
+		case biff12.RangeProtection, biff12.RangeProtectionIso:
+			p, err := parseRangeProtectionRecord(recData, recID == biff12.RangeProtectionIso)
+			if err == nil {
+				ws.ProtectedRanges = append(ws.ProtectedRanges, p)
+			}
+
 		case biff12.MergeCell:
 			ma, err := parseMergeCellRecord(recData)
 			if err == nil {
@@ -430,13 +1013,11 @@ func parseDimensionRecord(data []byte) (Dimension, error) {
 	}
 	// Cap to Excel maxima to prevent OOM via makeEmptyRow.
 	// Max row index: 1,048,575 (0xFFFFF); max col index: 16,383 (0x3FFF).
-	const maxRow = 0xFFFFF
-	const maxCol = 0x3FFF
-	if r2 > maxRow {
-		return Dimension{}, fmt.Errorf("dimension: r2 (%d) exceeds Excel maximum row index %d", r2, maxRow)
+	if r2 > cellref.MaxRow {
+		return Dimension{}, fmt.Errorf("dimension: r2 (%d) exceeds Excel maximum row index %d", r2, cellref.MaxRow)
 	}
-	if c2 > maxCol {
-		return Dimension{}, fmt.Errorf("dimension: c2 (%d) exceeds Excel maximum column index %d", c2, maxCol)
+	if c2 > cellref.MaxCol {
+		return Dimension{}, fmt.Errorf("dimension: c2 (%d) exceeds Excel maximum column index %d", c2, cellref.MaxCol)
 	}
 	return Dimension{
 		R: int(r1),
@@ -446,12 +1027,16 @@ func parseDimensionRecord(data []byte) (Dimension, error) {
 	}, nil
 }
 
-// parseColRecord decodes a COL record.
+// parseColRecord decodes a COL record (MS-XLSB BrtColInfo).
 //
 //	c1    = read_int()
 //	c2    = read_int()
 //	width = read_int() / 256
 //	style = read_int()
+//	flags = read_uint16()  // bit 0 fHidden, 1 fUserSet, 2 fBestFit,
+//	                       // 3 fPhonetic, bits 8-10 iOutLevel, 12 fCollapsed
+//
+// A record that ends after style is accepted with all flags clear.
 func parseColRecord(data []byte) (Col, error) {
 	rr := record.NewRecordReader(data)
 	c1, err := rr.ReadUint32()
@@ -479,12 +1064,138 @@ func parseColRecord(data []byte) (Col, error) {
 	if style > maxStyleIndex {
 		styleIdx = 0
 	}
-	return Col{
+	col := Col{
 		C1:    int(c1),
 		C2:    int(c2),
 		Width: float64(widthRaw) / 256,
 		Style: styleIdx,
-	}, nil
+	}
+	if rr.Len() == 0 {
+		return col, nil
+	}
+	flags, err := rr.ReadUint16()
+	if err != nil {
+		return Col{}, err
+	}
+	col.Hidden = flags&0x0001 != 0
+	col.CustomWidth = flags&0x0002 != 0
+	col.BestFit = flags&0x0004 != 0
+	col.ShowPhonetic = flags&0x0008 != 0
+	col.OutlineLevel = int(flags >> 8 & 0x07)
+	col.Collapsed = flags&0x1000 != 0
+	return col, nil
+}
+
+// parseSheetPrRecord decodes a SHEETPR record (MS-XLSB BrtWsProp).
+//
+//	flags    = 3 bytes        // bit 0 fShowAutoBreaks, 3 fPublish, 4 fDialog,
+//	                          // 5 fApplyStyles, 6 fRowSumsBelow,
+//	                          // 7 fColSumsRight, 8 fFitToPage,
+//	                          // 10 fShowOutlineSymbols, 12 fSyncHoriz,
+//	                          // 13 fSyncVert, 14 fAltExprEval,
+//	                          // 15 fAltFormulaEntry, 16 fFilterMode,
+//	                          // 17 fCondFmtCalc
+//	tabColor = Color (8 bytes)
+//	rwSync   = read_uint32()
+//	colSync  = read_uint32()
+//	codeName = read_string()
+//
+// The fields are read by sheetpr.Parse, which the workbook also uses to
+// find code names.
+func parseSheetPrRecord(data []byte) (SheetProperties, error) {
+	rec, err := sheetpr.Parse(data)
+	if err != nil {
+		return SheetProperties{}, err
+	}
+	flags, tab := rec.Flags, rec.TabColor
+	p := SheetProperties{
+		CodeName:             rec.CodeName,
+		ShowAutoPageBreaks:   flags&(1<<0) != 0,
+		Published:            flags&(1<<3) != 0,
+		Dialog:               flags&(1<<4) != 0,
+		ApplyStyles:          flags&(1<<5) != 0,
+		SummaryBelow:         flags&(1<<6) != 0,
+		SummaryRight:         flags&(1<<7) != 0,
+		FitToPage:            flags&(1<<8) != 0,
+		ShowOutlineSymbols:   flags&(1<<10) != 0,
+		SyncHorizontal:       flags&(1<<12) != 0,
+		SyncVertical:         flags&(1<<13) != 0,
+		TransitionEvaluation: flags&(1<<14) != 0,
+		TransitionEntry:      flags&(1<<15) != 0,
+		FilterMode:           flags&(1<<16) != 0,
+		CondFmtCalc:          flags&(1<<17) != 0,
+	}
+	// rwSync/colSync are 0xFFFFFFFF when scrolling is not synchronised.
+	if rec.RwSync <= cellref.MaxRow {
+		p.SyncRow = int(rec.RwSync)
+	}
+	if rec.ColSync <= cellref.MaxCol {
+		p.SyncCol = int(rec.ColSync)
+	}
+	if tab.Type != styles.ColorAuto {
+		p.TabColor = &tab
+	}
+	return p, nil
+}
+
+// parseSheetFormatRecord decodes a SHEETFORMATPR record (MS-XLSB
+// BrtWsFmtInfo).
+//
+//	dxGCol         = read_uint32()  // default width in 1/256 characters; 0xFFFFFFFF if unset
+//	cchDefColWidth = read_uint16()  // base column width in characters
+//	miyDefRwHeight = read_uint16()  // default row height in twips
+//	flags          = read_uint16()  // bit 0 fUnsynced, 1 fDyZero, 2 fExAsc, 3 fExDsc
+//	iOutLevelRw    = read_uint8()
+//	iOutLevelCol   = read_uint8()
+//
+// When dxGCol is unset the default width is derived from the base width as
+// Excel does, adding 5 pixels of padding and assuming a maximum digit width
+// of 7 pixels (Calibri 11, the default Normal font).
+func parseSheetFormatRecord(data []byte) (SheetFormat, error) {
+	rr := record.NewRecordReader(data)
+	dx, err := rr.ReadUint32()
+	if err != nil {
+		return SheetFormat{}, err
+	}
+	base, err := rr.ReadUint16()
+	if err != nil {
+		return SheetFormat{}, err
+	}
+	height, err := rr.ReadUint16()
+	if err != nil {
+		return SheetFormat{}, err
+	}
+	flags, err := rr.ReadUint16()
+	if err != nil {
+		return SheetFormat{}, err
+	}
+	var levels [2]byte
+	if err := rr.Read(levels[:]); err != nil {
+		return SheetFormat{}, err
+	}
+	f := SheetFormat{
+		BaseColWidth:     int(base),
+		DefaultRowHeight: float64(height) / 20,
+		CustomHeight:     flags&0x0001 != 0,
+		ZeroHeight:       flags&0x0002 != 0,
+		ThickTop:         flags&0x0004 != 0,
+		ThickBottom:      flags&0x0008 != 0,
+		OutlineLevelRow:  int(levels[0] & 0x07),
+		OutlineLevelCol:  int(levels[1] & 0x07),
+	}
+	if dx != 0xFFFFFFFF {
+		f.DefaultColWidth = float64(dx) / 256
+	} else {
+		f.DefaultColWidth = baseToColWidth(f.BaseColWidth)
+	}
+	return f, nil
+}
+
+// baseToColWidth converts a base column width in characters to a column
+// width, truncated to 1/256 of a character: base + 5px / 7px.
+func baseToColWidth(base int) float64 {
+	const mdw = 7 // maximum digit width of Calibri 11, in pixels
+	return float64(int((float64(base*mdw+5)/mdw)*256)) / 256
 }
 
 // parseRowRecord decodes a ROW record and returns the row index (0-based).
@@ -506,26 +1217,82 @@ func parseRowRecord(data []byte) (int, error) {
 	return int(r), nil
 }
 
-// errStrings maps BIFF12 BErr byte codes (MS-XLSB §2.5.97.2) to the
-// corresponding Excel error string displayed in a cell.
-var errStrings = map[byte]string{
-	0x00: "#NULL!",
-	0x07: "#DIV/0!",
-	0x0F: "#VALUE!",
-	0x17: "#REF!",
-	0x1D: "#NAME?",
-	0x24: "#NUM!",
-	0x2A: "#N/A",
-	0x2B: "#GETTING_DATA",
-}
-
-// errString returns the Excel error string for the given BErr byte code, or a
-// hex fallback (e.g. "0xff") for unknown codes.
-func errString(b byte) string {
-	if s, ok := errStrings[b]; ok {
-		return s
+// parseRowInfo decodes a ROW record (MS-XLSB BrtRowHdr) in full.
+//
+//	rw       = read_uint32()  // row index (0-based)
+//	ixfe     = read_uint32()  // XF index
+//	miyRw    = read_uint16()  // height in twips (1/20 pt)
+//	flags1   = read_uint8()   // bit 0 fExtraAsc, bit 1 fExtraDsc
+//	flags2   = read_uint8()   // bits 0-2 iOutLevel, 3 fCollapsed, 4 fDyZero,
+//	                          // 5 fUnsynced, 6 fGhostDirty
+//	flags3   = read_uint8()   // bit 0 fPhShow
+//	ccolspan = read_uint32()  // number of BrtColSpan entries (≤ 16)
+//	rgBrtColspan = ccolspan × (colMic uint32, colLast uint32)
+//
+// A record that ends after rw is accepted and yields only the row index.
+func parseRowInfo(data []byte) (RowInfo, error) {
+	r, err := parseRowRecord(data)
+	if err != nil {
+		return RowInfo{}, err
 	}
-	return fmt.Sprintf("0x%02x", b)
+	info := RowInfo{R: r}
+	rr := record.NewRecordReader(data)
+	if err := rr.Skip(4); err != nil || rr.Len() == 0 {
+		return info, nil
+	}
+	ixfe, err := rr.ReadUint32()
+	if err != nil {
+		return RowInfo{}, err
+	}
+	miyRw, err := rr.ReadUint16()
+	if err != nil {
+		return RowInfo{}, err
+	}
+	var flags [3]byte
+	if err := rr.Read(flags[:]); err != nil {
+		return RowInfo{}, err
+	}
+	// Guard: cap to MaxInt32 so int(ixfe) is identical on 32- and 64-bit,
+	// as parseColRecord and parseCellRecord do.
+	const maxStyleIndex = 0x7FFFFFFF
+	info.Style = int(ixfe)
+	if ixfe > maxStyleIndex {
+		info.Style = 0
+	}
+	info.Height = float64(miyRw) / 20
+	info.ThickTop = flags[0]&0x01 != 0
+	info.ThickBottom = flags[0]&0x02 != 0
+	info.OutlineLevel = int(flags[1] & 0x07)
+	info.Collapsed = flags[1]&0x08 != 0
+	info.Hidden = flags[1]&0x10 != 0
+	info.CustomHeight = flags[1]&0x20 != 0
+	info.CustomStyle = flags[1]&0x40 != 0
+	info.ShowPhonetic = flags[2]&0x01 != 0
+	if rr.Len() == 0 {
+		return info, nil
+	}
+	n, err := rr.ReadUint32()
+	if err != nil {
+		return RowInfo{}, err
+	}
+	// Each span is 8 bytes; reject counts the payload cannot hold.
+	if int64(n)*8 > int64(rr.Len()) {
+		return RowInfo{}, fmt.Errorf("worksheet: ROW record declares %d column spans in %d bytes", n, rr.Len())
+	}
+	for range n {
+		c1, err := rr.ReadUint32()
+		if err != nil {
+			return RowInfo{}, err
+		}
+		c2, err := rr.ReadUint32()
+		if err != nil {
+			return RowInfo{}, err
+		}
+		if c1 <= c2 && c2 <= cellref.MaxCol {
+			info.Spans = append(info.Spans, [2]int{int(c1), int(c2)})
+		}
+	}
+	return info, nil
 }
 
 // internalCell is used only during parsing.
@@ -533,16 +1300,30 @@ type internalCell struct {
 	C     int
 	V     any
 	Style int
+	// rgce and extra hold the raw formula token stream for formula cells;
+	// rgce is nil for all other cell types.
+	rgce, extra []byte
+	// rich holds the runs / phonetic data of a rich-text string cell; nil
+	// otherwise.
+	rich *stringtable.RichString
+	// err is the decode failure of a truncated or malformed value.
+	err error
+	// kind is derived from the record ID.
+	kind CellKind
 }
 
 // parseCellRecord decodes a cell record (BLANK, NUM, BOOLERR, BOOL, FLOAT,
-// STRING, FORMULA_STRING, FORMULA_FLOAT, FORMULA_BOOL, FORMULA_BOOLERR).
+// CELL_ST, STRING, FORMULA_STRING, FORMULA_FLOAT, FORMULA_BOOL,
+// FORMULA_BOOLERR, CELL_RSTRING).
 //
 // Python layout:
 //
 //	col   = read_int()
 //	style = read_int()
 //	... type-specific value ...
+//
+// Formula records continue after the cached value with a uint16 grbitFlags
+// field and a CellParsedFormula (see formula.Read).
 func parseCellRecord(data []byte, recID int, st *stringtable.StringTable) (internalCell, error) {
 	rr := record.NewRecordReader(data)
 	col, err := rr.ReadUint32()
@@ -551,7 +1332,7 @@ func parseCellRecord(data []byte, recID int, st *stringtable.StringTable) (inter
 	}
 	styleRaw, err := rr.ReadUint32()
 	if err != nil {
-		return internalCell{C: int(col)}, nil
+		return internalCell{C: int(col), kind: cellKinds[recID]}, nil
 	}
 	// Guard: cap to MaxInt32 so int(styleRaw) is identical on 32- and 64-bit.
 	const maxStyleIndex = 0x7FFFFFFF
@@ -560,47 +1341,68 @@ func parseCellRecord(data []byte, recID int, st *stringtable.StringTable) (inter
 		style = 0
 	}
 
-	// cellParseErr is the sentinel value placed in Cell.V when the record's
-	// value bytes are present but cannot be decoded (truncated or malformed
-	// data).  Using a visible error string rather than nil means the cell will
-	// never be silently indistinguishable from a genuinely blank (Blank record)
-	// cell — the caller can see that something went wrong.
-	const cellParseErr = "#VALUE!"
+	// cellParseErr is the value placed in Cell.V when the record's value
+	// bytes cannot be decoded (truncated or malformed data); the failure
+	// itself goes to Cell.Err.  Using an error value rather than nil means the
+	// cell is never silently indistinguishable from a genuinely blank (Blank
+	// record) cell.
+	const cellParseErr = ErrValue
 
 	var v any
+	var decodeErr error
+	var rgce, extra []byte
+	var rich *stringtable.RichString
 	switch recID {
 	case biff12.Num:
 		f, err := rr.ReadFloat()
 		if err != nil {
-			v = cellParseErr
+			v, decodeErr = cellParseErr, err
 			break
 		}
 		v = f
 	case biff12.BoolErr:
 		b, err := rr.ReadUint8()
 		if err != nil {
-			v = cellParseErr
+			v, decodeErr = cellParseErr, err
 			break
 		}
-		v = errString(b)
+		v = CellError(b)
 	case biff12.Bool:
 		b, err := rr.ReadUint8()
 		if err != nil {
-			v = cellParseErr
+			v, decodeErr = cellParseErr, err
 			break
 		}
 		v = b != 0
 	case biff12.Float:
 		f, err := rr.ReadDouble()
 		if err != nil {
-			v = cellParseErr
+			v, decodeErr = cellParseErr, err
 			break
 		}
 		v = f
+	case biff12.CellSt:
+		s, err := rr.ReadString()
+		if err != nil {
+			v, decodeErr = cellParseErr, err
+			break
+		}
+		v = s
+	case biff12.CellRString:
+		rs, err := stringtable.ReadRichString(rr)
+		if err != nil && rs.Text == "" {
+			v, decodeErr = cellParseErr, err
+			break
+		}
+		// A truncated run array still leaves the text usable.
+		v = rs.Text
+		if len(rs.Runs) > 0 || rs.Phonetic != nil {
+			rich = &rs
+		}
 	case biff12.String:
 		idx, err := rr.ReadUint32()
 		if err != nil {
-			v = cellParseErr
+			v, decodeErr = cellParseErr, err
 			break
 		}
 		// Use uint32 comparison to stay safe on 32-bit platforms where
@@ -608,41 +1410,99 @@ func parseCellRecord(data []byte, recID int, st *stringtable.StringTable) (inter
 		// true and causing st.Get(-1) to panic.
 		if st != nil && idx < uint32(st.Len()) {
 			v = st.Get(int(idx))
+			rich = st.GetRich(int(idx))
 		} else {
 			v = fmt.Sprintf("<%d>", idx) // fallback if no string table
 		}
 	case biff12.FormulaString:
 		s, err := rr.ReadString()
 		if err != nil {
-			v = cellParseErr
+			v, decodeErr = cellParseErr, err
 			break
 		}
 		v = s
+		rgce, extra = readCellFormula(rr)
 	case biff12.FormulaFloat:
 		f, err := rr.ReadDouble()
 		if err != nil {
-			v = cellParseErr
+			v, decodeErr = cellParseErr, err
 			break
 		}
 		v = f
+		rgce, extra = readCellFormula(rr)
 	case biff12.FormulaBool:
 		b, err := rr.ReadUint8()
 		if err != nil {
-			v = cellParseErr
+			v, decodeErr = cellParseErr, err
 			break
 		}
 		v = b != 0
+		rgce, extra = readCellFormula(rr)
 	case biff12.FormulaBoolErr:
 		b, err := rr.ReadUint8()
 		if err != nil {
-			v = cellParseErr
+			v, decodeErr = cellParseErr, err
 			break
 		}
-		v = errString(b)
+		v = CellError(b)
+		rgce, extra = readCellFormula(rr)
 		// biff12.Blank: v remains nil
 	}
 
-	return internalCell{C: int(col), V: v, Style: style}, nil
+	return internalCell{C: int(col), V: v, Style: style, rgce: rgce, extra: extra, rich: rich, err: decodeErr, kind: cellKinds[recID]}, nil
+}
+
+// readCellFormula reads the grbitFlags field and the CellParsedFormula that
+// follow the cached value of a formula cell record.  It returns nil slices
+// when the formula is absent or truncated; the cached value is still usable.
+func readCellFormula(rr *record.RecordReader) (rgce, extra []byte) {
+	if err := rr.Skip(2); err != nil { // grbitFlags (fAlwaysCalc etc.)
+		return nil, nil
+	}
+	rgce, extra, err := formula.Read(rr)
+	if err != nil {
+		return nil, nil
+	}
+	return rgce, extra
+}
+
+// parseSharedFormulaRecord decodes a BrtShrFmla or BrtArrFmla record.
+//
+//	rwFirst  uint32
+//	rwLast   uint32
+//	colFirst uint32
+//	colLast  uint32
+//	flags    uint8    — BrtArrFmla only (fAlwaysCalc)
+//	formula  SharedParsedFormula / ArrayParsedFormula (see formula.Read)
+func parseSharedFormulaRecord(data []byte, array bool) (sharedFormula, error) {
+	rr := record.NewRecordReader(data)
+	var f [4]uint32
+	for i := range f {
+		v, err := rr.ReadUint32()
+		if err != nil {
+			return sharedFormula{}, err
+		}
+		f[i] = v
+	}
+	if f[1] < f[0] || f[3] < f[2] {
+		return sharedFormula{}, fmt.Errorf("shared formula: inverted range")
+	}
+	if f[1] > cellref.MaxRow || f[3] > cellref.MaxCol {
+		return sharedFormula{}, fmt.Errorf("shared formula: range exceeds Excel maxima")
+	}
+	if array {
+		if err := rr.Skip(1); err != nil {
+			return sharedFormula{}, err
+		}
+	}
+	rgce, extra, err := formula.Read(rr)
+	if err != nil {
+		return sharedFormula{}, err
+	}
+	return sharedFormula{
+		r1: int(f[0]), r2: int(f[1]), c1: int(f[2]), c2: int(f[3]),
+		array: array, rgce: rgce, extra: extra,
+	}, nil
 }
 
 // parseMergeCellRecord decodes a MERGE_CELL record.
@@ -679,13 +1539,11 @@ func parseMergeCellRecord(data []byte) (MergeArea, error) {
 	}
 	// Cap to Excel maxima to prevent corrupt records producing enormous H/W
 	// values that could cause problems in downstream range iterations.
-	const maxRow = 0xFFFFF
-	const maxCol = 0x3FFF
-	if r2 > maxRow {
-		return MergeArea{}, fmt.Errorf("mergecell: r2 (%d) exceeds Excel maximum row index %d", r2, maxRow)
+	if r2 > cellref.MaxRow {
+		return MergeArea{}, fmt.Errorf("mergecell: r2 (%d) exceeds Excel maximum row index %d", r2, cellref.MaxRow)
 	}
-	if c2 > maxCol {
-		return MergeArea{}, fmt.Errorf("mergecell: c2 (%d) exceeds Excel maximum column index %d", c2, maxCol)
+	if c2 > cellref.MaxCol {
+		return MergeArea{}, fmt.Errorf("mergecell: c2 (%d) exceeds Excel maximum column index %d", c2, cellref.MaxCol)
 	}
 	return MergeArea{
 		R: int(r1),
@@ -740,13 +1598,11 @@ func parseHyperlinkRecord(data []byte) (hyperlinkRecord, error) {
 		return hyperlinkRecord{}, fmt.Errorf("hyperlink: c2 (%d) < c1 (%d)", c2, c1)
 	}
 	// Cap to Excel maxima.
-	const maxRow = 0xFFFFF
-	const maxCol = 0x3FFF
-	if r2 > maxRow {
-		return hyperlinkRecord{}, fmt.Errorf("hyperlink: r2 (%d) exceeds Excel maximum row index %d", r2, maxRow)
+	if r2 > cellref.MaxRow {
+		return hyperlinkRecord{}, fmt.Errorf("hyperlink: r2 (%d) exceeds Excel maximum row index %d", r2, cellref.MaxRow)
 	}
-	if c2 > maxCol {
-		return hyperlinkRecord{}, fmt.Errorf("hyperlink: c2 (%d) exceeds Excel maximum column index %d", c2, maxCol)
+	if c2 > cellref.MaxCol {
+		return hyperlinkRecord{}, fmt.Errorf("hyperlink: c2 (%d) exceeds Excel maximum column index %d", c2, cellref.MaxCol)
 	}
 	return hyperlinkRecord{
 		R:   int(r1),
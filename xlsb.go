@@ -18,9 +18,20 @@
 //	    }
 //	}
 //
+// # Encrypted workbooks
+//
+// Workbooks protected with an open password are stored as an encrypted
+// package inside an OLE compound file.  [OpenWithPassword], or [Open] and
+// [OpenReader] with [workbook.WithPassword], decrypt them in memory:
+//
+//	wb, err := xlsb.OpenWithPassword("Vendor.xlsb", password)
+//
+// Agile (AES with SHA-1/SHA-2) and Standard (AES) encryption are supported.
+//
 // # Cell formatting
 //
-// [worksheet.Worksheet.Rows] always returns raw values (nil, string, float64, or bool).  To obtain
+// [worksheet.Worksheet.Rows] always returns raw values (nil, string, float64,
+// bool, or [worksheet.CellError] for error cells such as #DIV/0!).  To obtain
 // the display string that Excel would show — respecting number formats, date
 // formats, custom formats, and so on — call [workbook.Workbook.FormatCell]:
 //
@@ -73,14 +84,51 @@ const Version = "1.1.1"
 
 // Open opens the named .xlsb file.  The caller must call Close on the returned
 // Workbook when done.
-func Open(name string) (*workbook.Workbook, error) {
-	return workbook.Open(name)
+func Open(name string, opts ...workbook.Option) (*workbook.Workbook, error) {
+	return workbook.Open(name, opts...)
+}
+
+// OpenWithPassword opens the named .xlsb file encrypted with the given open
+// password.  Unencrypted files open as with [Open]; the password is ignored.
+func OpenWithPassword(name, password string) (*workbook.Workbook, error) {
+	return workbook.Open(name, workbook.WithPassword(password))
 }
 
 // OpenReader reads an .xlsb workbook from an arbitrary [io.ReaderAt].
-// size must equal the total byte length of the data.
-func OpenReader(r io.ReaderAt, size int64) (*workbook.Workbook, error) {
-	return workbook.OpenReader(r, size)
+// size must equal the total byte length of the data.  Pass
+// [workbook.WithPassword] to read an encrypted workbook.
+func OpenReader(r io.ReaderAt, size int64, opts ...workbook.Option) (*workbook.Workbook, error) {
+	return workbook.OpenReader(r, size, opts...)
+}
+
+// Errors returned by [Open] and [OpenReader]; see [workbook.ErrNotXLSB],
+// [workbook.ErrEncrypted] and [workbook.ErrWrongPassword].  Test for them
+// with [errors.Is].
+var (
+	ErrNotXLSB       = workbook.ErrNotXLSB
+	ErrEncrypted     = workbook.ErrEncrypted
+	ErrWrongPassword = workbook.ErrWrongPassword
+)
+
+// Format is the kind of file identified by [Detect].
+type Format = workbook.Format
+
+// Formats reported by [Detect].
+const (
+	FormatUnknown   = workbook.FormatUnknown
+	FormatXLSB      = workbook.FormatXLSB
+	FormatXLSX      = workbook.FormatXLSX
+	FormatXLS       = workbook.FormatXLS
+	FormatEncrypted = workbook.FormatEncrypted
+	FormatZIP       = workbook.FormatZIP
+)
+
+// Detect identifies the format of the file in r — .xlsb, .xlsx/.xlsm,
+// legacy .xls, an encrypted OOXML package, another ZIP archive, or unknown
+// data — without parsing the workbook.  size is the total byte size of the
+// data.  The error is non-nil only when r cannot be read.
+func Detect(r io.ReaderAt, size int64) (Format, error) {
+	return workbook.Detect(r, size)
 }
 
 // ConvertDate converts an Excel date serial number to a [time.Time] value.
@@ -8,14 +8,32 @@ package xlsb_test
 import (
 	"archive/zip"
 	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha1"
+	"crypto/sha512"
+	"encoding/base64"
 	"encoding/binary"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"hash"
+	"io"
 	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
+	"unicode/utf16"
 
 	"github.com/TsubasaBE/go-xlsb"
 	"github.com/TsubasaBE/go-xlsb/biff12"
+	"github.com/TsubasaBE/go-xlsb/cellref"
+	"github.com/TsubasaBE/go-xlsb/formula"
+	"github.com/TsubasaBE/go-xlsb/internal/cfb"
+	"github.com/TsubasaBE/go-xlsb/internal/offcrypto"
 	"github.com/TsubasaBE/go-xlsb/numfmt"
 	"github.com/TsubasaBE/go-xlsb/record"
 	"github.com/TsubasaBE/go-xlsb/stringtable"
@@ -1507,23 +1525,182 @@ func TestErrorCellStrings(t *testing.T) {
 				t.Fatal("no rows returned")
 			}
 
-			// col 0: BoolErr
-			if got, ok := row[0].V.(string); !ok {
-				t.Errorf("BoolErr V type = %T, want string", row[0].V)
-			} else if got != tc.want {
-				t.Errorf("BoolErr V = %q, want %q", got, tc.want)
-			}
-
-			// col 1: FormulaBoolErr
-			if got, ok := row[1].V.(string); !ok {
-				t.Errorf("FormulaBoolErr V type = %T, want string", row[1].V)
-			} else if got != tc.want {
-				t.Errorf("FormulaBoolErr V = %q, want %q", got, tc.want)
+			// col 0: BoolErr, col 1: FormulaBoolErr
+			for i, kind := range []string{"BoolErr", "FormulaBoolErr"} {
+				got, ok := row[i].V.(worksheet.CellError)
+				if !ok {
+					t.Errorf("%s V type = %T, want worksheet.CellError", kind, row[i].V)
+					continue
+				}
+				if got.Code() != tc.errCode || got.String() != tc.want || got.Error() != tc.want {
+					t.Errorf("%s V = %v (code %#x), want %q", kind, got, got.Code(), tc.want)
+				}
+				if row[i].Err != nil {
+					t.Errorf("%s Err = %v, want nil", kind, row[i].Err)
+				}
+				if s := wb.FormatCell(row[i].V, 0); s != tc.want {
+					t.Errorf("%s FormatCell = %q, want %q", kind, s, tc.want)
+				}
 			}
 		})
 	}
 }
 
+// TestCellError covers the typed error value: text cells spelling an error
+// stay strings, clean cells have no Err, and numfmt renders CellError
+// regardless of the format code.
+func TestCellError(t *testing.T) {
+	cellHdr := func(col uint32) []byte {
+		return concatBytes(biff12Le32(col), biff12Le32(0))
+	}
+	var ws bytes.Buffer
+	biff12WriteRec(&ws, 0x0181, nil) // WORKSHEET start
+	biff12WriteRec(&ws, 0x0191, nil) // SHEETDATA start
+	biff12WriteRec(&ws, 0x0000, biff12Le32(0))
+	biff12WriteRec(&ws, 0x0006, concatBytes(cellHdr(0), biff12EncStr("#N/A"))) // BrtCellSt
+	biff12WriteRec(&ws, 0x0003, concatBytes(cellHdr(1), []byte{0x2A}))         // BrtCellError #N/A
+	biff12WriteRec(&ws, 0x0005, concatBytes(cellHdr(2), make([]byte, 8)))      // BrtCellReal 0
+	biff12WriteRec(&ws, 0x0192, nil)                                           // SHEETDATA end
+	biff12WriteRec(&ws, 0x0182, nil)                                           // WORKSHEET end
+
+	data := buildSheetXLSB(t, ws.Bytes(), nil)
+	wb, err := workbook.OpenReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("OpenReader: %v", err)
+	}
+	defer wb.Close()
+	sheet, err := wb.Sheet(1)
+	if err != nil {
+		t.Fatalf("Sheet(1): %v", err)
+	}
+	row, err := sheet.Row(0)
+	if err != nil {
+		t.Fatalf("Row(0): %v", err)
+	}
+	if len(row) < 3 {
+		t.Fatalf("row has %d cells, want 3", len(row))
+	}
+	if row[0].V != "#N/A" {
+		t.Errorf("text cell V = %v (%T), want string %q", row[0].V, row[0].V, "#N/A")
+	}
+	if row[1].V != worksheet.ErrNA {
+		t.Errorf("error cell V = %v (%T), want ErrNA", row[1].V, row[1].V)
+	}
+	if row[0].V == row[1].V {
+		t.Error("text #N/A and error #N/A compare equal")
+	}
+	for i, c := range row {
+		if c.Err != nil {
+			t.Errorf("cell[%d].Err = %v, want nil", i, c.Err)
+		}
+	}
+
+	// CellError satisfies error, so it can be wrapped and matched.
+	var e error = worksheet.ErrDiv0
+	if !errors.Is(fmt.Errorf("sum: %w", e), formula.ErrDiv0) {
+		t.Error("errors.Is does not match a wrapped ErrDiv0")
+	}
+	if got := numfmt.FormatValue(worksheet.ErrDiv0, 0, "0.00", false); got != "#DIV/0!" {
+		t.Errorf("FormatValue(ErrDiv0) = %q, want %q", got, "#DIV/0!")
+	}
+	if got := numfmt.FormatValue(worksheet.ErrRef, 14, "", false); got != "#REF!" {
+		t.Errorf("FormatValue(ErrRef, date) = %q, want %q", got, "#REF!")
+	}
+	if got := worksheet.CellError(0x01).String(); got != "0x01" {
+		t.Errorf("unknown code String() = %q, want %q", got, "0x01")
+	}
+}
+
+// TestCellKind checks that every cell record type sets Cell.Kind, and that a
+// position without a record reports KindNone.
+func TestCellKind(t *testing.T) {
+	cellHdr := func(col uint32) []byte {
+		return concatBytes(biff12Le32(col), biff12Le32(0))
+	}
+	one := biff12Le32(0x3FF00000) // RK 1.0
+	dbl := make([]byte, 8)
+	noFmla := concatBytes(biff12Le16(0), biff12Le32(0), biff12Le32(0)) // grbit, cce, cb
+	recs := []struct {
+		id      int
+		payload []byte
+		want    worksheet.CellKind
+	}{
+		{biff12.Blank, nil, worksheet.KindBlank},
+		{biff12.Num, one, worksheet.KindRK},
+		{biff12.Float, dbl, worksheet.KindNumber},
+		{biff12.Bool, []byte{1}, worksheet.KindBool},
+		{biff12.BoolErr, []byte{0x07}, worksheet.KindError},
+		{biff12.String, biff12Le32(0), worksheet.KindSharedString},
+		{biff12.CellSt, biff12EncStr("x"), worksheet.KindInlineString},
+		{biff12.CellRString, concatBytes([]byte{0}, biff12EncStr("y")), worksheet.KindRichString},
+		{biff12.FormulaFloat, concatBytes(dbl, noFmla), worksheet.KindFormulaNumber},
+		{biff12.FormulaString, concatBytes(biff12EncStr("z"), noFmla), worksheet.KindFormulaString},
+		{biff12.FormulaBool, concatBytes([]byte{0}, noFmla), worksheet.KindFormulaBool},
+		{biff12.FormulaBoolErr, concatBytes([]byte{0x2A}, noFmla), worksheet.KindFormulaError},
+	}
+	var ws bytes.Buffer
+	biff12WriteRec(&ws, 0x0181, nil) // WORKSHEET start
+	biff12WriteRec(&ws, 0x0191, nil) // SHEETDATA start
+	biff12WriteRec(&ws, 0x0000, biff12Le32(0))
+	for i, r := range recs {
+		biff12WriteRec(&ws, r.id, concatBytes(cellHdr(uint32(i)), r.payload))
+	}
+	// Column len(recs) is left without a record; a truncated number follows.
+	biff12WriteRec(&ws, biff12.Float, cellHdr(uint32(len(recs)+1)))
+	biff12WriteRec(&ws, 0x0192, nil) // SHEETDATA end
+	biff12WriteRec(&ws, 0x0182, nil) // WORKSHEET end
+
+	data := buildSheetXLSB(t, ws.Bytes(), nil)
+	wb, err := workbook.OpenReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("OpenReader: %v", err)
+	}
+	defer wb.Close()
+	sheet, err := wb.Sheet(1)
+	if err != nil {
+		t.Fatalf("Sheet(1): %v", err)
+	}
+	row, err := sheet.Row(0)
+	if err != nil {
+		t.Fatalf("Row(0): %v", err)
+	}
+	if len(row) != len(recs)+2 {
+		t.Fatalf("row has %d cells, want %d", len(row), len(recs)+2)
+	}
+	for i, r := range recs {
+		got := row[i].Kind
+		if got != r.want {
+			t.Errorf("record 0x%04X: Kind = %v, want %v", r.id, got, r.want)
+		}
+		isFmla := r.id >= biff12.FormulaString && r.id <= biff12.FormulaBoolErr
+		if got.IsFormula() != isFmla {
+			t.Errorf("record 0x%04X: IsFormula = %v, want %v", r.id, got.IsFormula(), isFmla)
+		}
+	}
+	if got := row[len(recs)].Kind; got != worksheet.KindNone {
+		t.Errorf("gap cell Kind = %v, want KindNone", got)
+	}
+	if c := row[len(recs)+1]; c.Kind != worksheet.KindNumber || c.Err == nil {
+		t.Errorf("truncated cell Kind = %v, Err = %v; want number with an error", c.Kind, c.Err)
+	}
+	if !row[1].Kind.IsNumber() || !row[2].Kind.IsNumber() || row[8].Kind.IsNumber() {
+		t.Error("IsNumber does not match RK and double constants only")
+	}
+	if !row[5].Kind.IsString() || !row[7].Kind.IsString() || row[9].Kind.IsString() {
+		t.Error("IsString does not match string constants only")
+	}
+	if s := worksheet.KindFormulaNumber.String(); s != "formula-number" {
+		t.Errorf("String() = %q, want %q", s, "formula-number")
+	}
+	if s := worksheet.CellKind(99).String(); s != "unknown" {
+		t.Errorf("String() of out-of-range kind = %q, want %q", s, "unknown")
+	}
+	c, err := sheet.Cell(0, 8)
+	if err != nil || c.Kind != worksheet.KindFormulaNumber {
+		t.Errorf("Cell(0, 8).Kind = %v (err %v), want KindFormulaNumber", c.Kind, err)
+	}
+}
+
 // ── SheetVisibility ───────────────────────────────────────────────────────────
 
 // buildVisibilityXLSB constructs a minimal .xlsb with three sheets whose
@@ -3077,10 +3254,11 @@ func collectCells(t *testing.T, data []byte) (cells []worksheet.Cell, wsErr erro
 
 // TestCellTruncatedValueBytesSentinel verifies Fix 1: when a non-Blank cell
 // record's value bytes are truncated (only col+style present, no value field),
-// parseCellRecord must set V = "#VALUE!" rather than leaving it nil.
+// parseCellRecord must set V = ErrValue and Cell.Err rather than leaving V nil.
 //
 // This guarantees that a cell with real data can never be silently
-// indistinguishable from a genuinely empty (Blank) cell.
+// indistinguishable from a genuinely empty (Blank) cell, nor a corrupt record
+// from a cell that really holds #VALUE!.
 func TestCellTruncatedValueBytesSentinel(t *testing.T) {
 	t.Parallel()
 
@@ -3108,10 +3286,13 @@ func TestCellTruncatedValueBytesSentinel(t *testing.T) {
 			data := buildXLSBWithRawCellPayload(t, tc.recID, truncatedPayload)
 			cells, _ := collectCells(t, data)
 			if len(cells) == 0 {
-				t.Fatalf("recID 0x%04X: cell was silently dropped; want V=%q", tc.recID, "#VALUE!")
+				t.Fatalf("recID 0x%04X: cell was silently dropped; want V=#VALUE!", tc.recID)
 			}
-			if got := cells[0].V; got != "#VALUE!" {
-				t.Errorf("recID 0x%04X: V = %v (%T), want %q", tc.recID, got, got, "#VALUE!")
+			if got := cells[0].V; got != worksheet.ErrValue {
+				t.Errorf("recID 0x%04X: V = %v (%T), want ErrValue", tc.recID, got, got)
+			}
+			if cells[0].Err == nil || !strings.Contains(cells[0].Err.Error(), "A1") {
+				t.Errorf("recID 0x%04X: Err = %v, want a decode error for A1", tc.recID, cells[0].Err)
 			}
 		})
 	}
@@ -3303,3 +3484,2682 @@ func TestRowMalformedRecordSetsErr(t *testing.T) {
 		t.Error("valid cell (V=7.0) on row 1 was not yielded after malformed ROW record")
 	}
 }
+
+// ── Formula ───────────────────────────────────────────────────────────────────
+
+// stubFormulaContext is a formula.Context backed by fixed tables.
+type stubFormulaContext struct {
+	externs []formula.Extern
+	names   []string
+}
+
+func (c stubFormulaContext) Extern(ixti int) (formula.Extern, bool) {
+	if ixti < 0 || ixti >= len(c.externs) {
+		return formula.Extern{}, false
+	}
+	return c.externs[ixti], true
+}
+
+func (c stubFormulaContext) Name(idx int) (string, bool) {
+	if idx < 1 || idx > len(c.names) {
+		return "", false
+	}
+	return c.names[idx-1], true
+}
+
+// ptgRef encodes a PtgRef token; rowRel/colRel set the relative-flag bits.
+func ptgRef(ptg byte, row uint32, col uint16, rowRel, colRel bool) []byte {
+	if colRel {
+		col |= 0x4000
+	}
+	if rowRel {
+		col |= 0x8000
+	}
+	b := []byte{ptg}
+	b = append(b, biff12Le32(row)...)
+	return append(b, biff12Le16(col)...)
+}
+
+// ptgArea encodes a PtgArea token with all four components relative.
+func ptgArea(ptg byte, r1, r2 uint32, c1, c2 uint16) []byte {
+	b := []byte{ptg}
+	b = append(b, biff12Le32(r1)...)
+	b = append(b, biff12Le32(r2)...)
+	b = append(b, biff12Le16(c1|0xC000)...)
+	return append(b, biff12Le16(c2|0xC000)...)
+}
+
+// ptgStr encodes a PtgStr token.
+func ptgStr(s string) []byte {
+	b := []byte{0x17}
+	b = append(b, biff12Le16(uint16(len(s)))...)
+	for _, r := range s {
+		b = append(b, biff12Le16(uint16(r))...)
+	}
+	return b
+}
+
+func concatBytes(parts ...[]byte) []byte {
+	var b []byte
+	for _, p := range parts {
+		b = append(b, p...)
+	}
+	return b
+}
+
+func TestFormulaDecompile(t *testing.T) {
+	ctx := stubFormulaContext{
+		externs: []formula.Extern{
+			{First: "Sheet2", Last: "Sheet2"},
+			{First: "My Sheet", Last: "My Sheet"},
+			{First: "Jan", Last: "Mar"},
+			{Book: 1, First: "Data", Last: "Data"},
+		},
+		names: []string{"TaxRate", "_xlfn.CONCAT"},
+	}
+	ref3d := func(ixti uint16, row uint32, col uint16) []byte {
+		return concatBytes([]byte{0x3A}, biff12Le16(ixti), biff12Le32(row), biff12Le16(col))
+	}
+	num := func(v float64) []byte {
+		b := make([]byte, 9)
+		b[0] = 0x1F
+		binary.LittleEndian.PutUint64(b[1:], math.Float64bits(v))
+		return b
+	}
+	arrayExtra := concatBytes(
+		biff12Le32(1), biff12Le32(2), // 1 row, 2 cols
+		[]byte{0x00}, func() []byte { b := make([]byte, 8); binary.LittleEndian.PutUint64(b, math.Float64bits(1.5)); return b }(),
+		[]byte{0x01}, biff12Le16(1), biff12Le16('x'),
+	)
+
+	tests := []struct {
+		name     string
+		rgce     []byte
+		extra    []byte
+		row, col int
+		want     string
+	}{
+		{
+			name: "arithmetic with paren",
+			rgce: concatBytes(ptgRef(0x24, 0, 0, true, true), []byte{0x1E}, biff12Le16(2),
+				[]byte{0x03, 0x15}, num(0.5), []byte{0x05}),
+			want: "(A1+2)*0.5",
+		},
+		{
+			name: "string concat, percent, unary minus",
+			rgce: concatBytes(ptgStr(`say "hi"`), []byte{0x1E}, biff12Le16(5), []byte{0x14, 0x13, 0x08}),
+			want: `"say ""hi"""&-5%`,
+		},
+		{
+			name: "SUM attr over area times 3-D ref",
+			rgce: concatBytes(ptgArea(0x25, 0, 2, 0, 1), []byte{0x19, 0x10}, biff12Le16(0),
+				ref3d(0, 3, 2), []byte{0x05}),
+			want: "SUM(A1:B3)*Sheet2!$C$4",
+		},
+		{
+			name: "quoted and spanning sheet names",
+			rgce: concatBytes(ref3d(1, 0, 0), ref3d(2, 0, 0), []byte{0x03}),
+			want: "'My Sheet'!$A$1+Jan:Mar!$A$1",
+		},
+		{
+			name: "external book",
+			rgce: ref3d(3, 9, 0),
+			want: "[1]Data!$A$10",
+		},
+		{
+			name: "unresolvable ixti",
+			rgce: ref3d(9, 0, 0),
+			want: "#REF!$A$1",
+		},
+		{
+			name: "whole column and row",
+			rgce: concatBytes(ptgArea(0x25, 0, 0xFFFFF, 0, 0), ptgArea(0x25, 2, 2, 0, 0x3FFF), []byte{0x0F}),
+			want: "A:A 3:3",
+		},
+		{
+			name: "functions, bool and error",
+			rgce: concatBytes([]byte{0x1D, 1, 0x1C, 0x07, 0x1E}, biff12Le16(3),
+				[]byte{0x13, 0x41}, biff12Le16(24), // ABS(-3)
+				[]byte{0x42, 3}, biff12Le16(1)), // IF(…)
+			want: "IF(TRUE,#DIV/0!,ABS(-3))",
+		},
+		{
+			name: "defined name",
+			rgce: concatBytes(ptgRef(0x24, 0, 1, false, false), []byte{0x23}, biff12Le32(1), []byte{0x05}),
+			want: "$B$1*TaxRate",
+		},
+		{
+			name: "future function via UDF index",
+			rgce: concatBytes([]byte{0x23}, biff12Le32(2), ptgStr("a"), ptgStr("b"),
+				[]byte{0x22, 3}, biff12Le16(255)),
+			want: `CONCAT("a","b")`,
+		},
+		{
+			name: "RefN relative to owning cell",
+			rgce: concatBytes(ptgRef(0x2C, 0xFFFFFFFF, 1, true, true)), // one row up, one column right
+			row:  4, col: 2,
+			want: "D4",
+		},
+		{
+			name:  "array constant",
+			rgce:  concatBytes([]byte{0x60}, make([]byte, 14)),
+			extra: arrayExtra,
+			want:  `{1.5,"x"}`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := formula.Decompile(tc.rgce, tc.extra, tc.row, tc.col, ctx)
+			if err != nil {
+				t.Fatalf("Decompile: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("Decompile = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+// buildFormulaXLSB builds a two-sheet .xlsb whose first sheet holds:
+//
+//	A1: =SUM(A1:B3)*Sheet2!$C$4           (BrtFmlaNum, cached 10)
+//	B2: =A2*2, C2: =B2*2                  (shared formula anchored at B2)
+//	D3: {=SUM(A1:A2*B1:B2)}               (array formula anchored at D3)
+//
+// The workbook carries an ExternSheet table with one XTI pointing at Sheet2.
+func buildFormulaXLSB(t *testing.T) []byte {
+	t.Helper()
+
+	var wb bytes.Buffer
+	biff12WriteRec(&wb, 0x0183, nil) // WORKBOOK start
+	biff12WriteRec(&wb, 0x018F, nil) // SHEETS start
+	for i, name := range []string{"Sheet1", "Sheet2"} {
+		var sheetRec bytes.Buffer
+		sheetRec.Write(biff12Le32(0))
+		sheetRec.Write(biff12Le32(uint32(i + 1)))
+		sheetRec.Write(biff12EncStr(fmt.Sprintf("rId%d", i+1)))
+		sheetRec.Write(biff12EncStr(name))
+		biff12WriteRec(&wb, 0x019C, sheetRec.Bytes())
+	}
+	biff12WriteRec(&wb, 0x0190, nil) // SHEETS end
+	biff12WriteRec(&wb, 0x02E1, nil) // BrtBeginExternals
+	biff12WriteRec(&wb, 0x02E5, nil) // BrtSupSelf
+	biff12WriteRec(&wb, 0x02EA, concatBytes(
+		biff12Le32(1),                               // cXti
+		biff12Le32(0), biff12Le32(1), biff12Le32(1), // iSupBook=0, Sheet2..Sheet2
+	))
+	biff12WriteRec(&wb, 0x02E2, nil) // BrtEndExternals
+	biff12WriteRec(&wb, 0x0184, nil) // WORKBOOK end
+
+	fmlaCell := func(col uint32, v float64, rgce, extra []byte) []byte {
+		var b bytes.Buffer
+		b.Write(biff12Le32(col))
+		b.Write(biff12Le32(0))
+		_ = binary.Write(&b, binary.LittleEndian, v)
+		b.Write(biff12Le16(0)) // grbitFlags
+		b.Write(biff12Le32(uint32(len(rgce))))
+		b.Write(rgce)
+		b.Write(biff12Le32(uint32(len(extra))))
+		b.Write(extra)
+		return b.Bytes()
+	}
+	rfx := func(r1, r2, c1, c2 uint32) []byte {
+		return concatBytes(biff12Le32(r1), biff12Le32(r2), biff12Le32(c1), biff12Le32(c2))
+	}
+	ptgExp := func(row, col uint32) (rgce, extra []byte) {
+		return concatBytes([]byte{0x01}, biff12Le32(row)), biff12Le32(col)
+	}
+	withFormula := func(rgce []byte) []byte {
+		return concatBytes(biff12Le32(uint32(len(rgce))), rgce, biff12Le32(0))
+	}
+
+	var ws bytes.Buffer
+	biff12WriteRec(&ws, 0x0181, nil) // WORKSHEET start
+	biff12WriteRec(&ws, 0x0191, nil) // SHEETDATA start
+
+	biff12WriteRec(&ws, 0x0000, biff12Le32(0)) // ROW 0
+	biff12WriteRec(&ws, 0x0009, fmlaCell(0, 10, concatBytes(
+		ptgArea(0x25, 0, 2, 0, 1), []byte{0x19, 0x10}, biff12Le16(0),
+		[]byte{0x3A}, biff12Le16(0), biff12Le32(3), biff12Le16(2), // Sheet2!$C$4
+		[]byte{0x05},
+	), nil))
+
+	biff12WriteRec(&ws, 0x0000, biff12Le32(1)) // ROW 1
+	rgce, extra := ptgExp(1, 1)
+	biff12WriteRec(&ws, 0x0009, fmlaCell(1, 0, rgce, extra))
+	// BrtShrFmla B2:C2 — RefN one column left, times 2.
+	biff12WriteRec(&ws, biff12RawID(427), concatBytes(rfx(1, 1, 1, 2), withFormula(concatBytes(
+		ptgRef(0x2C, 0, 0x3FFF, true, true), []byte{0x1E}, biff12Le16(2), []byte{0x05},
+	))))
+	biff12WriteRec(&ws, 0x0009, fmlaCell(2, 0, rgce, extra))
+
+	biff12WriteRec(&ws, 0x0000, biff12Le32(2)) // ROW 2
+	rgce, extra = ptgExp(2, 3)
+	biff12WriteRec(&ws, 0x0009, fmlaCell(3, 0, rgce, extra))
+	// BrtArrFmla D3:D3 — flags byte, then SUM(A1:A2*B1:B2).
+	biff12WriteRec(&ws, biff12RawID(426), concatBytes(rfx(2, 2, 3, 3), []byte{0}, withFormula(concatBytes(
+		ptgArea(0x65, 0, 1, 0, 0), ptgArea(0x65, 0, 1, 1, 1), []byte{0x05},
+		[]byte{0x19, 0x10}, biff12Le16(0),
+	))))
+
+	biff12WriteRec(&ws, 0x0192, nil) // SHEETDATA end
+	biff12WriteRec(&ws, 0x0182, nil) // WORKSHEET end
+
+	var empty bytes.Buffer
+	biff12WriteRec(&empty, 0x0181, nil)
+	biff12WriteRec(&empty, 0x0191, nil)
+	biff12WriteRec(&empty, 0x0192, nil)
+	biff12WriteRec(&empty, 0x0182, nil)
+
+	var zipBuf bytes.Buffer
+	zw := zip.NewWriter(&zipBuf)
+	relsXML := `<?xml version="1.0" encoding="UTF-8"?>` +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="worksheet" Target="worksheets/sheet1.bin"/>` +
+		`<Relationship Id="rId2" Type="worksheet" Target="worksheets/sheet2.bin"/>` +
+		`</Relationships>`
+	zipAddFile(t, zw, "xl/_rels/workbook.bin.rels", []byte(relsXML))
+	zipAddFile(t, zw, "xl/workbook.bin", wb.Bytes())
+	zipAddFile(t, zw, "xl/worksheets/sheet1.bin", ws.Bytes())
+	zipAddFile(t, zw, "xl/worksheets/sheet2.bin", empty.Bytes())
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zip close: %v", err)
+	}
+	return zipBuf.Bytes()
+}
+
+// TestFormulaRecordIDs checks the shared/array formula record IDs against
+// the MS-XLSB record numbers, and that a data table (BrtTable, 428) anchored
+// like a shared formula is not mistaken for one.
+func TestFormulaRecordIDs(t *testing.T) {
+	if biff12.ArrFmla != biff12RawID(426) || biff12.ShrFmla != biff12RawID(427) {
+		t.Fatalf("ArrFmla = %#04x, ShrFmla = %#04x; want %#04x, %#04x",
+			biff12.ArrFmla, biff12.ShrFmla, biff12RawID(426), biff12RawID(427))
+	}
+
+	// A1 holds a PtgExp pointing at itself, followed by a BrtTable for A1:A1.
+	// The table payload is laid out so that, read as a BrtShrFmla, it would
+	// decode to the formula "5": rwInpRw = cce 3, colInpRw = PtgInt 5.
+	var ws bytes.Buffer
+	biff12WriteRec(&ws, 0x0181, nil) // WORKSHEET start
+	biff12WriteRec(&ws, 0x0191, nil) // SHEETDATA start
+	biff12WriteRec(&ws, 0x0000, biff12Le32(0))
+	biff12WriteRec(&ws, 0x0009, concatBytes(biff12Le32(0), biff12Le32(0), make([]byte, 8),
+		biff12Le16(0), biff12Le32(5), []byte{0x01}, biff12Le32(0), biff12Le32(4), biff12Le32(0)))
+	biff12WriteRec(&ws, biff12RawID(428), concatBytes(
+		biff12Le32(0), biff12Le32(0), biff12Le32(0), biff12Le32(0), // rfx A1:A1
+		biff12Le32(3), biff12Le32(0x051E), biff12Le32(0), biff12Le32(0), []byte{0},
+	))
+	biff12WriteRec(&ws, 0x0192, nil) // SHEETDATA end
+	biff12WriteRec(&ws, 0x0182, nil) // WORKSHEET end
+
+	data := buildSheetXLSB(t, ws.Bytes(), nil)
+	wb, err := workbook.OpenReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("OpenReader: %v", err)
+	}
+	defer wb.Close()
+	sheet, err := wb.Sheet(1)
+	if err != nil {
+		t.Fatalf("Sheet(1): %v", err)
+	}
+	cell, err := sheet.Cell(0, 0)
+	if err != nil {
+		t.Fatalf("Cell(0, 0): %v", err)
+	}
+	if cell.Formula != "" {
+		t.Errorf("Formula = %q, want empty (data tables are not decompiled)", cell.Formula)
+	}
+}
+
+func TestWorkbookCellFormula(t *testing.T) {
+	data := buildFormulaXLSB(t)
+	wb, err := workbook.OpenReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("OpenReader: %v", err)
+	}
+	defer wb.Close()
+
+	sheet, err := wb.Sheet(1)
+	if err != nil {
+		t.Fatalf("Sheet(1): %v", err)
+	}
+	got := make(map[[2]int]worksheet.Cell)
+	for row := range sheet.Rows(true) {
+		for _, c := range row {
+			if c.Formula != "" {
+				got[[2]int{c.R, c.C}] = c
+			}
+		}
+	}
+	if sheet.Err != nil {
+		t.Fatalf("Rows: %v", sheet.Err)
+	}
+
+	want := map[[2]int]string{
+		{0, 0}: "=SUM(A1:B3)*Sheet2!$C$4",
+		{1, 1}: "=A2*2",
+		{1, 2}: "=B2*2",
+		{2, 3}: "{=SUM(A1:A2*B1:B2)}",
+	}
+	for pos, w := range want {
+		if c := got[pos]; c.Formula != w {
+			t.Errorf("cell %v Formula = %q, want %q", pos, c.Formula, w)
+		}
+	}
+	if v := got[[2]int{0, 0}].V; v != 10.0 {
+		t.Errorf("cached value = %v, want 10", v)
+	}
+	if len(got) != len(want) {
+		t.Errorf("got %d formula cells, want %d", len(got), len(want))
+	}
+}
+
+// ── Inline string cells ───────────────────────────────────────────────────────
+
+func TestInlineStringCells(t *testing.T) {
+	cellHdr := func(col uint32) []byte {
+		return concatBytes(biff12Le32(col), biff12Le32(0))
+	}
+	var ws bytes.Buffer
+	biff12WriteRec(&ws, 0x0181, nil) // WORKSHEET start
+	biff12WriteRec(&ws, 0x0191, nil) // SHEETDATA start
+	biff12WriteRec(&ws, 0x0000, biff12Le32(0))
+	// BrtCellSt: inline XLWideString.
+	biff12WriteRec(&ws, 0x0006, concatBytes(cellHdr(0), biff12EncStr("inline")))
+	// BrtCellRString with two runs: "Bold" in font 1, " text" in font 0.
+	biff12WriteRec(&ws, 0x003E, concatBytes(cellHdr(1), []byte{0x01}, biff12EncStr("Bold text"),
+		biff12Le32(2), biff12Le16(0), biff12Le16(1), biff12Le16(4), biff12Le16(0)))
+	// BrtCellRString without runs.
+	biff12WriteRec(&ws, 0x003E, concatBytes(cellHdr(2), []byte{0x00}, biff12EncStr("plain")))
+	biff12WriteRec(&ws, 0x0192, nil) // SHEETDATA end
+	biff12WriteRec(&ws, 0x0182, nil) // WORKSHEET end
+
+	data := buildSheetXLSB(t, ws.Bytes(), nil)
+	wb, err := workbook.OpenReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("OpenReader: %v", err)
+	}
+	defer wb.Close()
+	sheet, err := wb.Sheet(1)
+	if err != nil {
+		t.Fatalf("Sheet(1): %v", err)
+	}
+	var row []worksheet.Cell
+	for r := range sheet.Rows(true) {
+		row = r
+	}
+	if sheet.Err != nil {
+		t.Fatalf("Rows: %v", sheet.Err)
+	}
+	if len(row) < 3 {
+		t.Fatalf("row has %d cells, want 3", len(row))
+	}
+	for i, want := range []string{"inline", "Bold text", "plain"} {
+		if row[i].V != want {
+			t.Errorf("cell[%d].V = %v, want %q", i, row[i].V, want)
+		}
+	}
+	if row[0].Rich != nil || row[2].Rich != nil {
+		t.Error("Rich is set on a cell without formatting runs")
+	}
+	rich := row[1].Rich
+	if rich == nil {
+		t.Fatal("cell[1].Rich is nil")
+	}
+	wantRuns := []stringtable.Run{{Start: 0, Font: 1}, {Start: 4, Font: 0}}
+	if fmt.Sprint(rich.Runs) != fmt.Sprint(wantRuns) {
+		t.Errorf("Runs = %v, want %v", rich.Runs, wantRuns)
+	}
+}
+
+// ── Rich shared strings ───────────────────────────────────────────────────────
+
+// buildFontRecord encodes a BrtFont payload with an RGB colour.
+func buildFontRecord(name string, twips uint16, bold, italic bool, rgb [3]byte) []byte {
+	var grbit, bls uint16 = 0, 400
+	if italic {
+		grbit |= 0x0002
+	}
+	if bold {
+		bls = 700
+	}
+	return concatBytes(
+		biff12Le16(twips), biff12Le16(grbit), biff12Le16(bls), biff12Le16(0),
+		[]byte{0x01, 0x02, 0x00, 0x00}, // uls single, bFamily, bCharSet, unused
+		[]byte{0x02<<1 | 0x01, 0x00}, biff12Le16(0), []byte{rgb[0], rgb[1], rgb[2], 0xFF},
+		[]byte{0x02}, // bFontScheme minor
+		biff12EncStr(name),
+	)
+}
+
+func TestRichSharedStrings(t *testing.T) {
+	// SST: a plain entry, a rich entry with two runs, and a rich entry that
+	// also carries phonetic data after its runs.
+	var sst bytes.Buffer
+	biff12WriteRec(&sst, 0x019F, concatBytes(biff12Le32(3), biff12Le32(3)))
+	biff12WriteRec(&sst, 0x0013, concatBytes([]byte{0x00}, biff12EncStr("plain")))
+	biff12WriteRec(&sst, 0x0013, concatBytes([]byte{0x01}, biff12EncStr("Hi there"),
+		biff12Le32(2), biff12Le16(0), biff12Le16(1), biff12Le16(2), biff12Le16(0)))
+	biff12WriteRec(&sst, 0x0013, concatBytes([]byte{0x03}, biff12EncStr("東京"),
+		biff12Le32(1), biff12Le16(1), biff12Le16(1),
+		biff12EncStr("トウキョウ"), biff12Le32(0)))
+	biff12WriteRec(&sst, 0x01A0, nil)
+
+	var sty bytes.Buffer
+	biff12WriteRec(&sty, 0x0296, nil) // StyleSheet start
+	biff12WriteRec(&sty, 0x04E3, biff12Le32(2))
+	biff12WriteRec(&sty, 0x002B, buildFontRecord("Calibri", 220, false, false, [3]byte{0, 0, 0}))
+	biff12WriteRec(&sty, 0x002B, buildFontRecord("Arial", 240, true, true, [3]byte{0xFF, 0, 0}))
+	biff12WriteRec(&sty, 0x04E4, nil)
+	biff12WriteRec(&sty, 0x0297, nil) // StyleSheet end
+
+	var ws bytes.Buffer
+	biff12WriteRec(&ws, 0x0181, nil)
+	biff12WriteRec(&ws, 0x0191, nil)
+	biff12WriteRec(&ws, 0x0000, biff12Le32(0))
+	for col := range 3 {
+		biff12WriteRec(&ws, 0x0007, concatBytes(biff12Le32(uint32(col)), biff12Le32(0), biff12Le32(uint32(col))))
+	}
+	biff12WriteRec(&ws, 0x0192, nil)
+	biff12WriteRec(&ws, 0x0182, nil)
+
+	data := buildSheetXLSB(t, ws.Bytes(), map[string][]byte{
+		"xl/sharedStrings.bin": sst.Bytes(),
+		"xl/styles.bin":        sty.Bytes(),
+	})
+	wb, err := workbook.OpenReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("OpenReader: %v", err)
+	}
+	defer wb.Close()
+
+	if len(wb.Fonts) != 2 {
+		t.Fatalf("len(Fonts) = %d, want 2", len(wb.Fonts))
+	}
+	wantFont := styles.Font{
+		Name: "Arial", Size: 12, Bold: true, Weight: 700, Italic: true, Underline: styles.UnderlineSingle,
+		Color:  styles.Color{Type: styles.ColorRGB, RGB: 0xFFFF0000},
+		Family: styles.FontFamilySwiss, Scheme: styles.FontSchemeMinor,
+	}
+	if wb.Fonts[1] != wantFont {
+		t.Errorf("Fonts[1] = %+v, want %+v", wb.Fonts[1], wantFont)
+	}
+
+	sheet, err := wb.Sheet(1)
+	if err != nil {
+		t.Fatalf("Sheet(1): %v", err)
+	}
+	var row []worksheet.Cell
+	for r := range sheet.Rows(true) {
+		row = r
+	}
+	if len(row) < 3 {
+		t.Fatalf("row has %d cells, want 3", len(row))
+	}
+	for i, want := range []string{"plain", "Hi there", "東京"} {
+		if row[i].V != want {
+			t.Errorf("cell[%d].V = %v, want %q", i, row[i].V, want)
+		}
+	}
+	if row[0].Rich != nil {
+		t.Error("plain shared string has Rich set")
+	}
+
+	runs := wb.RichText(row[1])
+	if len(runs) != 2 || runs[0].Text != "Hi" || runs[1].Text != " there" {
+		t.Fatalf("RichText = %+v, want [Hi][ there]", runs)
+	}
+	if runs[0].Font == nil || runs[0].Font.Name != "Arial" || runs[1].Font == nil || runs[1].Font.Name != "Calibri" {
+		t.Errorf("run fonts not resolved: %+v", runs)
+	}
+
+	// The first run starts at offset 1, so the leading text keeps the cell font.
+	runs = wb.RichText(row[2])
+	if len(runs) != 2 || runs[0].Font != nil || runs[0].Text != "東" || runs[1].Text != "京" {
+		t.Errorf("RichText with leading text = %+v", runs)
+	}
+	if wb.RichText(row[0]) != nil {
+		t.Error("RichText of a plain cell is non-nil")
+	}
+}
+
+// TestStyleFonts checks the full BrtFont decoding and the per-XF font
+// resolution, including rich text whose leading characters use the cell font.
+func TestStyleFonts(t *testing.T) {
+	// BrtFont: 9pt, italic+outline+shadow, weight 600, subscript, double
+	// underline, Roman family, Shift-JIS, theme colour 1, major scheme.
+	sub := concatBytes(
+		biff12Le16(180), biff12Le16(0x0032), biff12Le16(600), biff12Le16(2),
+		[]byte{0x02, 0x01, 0x80, 0x00},
+		[]byte{0x03 << 1, 0x01}, biff12Le16(0), []byte{0, 0, 0, 0xFF},
+		[]byte{0x01},
+		biff12EncStr("MS Mincho"),
+	)
+	xf := func(numFmt, font uint16) []byte {
+		return concatBytes(biff12Le16(0), biff12Le16(numFmt), biff12Le16(font),
+			biff12Le16(0), biff12Le16(0), []byte{0, 0}, biff12Le16(0x1000), biff12Le16(0))
+	}
+	var sty bytes.Buffer
+	biff12WriteRec(&sty, 0x0296, nil) // StyleSheet start
+	biff12WriteRec(&sty, biff12.Fonts, biff12Le32(2))
+	biff12WriteRec(&sty, biff12.Font, buildFontRecord("Calibri", 220, false, false, [3]byte{}))
+	biff12WriteRec(&sty, biff12.Font, sub)
+	biff12WriteRec(&sty, biff12.FontsEnd, nil)
+	biff12WriteRec(&sty, biff12.CellXfs, biff12Le32(3))
+	biff12WriteRec(&sty, biff12.Xf, xf(0, 0))
+	biff12WriteRec(&sty, biff12.Xf, xf(0, 1))
+	biff12WriteRec(&sty, biff12.Xf, xf(0, 9)) // font index out of range
+	biff12WriteRec(&sty, biff12.CellXfsEnd, nil)
+	biff12WriteRec(&sty, 0x0297, nil) // StyleSheet end
+
+	// "H2O" as a rich inline string in XF 0 with a subscript "2"; the first
+	// run starts at offset 1.
+	var ws bytes.Buffer
+	biff12WriteRec(&ws, 0x0181, nil)
+	biff12WriteRec(&ws, 0x0191, nil)
+	biff12WriteRec(&ws, 0x0000, biff12Le32(0))
+	biff12WriteRec(&ws, biff12.CellRString, concatBytes(biff12Le32(0), biff12Le32(0), []byte{0x01},
+		biff12EncStr("H2O"), biff12Le32(2), biff12Le16(1), biff12Le16(1), biff12Le16(2), biff12Le16(0)))
+	biff12WriteRec(&ws, 0x0192, nil)
+	biff12WriteRec(&ws, 0x0182, nil)
+
+	data := buildSheetXLSB(t, ws.Bytes(), map[string][]byte{"xl/styles.bin": sty.Bytes()})
+	wb, err := workbook.OpenReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("OpenReader: %v", err)
+	}
+	defer wb.Close()
+
+	want := styles.Font{
+		Name: "MS Mincho", Size: 9, Weight: 600, Italic: true, Outline: true, Shadow: true,
+		Underline: styles.UnderlineDouble, VertAlign: styles.VertAlignSubscript,
+		Color:  styles.Color{Type: styles.ColorTheme, Index: 1, RGB: 0xFF000000},
+		Family: styles.FontFamilyRoman, Charset: 128, Scheme: styles.FontSchemeMajor,
+	}
+	if len(wb.Fonts) != 2 || wb.Fonts[1] != want {
+		t.Fatalf("Fonts = %+v, want [1] = %+v", wb.Fonts, want)
+	}
+	if len(wb.Styles) != 3 {
+		t.Fatalf("len(Styles) = %d, want 3", len(wb.Styles))
+	}
+	for i, wantID := range []int{0, 1, 9} {
+		if got := wb.Styles[i].FontID; got != wantID {
+			t.Errorf("Styles[%d].FontID = %d, want %d", i, got, wantID)
+		}
+	}
+	if wb.Styles[0].Font != &wb.Fonts[0] || wb.Styles[1].Font != &wb.Fonts[1] {
+		t.Error("XF fonts do not point into wb.Fonts")
+	}
+	if wb.Styles[2].Font != nil {
+		t.Errorf("out-of-range FontID resolved to %+v", wb.Styles[2].Font)
+	}
+
+	sheet, err := wb.Sheet(1)
+	if err != nil {
+		t.Fatalf("Sheet(1): %v", err)
+	}
+	cell, err := sheet.Cell(0, 0)
+	if err != nil {
+		t.Fatalf("Cell(0, 0): %v", err)
+	}
+	runs := wb.RichText(cell)
+	if len(runs) != 3 || runs[0].Text != "H" || runs[1].Text != "2" || runs[2].Text != "O" {
+		t.Fatalf("RichText = %+v, want [H][2][O]", runs)
+	}
+	// The leading "H" has no run and takes the font of XF 0.
+	if runs[0].Font != &wb.Fonts[0] || runs[1].Font != &wb.Fonts[1] || runs[2].Font != &wb.Fonts[0] {
+		t.Errorf("run fonts = %v, %v, %v", runs[0].Font, runs[1].Font, runs[2].Font)
+	}
+}
+
+// TestStyleFills checks pattern and gradient fill decoding and the per-XF
+// fill resolution.
+func TestStyleFills(t *testing.T) {
+	xnum := func(v float64) []byte {
+		var b [8]byte
+		binary.LittleEndian.PutUint64(b[:], math.Float64bits(v))
+		return b[:]
+	}
+	rgb := func(r, g, b byte) []byte {
+		return concatBytes([]byte{0x02<<1 | 0x01, 0x00}, biff12Le16(0), []byte{r, g, b, 0xFF})
+	}
+	auto := concatBytes([]byte{0x00, 0x00}, biff12Le16(0), []byte{0, 0, 0, 0})
+	noGradient := make([]byte, 4+5*8+4)
+	fill := func(fls uint32, fg, bg, rest []byte) []byte {
+		return concatBytes(biff12Le32(fls), fg, bg, rest)
+	}
+	// Gradient: path type, 90°, inner rect (0.2, 0.8, 0.3, 0.7), two stops.
+	grad := concatBytes(biff12Le32(1), xnum(90), xnum(0.2), xnum(0.8), xnum(0.3), xnum(0.7),
+		biff12Le32(2), rgb(0xFF, 0xFF, 0xFF), xnum(0), rgb(0x44, 0x72, 0xC4), xnum(1))
+	xf := func(fill uint16) []byte {
+		return concatBytes(biff12Le16(0), biff12Le16(0), biff12Le16(0),
+			biff12Le16(fill), biff12Le16(0), []byte{0, 0}, biff12Le16(0x1000), biff12Le16(0))
+	}
+	var sty bytes.Buffer
+	biff12WriteRec(&sty, 0x0296, nil) // StyleSheet start
+	biff12WriteRec(&sty, biff12.Fills, biff12Le32(5))
+	biff12WriteRec(&sty, biff12.Fill, fill(0, auto, auto, noGradient))
+	biff12WriteRec(&sty, biff12.Fill, fill(17, auto, auto, noGradient))
+	biff12WriteRec(&sty, biff12.Fill, fill(1, rgb(0xFF, 0xFF, 0x00), auto, noGradient))
+	biff12WriteRec(&sty, biff12.Fill, fill(0x28, auto, auto, grad))
+	biff12WriteRec(&sty, biff12.Fill, fill(0x28, auto, auto, concatBytes(make([]byte, 44), biff12Le32(1000))))
+	biff12WriteRec(&sty, biff12.FillsEnd, nil)
+	biff12WriteRec(&sty, biff12.CellXfs, biff12Le32(4))
+	biff12WriteRec(&sty, biff12.Xf, xf(0))
+	biff12WriteRec(&sty, biff12.Xf, xf(2))
+	biff12WriteRec(&sty, biff12.Xf, xf(3))
+	biff12WriteRec(&sty, biff12.Xf, xf(7)) // out of range
+	biff12WriteRec(&sty, biff12.CellXfsEnd, nil)
+	biff12WriteRec(&sty, 0x0297, nil) // StyleSheet end
+
+	var ws bytes.Buffer
+	biff12WriteRec(&ws, 0x0181, nil)
+	biff12WriteRec(&ws, 0x0182, nil)
+	data := buildSheetXLSB(t, ws.Bytes(), map[string][]byte{"xl/styles.bin": sty.Bytes()})
+	wb, err := workbook.OpenReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("OpenReader: %v", err)
+	}
+	defer wb.Close()
+
+	if len(wb.Fills) != 5 {
+		t.Fatalf("len(Fills) = %d, want 5", len(wb.Fills))
+	}
+	if f := wb.Fills[1]; f.Pattern != styles.FillGray125 || f.Gradient != nil {
+		t.Errorf("Fills[1] = %+v, want gray125 without gradient", f)
+	}
+	yellow := styles.Color{Type: styles.ColorRGB, RGB: 0xFFFFFF00}
+	if f := wb.Fills[2]; f.Pattern != styles.FillSolid || f.FgColor != yellow || f.BgColor.Type != styles.ColorAuto {
+		t.Errorf("Fills[2] = %+v, want solid yellow", f)
+	}
+	g := wb.Fills[3].Gradient
+	if wb.Fills[3].Pattern != styles.FillGradient || g == nil {
+		t.Fatalf("Fills[3] = %+v, want a gradient", wb.Fills[3])
+	}
+	if g.Type != styles.GradientPath || g.Degree != 90 || g.Left != 0.2 || g.Right != 0.8 || g.Top != 0.3 || g.Bottom != 0.7 {
+		t.Errorf("gradient = %+v", g)
+	}
+	wantStops := []styles.GradientStop{
+		{Position: 0, Color: styles.Color{Type: styles.ColorRGB, RGB: 0xFFFFFFFF}},
+		{Position: 1, Color: styles.Color{Type: styles.ColorRGB, RGB: 0xFF4472C4}},
+	}
+	if !slices.Equal(g.Stops, wantStops) {
+		t.Errorf("Stops = %+v, want %+v", g.Stops, wantStops)
+	}
+	// An impossible stop count leaves the slot in place without a gradient.
+	if f := wb.Fills[4]; f.Pattern != styles.FillGradient || f.Gradient != nil {
+		t.Errorf("Fills[4] = %+v, want a gradient pattern with no stops decoded", f)
+	}
+
+	for i, wantID := range []int{0, 2, 3, 7} {
+		xs := wb.Styles[i]
+		if xs.FillID != wantID {
+			t.Errorf("Styles[%d].FillID = %d, want %d", i, xs.FillID, wantID)
+		}
+		if wantID < len(wb.Fills) && xs.Fill != &wb.Fills[wantID] || wantID >= len(wb.Fills) && xs.Fill != nil {
+			t.Errorf("Styles[%d].Fill = %p, want fill %d", i, xs.Fill, wantID)
+		}
+	}
+}
+
+// TestStyleBorders checks BrtBorder decoding and the per-XF border
+// resolution.
+func TestStyleBorders(t *testing.T) {
+	edge := func(dg byte, color []byte) []byte {
+		return concatBytes([]byte{dg, 0x00}, color)
+	}
+	auto := concatBytes([]byte{0x00, 0x00}, biff12Le16(0), []byte{0, 0, 0, 0})
+	red := concatBytes([]byte{0x02<<1 | 0x01, 0x00}, biff12Le16(0), []byte{0xFF, 0, 0, 0xFF})
+	theme := concatBytes([]byte{0x03 << 1, 0x04}, biff12Le16(0), []byte{0, 0, 0, 0})
+	none := edge(0, auto)
+	xf := func(border uint16) []byte {
+		return concatBytes(biff12Le16(0), biff12Le16(0), biff12Le16(0),
+			biff12Le16(0), biff12Le16(border), []byte{0, 0}, biff12Le16(0x1000), biff12Le16(0))
+	}
+	var sty bytes.Buffer
+	biff12WriteRec(&sty, 0x0296, nil) // StyleSheet start
+	biff12WriteRec(&sty, biff12.Borders, biff12Le32(3))
+	biff12WriteRec(&sty, biff12.Border, concatBytes([]byte{0}, none, none, none, none, none))
+	// Box: thin top/left/right, double red bottom, dashed theme diagonal both ways.
+	biff12WriteRec(&sty, biff12.Border, concatBytes([]byte{0x03},
+		edge(1, auto), edge(6, red), edge(1, auto), edge(1, auto), edge(3, theme)))
+	biff12WriteRec(&sty, biff12.Border, []byte{0x02, 0x05}) // truncated
+	biff12WriteRec(&sty, biff12.BordersEnd, nil)
+	biff12WriteRec(&sty, biff12.CellXfs, biff12Le32(3))
+	biff12WriteRec(&sty, biff12.Xf, xf(0))
+	biff12WriteRec(&sty, biff12.Xf, xf(1))
+	biff12WriteRec(&sty, biff12.Xf, xf(5)) // out of range
+	biff12WriteRec(&sty, biff12.CellXfsEnd, nil)
+	biff12WriteRec(&sty, 0x0297, nil) // StyleSheet end
+
+	var ws bytes.Buffer
+	biff12WriteRec(&ws, 0x0181, nil)
+	biff12WriteRec(&ws, 0x0182, nil)
+	data := buildSheetXLSB(t, ws.Bytes(), map[string][]byte{"xl/styles.bin": sty.Bytes()})
+	wb, err := workbook.OpenReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("OpenReader: %v", err)
+	}
+	defer wb.Close()
+
+	if len(wb.Borders) != 3 {
+		t.Fatalf("len(Borders) = %d, want 3", len(wb.Borders))
+	}
+	if wb.Borders[0] != (styles.Border{}) {
+		t.Errorf("Borders[0] = %+v, want no edges", wb.Borders[0])
+	}
+	thin := styles.BorderEdge{Style: styles.BorderThin}
+	want := styles.Border{
+		Top: thin, Left: thin, Right: thin,
+		Bottom:     styles.BorderEdge{Style: styles.BorderDouble, Color: styles.Color{Type: styles.ColorRGB, RGB: 0xFFFF0000}},
+		Diagonal:   styles.BorderEdge{Style: styles.BorderDashed, Color: styles.Color{Type: styles.ColorTheme, Index: 4}},
+		DiagonalUp: true, DiagonalDown: true,
+	}
+	if wb.Borders[1] != want {
+		t.Errorf("Borders[1] = %+v, want %+v", wb.Borders[1], want)
+	}
+	// A truncated record keeps its slot and the edges read so far.
+	if b := wb.Borders[2]; !b.DiagonalUp || b.DiagonalDown || b.Top.Style != styles.BorderThick {
+		t.Errorf("Borders[2] = %+v, want diagonal-up with a thick top", b)
+	}
+
+	if wb.Styles[1].BorderID != 1 || wb.Styles[1].Border != &wb.Borders[1] {
+		t.Errorf("Styles[1] border = %d/%p, want 1/%p", wb.Styles[1].BorderID, wb.Styles[1].Border, &wb.Borders[1])
+	}
+	if wb.Styles[2].BorderID != 5 || wb.Styles[2].Border != nil {
+		t.Errorf("Styles[2] border = %d/%v, want 5/nil", wb.Styles[2].BorderID, wb.Styles[2].Border)
+	}
+}
+
+// TestStyleAlignment checks decoding of the alignment, protection and apply
+// flags of BrtXF.
+func TestStyleAlignment(t *testing.T) {
+	xf := func(trot, indent byte, flags uint16, atr byte) []byte {
+		return concatBytes(biff12Le16(0), biff12Le16(0), biff12Le16(0), biff12Le16(0), biff12Le16(0),
+			[]byte{trot, indent}, biff12Le16(flags), []byte{atr, 0})
+	}
+	var sty bytes.Buffer
+	biff12WriteRec(&sty, 0x0296, nil) // StyleSheet start
+	biff12WriteRec(&sty, biff12.CellXfs, biff12Le32(5))
+	// Default: bottom-aligned, locked.
+	biff12WriteRec(&sty, biff12.Xf, xf(0, 0, 2<<3|0x1000, 0))
+	// Centre/top, wrap, shrink, right-to-left, hidden, unlocked, 45°, indent 2,
+	// quote prefix; applies alignment and protection.
+	biff12WriteRec(&sty, biff12.Xf, xf(45, 2, 2|0x0040|0x0100|2<<10|0x2000|0x8000, 0x04|0x20))
+	// Distributed with justified last line, 30° clockwise, pivot button, all
+	// apply flags.
+	biff12WriteRec(&sty, biff12.Xf, xf(120, 0, 7|4<<3|0x0080|0x1000|0x4000, 0x3F))
+	// Stacked vertical text.
+	biff12WriteRec(&sty, biff12.Xf, xf(255, 0, 2<<3|0x1000, 0x01|0x02|0x08|0x10))
+	// Truncated after the border index: defaults apply.
+	biff12WriteRec(&sty, biff12.Xf, make([]byte, 10))
+	biff12WriteRec(&sty, biff12.CellXfsEnd, nil)
+	biff12WriteRec(&sty, 0x0297, nil) // StyleSheet end
+
+	var ws bytes.Buffer
+	biff12WriteRec(&ws, 0x0181, nil)
+	biff12WriteRec(&ws, 0x0182, nil)
+	data := buildSheetXLSB(t, ws.Bytes(), map[string][]byte{"xl/styles.bin": sty.Bytes()})
+	wb, err := workbook.OpenReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("OpenReader: %v", err)
+	}
+	defer wb.Close()
+	if len(wb.Styles) != 5 {
+		t.Fatalf("len(Styles) = %d, want 5", len(wb.Styles))
+	}
+
+	wantAlign := []styles.Alignment{
+		{Vertical: styles.VAlignBottom},
+		{Horizontal: styles.HAlignCenter, Vertical: styles.VAlignTop, WrapText: true, ShrinkToFit: true,
+			Indent: 2, Rotation: 45, ReadingOrder: styles.ReadingOrderRightToLeft},
+		{Horizontal: styles.HAlignDistributed, Vertical: styles.VAlignDistributed, JustifyLastLine: true, Rotation: 120},
+		{Vertical: styles.VAlignBottom, Rotation: styles.RotationStacked},
+		{Vertical: styles.VAlignBottom},
+	}
+	for i, want := range wantAlign {
+		if got := wb.Styles[i].Alignment; got != want {
+			t.Errorf("Styles[%d].Alignment = %+v, want %+v", i, got, want)
+		}
+	}
+	for i, want := range []int{0, 45, -30, 0, 0} {
+		if got := wb.Styles[i].Alignment.Angle(); got != want {
+			t.Errorf("Styles[%d].Alignment.Angle() = %d, want %d", i, got, want)
+		}
+	}
+
+	x := wb.Styles[1]
+	if x.Locked || !x.Hidden || !x.QuotePrefix || x.PivotButton {
+		t.Errorf("Styles[1] protection = %+v", x)
+	}
+	if x.ApplyNumberFormat || x.ApplyFont || !x.ApplyAlignment || x.ApplyBorder || x.ApplyFill || !x.ApplyProtection {
+		t.Errorf("Styles[1] apply flags = %+v", x)
+	}
+	x = wb.Styles[2]
+	if !x.Locked || x.Hidden || x.QuotePrefix || !x.PivotButton {
+		t.Errorf("Styles[2] protection = %+v", x)
+	}
+	if !(x.ApplyNumberFormat && x.ApplyFont && x.ApplyAlignment && x.ApplyBorder && x.ApplyFill && x.ApplyProtection) {
+		t.Errorf("Styles[2] apply flags = %+v", x)
+	}
+	x = wb.Styles[3]
+	if !(x.ApplyNumberFormat && x.ApplyFont && x.ApplyBorder && x.ApplyFill) || x.ApplyAlignment || x.ApplyProtection {
+		t.Errorf("Styles[3] apply flags = %+v", x)
+	}
+	if !wb.Styles[4].Locked {
+		t.Error("truncated XF is not locked by default")
+	}
+}
+
+// TestThemeColors checks theme parsing, the custom indexed palette and
+// colour resolution for every colour type, with and without tint.
+func TestThemeColors(t *testing.T) {
+	theme := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<a:theme xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" name="Custom Theme">` +
+		`<a:themeElements><a:clrScheme name="Custom">` +
+		`<a:dk1><a:sysClr val="windowText" lastClr="111111"/></a:dk1>` +
+		`<a:lt1><a:sysClr val="window" lastClr="FEFEFE"/></a:lt1>` +
+		`<a:dk2><a:srgbClr val="222222"/></a:dk2>` +
+		`<a:lt2><a:srgbClr val="EEEEEE"/></a:lt2>` +
+		`<a:accent1><a:srgbClr val="4472C4"/></a:accent1>` +
+		`<a:accent2><a:srgbClr val="bogus"/></a:accent2>` +
+		`</a:clrScheme>` +
+		`<a:fontScheme name="Office">` +
+		`<a:majorFont><a:latin typeface="Calibri Light"/><a:ea typeface=""/><a:cs typeface=""/>` +
+		`<a:font script="Jpan" typeface="游ゴシック Light"/></a:majorFont>` +
+		`<a:minorFont><a:latin typeface="Calibri"/><a:ea typeface=""/><a:cs typeface=""/></a:minorFont>` +
+		`</a:fontScheme></a:themeElements></a:theme>`
+
+	var sty bytes.Buffer
+	biff12WriteRec(&sty, 0x0296, nil) // StyleSheet start
+	biff12WriteRec(&sty, biff12.Colors, nil)
+	biff12WriteRec(&sty, 0x04B5, biff12Le32(2)) // BrtBeginIndexedColors
+	biff12WriteRec(&sty, biff12.Color, []byte{0x12, 0x34, 0x56, 0xFF})
+	biff12WriteRec(&sty, biff12.Color, []byte{0xAB, 0xCD, 0xEF, 0xFF})
+	biff12WriteRec(&sty, 0x04B6, nil) // BrtEndIndexedColors
+	biff12WriteRec(&sty, biff12.ColorsEnd, nil)
+	biff12WriteRec(&sty, 0x0297, nil) // StyleSheet end
+
+	var ws bytes.Buffer
+	biff12WriteRec(&ws, 0x0181, nil)
+	biff12WriteRec(&ws, 0x0182, nil)
+	open := func(parts map[string][]byte) *workbook.Workbook {
+		t.Helper()
+		data := buildSheetXLSB(t, ws.Bytes(), parts)
+		wb, err := workbook.OpenReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatalf("OpenReader: %v", err)
+		}
+		t.Cleanup(func() { wb.Close() })
+		return wb
+	}
+	wb := open(map[string][]byte{"xl/styles.bin": sty.Bytes(), "xl/theme/theme1.xml": []byte(theme)})
+
+	th := wb.Theme
+	if th == nil {
+		t.Fatal("Theme is nil")
+	}
+	if th.Name != "Custom Theme" || th.ColorSchemeName != "Custom" || th.FontSchemeName != "Office" {
+		t.Errorf("Theme names = %q/%q/%q", th.Name, th.ColorSchemeName, th.FontSchemeName)
+	}
+	wantColors := styles.DefaultThemeColors
+	wantColors[styles.ThemeDark1] = 0xFF111111
+	wantColors[styles.ThemeLight1] = 0xFFFEFEFE
+	wantColors[styles.ThemeDark2] = 0xFF222222
+	wantColors[styles.ThemeLight2] = 0xFFEEEEEE
+	if th.Colors != wantColors {
+		t.Errorf("Theme.Colors = %08X, want %08X", th.Colors, wantColors)
+	}
+	if th.MajorFont.Latin != "Calibri Light" || th.MinorFont.Latin != "Calibri" || th.MajorFont.Scripts["Jpan"] != "游ゴシック Light" {
+		t.Errorf("theme fonts = %+v / %+v", th.MajorFont, th.MinorFont)
+	}
+	if want := []uint32{0xFF123456, 0xFFABCDEF}; !slices.Equal(wb.Palette, want) {
+		t.Errorf("Palette = %08X, want %08X", wb.Palette, want)
+	}
+
+	tint := func(v int16) float64 { return float64(v) / 32767 }
+	tests := []struct {
+		name string
+		c    styles.Color
+		want uint32
+	}{
+		{"auto", styles.Color{Type: styles.ColorAuto}, 0xFF000000},
+		{"rgb", styles.Color{Type: styles.ColorRGB, RGB: 0xFF336699}, 0xFF336699},
+		{"indexed custom", styles.Color{Type: styles.ColorIndexed, Index: 1}, 0xFFABCDEF},
+		{"indexed default", styles.Color{Type: styles.ColorIndexed, Index: 10}, 0xFFFF0000},
+		{"indexed system fg", styles.Color{Type: styles.ColorIndexed, Index: 64}, 0xFF000000},
+		{"indexed system bg", styles.Color{Type: styles.ColorIndexed, Index: 65}, 0xFFFFFFFF},
+		{"theme bg1", styles.Color{Type: styles.ColorTheme, Index: 0}, 0xFFFEFEFE},
+		{"theme tx1", styles.Color{Type: styles.ColorTheme, Index: 1}, 0xFF111111},
+		{"theme bg2", styles.Color{Type: styles.ColorTheme, Index: 2}, 0xFFEEEEEE},
+		{"theme tx2", styles.Color{Type: styles.ColorTheme, Index: 3}, 0xFF222222},
+		{"theme accent1", styles.Color{Type: styles.ColorTheme, Index: 4}, 0xFF4472C4},
+		{"theme bad slot keeps default", styles.Color{Type: styles.ColorTheme, Index: 5}, 0xFFED7D31},
+		{"accent1 lighter 40%", styles.Color{Type: styles.ColorTheme, Index: 4, Tint: tint(13106)}, 0xFF8FAADC},
+		{"accent1 lighter 80%", styles.Color{Type: styles.ColorTheme, Index: 4, Tint: tint(26213)}, 0xFFDAE3F3},
+		{"accent1 darker 25%", styles.Color{Type: styles.ColorTheme, Index: 4, Tint: tint(-8191)}, 0xFF2F5597},
+		{"rgb darker 50%", styles.Color{Type: styles.ColorRGB, RGB: 0xFFFFFFFF, Tint: tint(-16383)}, 0xFF808080},
+	}
+	for _, tc := range tests {
+		if got := wb.ResolveColor(tc.c); got != tc.want {
+			t.Errorf("%s: ResolveColor = %08X, want %08X", tc.name, got, tc.want)
+		}
+	}
+
+	// Without a theme part or custom palette the Office defaults apply; a
+	// corrupt theme is ignored.
+	plain := open(map[string][]byte{"xl/theme/theme1.xml": []byte("<a:theme")})
+	if plain.Theme != nil || plain.Palette != nil {
+		t.Errorf("Theme = %v, Palette = %v; want nil", plain.Theme, plain.Palette)
+	}
+	if got := plain.ResolveColor(styles.Color{Type: styles.ColorTheme, Index: 1}); got != 0xFF000000 {
+		t.Errorf("default tx1 = %08X, want FF000000", got)
+	}
+	if got := plain.ResolveColor(styles.Color{Type: styles.ColorIndexed, Index: 1}); got != 0xFFFFFFFF {
+		t.Errorf("default indexed 1 = %08X, want FFFFFFFF", got)
+	}
+}
+
+func TestRichStringSegmentsSurrogates(t *testing.T) {
+	// "😀ab": the emoji occupies two UTF-16 code units, so ich=2 is rune 1.
+	var b bytes.Buffer
+	b.WriteByte(0x01)
+	b.Write(biff12Le32(4))
+	for _, u := range []uint16{0xD83D, 0xDE00, 'a', 'b'} {
+		b.Write(biff12Le16(u))
+	}
+	b.Write(concatBytes(biff12Le32(2), biff12Le16(0), biff12Le16(3), biff12Le16(2), biff12Le16(5)))
+	rs, err := stringtable.ReadRichString(record.NewRecordReader(b.Bytes()))
+	if err != nil {
+		t.Fatalf("ReadRichString: %v", err)
+	}
+	want := []stringtable.Segment{{Text: "😀", Font: 3}, {Text: "ab", Font: 5}}
+	if got := rs.Segments(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Segments = %v, want %v", got, want)
+	}
+}
+
+// ── Phonetic strings ──────────────────────────────────────────────────────────
+
+func TestSharedStringPhonetic(t *testing.T) {
+	// "東京都" read as "トウキョウト": 東京 ← トウキョウ, 都 ← ト.
+	var sst bytes.Buffer
+	biff12WriteRec(&sst, 0x019F, concatBytes(biff12Le32(2), biff12Le32(2)))
+	biff12WriteRec(&sst, 0x0013, concatBytes([]byte{0x00}, biff12EncStr("plain")))
+	biff12WriteRec(&sst, 0x0013, concatBytes([]byte{0x02}, biff12EncStr("東京都"),
+		biff12EncStr("トウキョウト"), biff12Le32(2),
+		biff12Le16(0), biff12Le16(0), biff12Le16(2), biff12Le16(1), biff12Le16(0x02|1<<2), // Hiragana, left
+		biff12Le16(5), biff12Le16(2), biff12Le16(1), biff12Le16(1), biff12Le16(0x02|1<<2),
+	))
+	biff12WriteRec(&sst, 0x01A0, nil)
+
+	st, err := stringtable.NewFromBytes(sst.Bytes())
+	if err != nil {
+		t.Fatalf("NewFromBytes: %v", err)
+	}
+	if _, ok := st.GetPhonetic(0); ok {
+		t.Error("GetPhonetic(0) reports phonetic data for a plain string")
+	}
+	if got := st.Get(1); got != "東京都" {
+		t.Errorf("Get(1) = %q, want %q", got, "東京都")
+	}
+	ph, ok := st.GetPhonetic(1)
+	if !ok {
+		t.Fatal("GetPhonetic(1) found no phonetic data")
+	}
+	if ph.Text != "トウキョウト" {
+		t.Errorf("Phonetic.Text = %q, want %q", ph.Text, "トウキョウト")
+	}
+	want := []stringtable.PhoneticRun{
+		{Start: 0, BaseStart: 0, BaseLen: 2, Font: 1, Type: stringtable.PhoneticHiragana, Alignment: stringtable.PhoneticAlignLeft},
+		{Start: 5, BaseStart: 2, BaseLen: 1, Font: 1, Type: stringtable.PhoneticHiragana, Alignment: stringtable.PhoneticAlignLeft},
+	}
+	if fmt.Sprint(ph.Runs) != fmt.Sprint(want) {
+		t.Errorf("Phonetic.Runs = %+v, want %+v", ph.Runs, want)
+	}
+	if rs := st.GetRich(1); rs == nil || len(rs.Runs) != 0 {
+		t.Errorf("GetRich(1) = %+v, want phonetic-only entry", rs)
+	}
+}
+
+// ── Defined names ─────────────────────────────────────────────────────────────
+
+// buildNameRecord encodes a BrtName payload.  itab is 0xFFFFFFFF for workbook
+// scope; comment is written as a null string when empty.
+func buildNameRecord(name string, flags, itab uint32, rgce []byte, comment string) []byte {
+	cmt := biff12Le32(0xFFFFFFFF)
+	if comment != "" {
+		cmt = biff12EncStr(comment)
+	}
+	return concatBytes(biff12Le32(flags), []byte{0}, biff12Le32(itab), biff12EncStr(name),
+		biff12Le32(uint32(len(rgce))), rgce, biff12Le32(0), cmt)
+}
+
+// ptgArea3d encodes an absolute PtgArea3d token.
+func ptgArea3d(ixti uint16, r1, r2 uint32, c1, c2 uint16) []byte {
+	return concatBytes([]byte{0x3B}, biff12Le16(ixti), biff12Le32(r1), biff12Le32(r2), biff12Le16(c1), biff12Le16(c2))
+}
+
+// buildNamesXLSB builds a two-sheet workbook ("Sheet1", "Data Sheet") with an
+// ExternSheet table (ixti 0 → Sheet1, 1 → Data Sheet) and the given BrtName
+// payloads.
+func buildNamesXLSB(t *testing.T, names ...[]byte) []byte {
+	t.Helper()
+	var wb bytes.Buffer
+	biff12WriteRec(&wb, 0x0183, nil)
+	biff12WriteRec(&wb, 0x018F, nil)
+	for i, name := range []string{"Sheet1", "Data Sheet"} {
+		biff12WriteRec(&wb, 0x019C, concatBytes(biff12Le32(0), biff12Le32(uint32(i+1)),
+			biff12EncStr(fmt.Sprintf("rId%d", i+1)), biff12EncStr(name)))
+	}
+	biff12WriteRec(&wb, 0x0190, nil)
+	biff12WriteRec(&wb, 0x02E1, nil)
+	biff12WriteRec(&wb, 0x02E5, nil)
+	biff12WriteRec(&wb, 0x02EA, concatBytes(biff12Le32(2),
+		biff12Le32(0), biff12Le32(0), biff12Le32(0),
+		biff12Le32(0), biff12Le32(1), biff12Le32(1)))
+	biff12WriteRec(&wb, 0x02E2, nil)
+	for _, n := range names {
+		biff12WriteRec(&wb, 0x0027, n)
+	}
+	biff12WriteRec(&wb, 0x0184, nil)
+
+	var ws bytes.Buffer
+	biff12WriteRec(&ws, 0x0181, nil)
+	biff12WriteRec(&ws, 0x0191, nil)
+	biff12WriteRec(&ws, 0x0192, nil)
+	biff12WriteRec(&ws, 0x0182, nil)
+
+	var zipBuf bytes.Buffer
+	zw := zip.NewWriter(&zipBuf)
+	relsXML := `<?xml version="1.0" encoding="UTF-8"?>` +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="worksheet" Target="worksheets/sheet1.bin"/>` +
+		`<Relationship Id="rId2" Type="worksheet" Target="worksheets/sheet2.bin"/>` +
+		`</Relationships>`
+	zipAddFile(t, zw, "xl/_rels/workbook.bin.rels", []byte(relsXML))
+	zipAddFile(t, zw, "xl/workbook.bin", wb.Bytes())
+	zipAddFile(t, zw, "xl/worksheets/sheet1.bin", ws.Bytes())
+	zipAddFile(t, zw, "xl/worksheets/sheet2.bin", ws.Bytes())
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zip close: %v", err)
+	}
+	return zipBuf.Bytes()
+}
+
+func TestDefinedNames(t *testing.T) {
+	rate := make([]byte, 9)
+	rate[0] = 0x1F
+	binary.LittleEndian.PutUint64(rate[1:], math.Float64bits(0.05))
+	data := buildNamesXLSB(t,
+		buildNameRecord("Inputs", 0, 0xFFFFFFFF, ptgArea3d(0, 1, 9, 1, 3), ""),
+		buildNameRecord("Local", 0, 1, concatBytes([]byte{0x3A}, biff12Le16(1), biff12Le32(0), biff12Le16(0)), "scoped"),
+		buildNameRecord("Rate", 0x01, 0xFFFFFFFF, rate, ""),
+		buildNameRecord("Multi", 0, 0xFFFFFFFF, concatBytes(
+			ptgArea3d(0, 0, 1, 0, 0), ptgArea3d(1, 4, 5, 2, 2), []byte{0x10}), ""),
+		buildNameRecord("Total", 0, 0xFFFFFFFF, concatBytes(
+			[]byte{0x43}, biff12Le32(1), []byte{0x19, 0x10}, biff12Le16(0)), ""),
+	)
+	wb, err := workbook.OpenReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("OpenReader: %v", err)
+	}
+	defer wb.Close()
+
+	want := []workbook.DefinedName{
+		{Name: "Inputs", Scope: -1, Formula: "=Sheet1!$B$2:$D$10"},
+		{Name: "Local", Scope: 1, Comment: "scoped", Formula: "='Data Sheet'!$A$1"},
+		{Name: "Rate", Scope: -1, Hidden: true, Formula: "=0.05"},
+		{Name: "Multi", Scope: -1, Formula: "=Sheet1!$A$1:$A$2,'Data Sheet'!$C$5:$C$6"},
+		{Name: "Total", Scope: -1, Formula: "=SUM(Inputs)"},
+	}
+	got := wb.DefinedNames()
+	if len(got) != len(want) {
+		t.Fatalf("DefinedNames returned %d names, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("DefinedNames[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	area, err := wb.ResolveName("inputs")
+	if err != nil {
+		t.Fatalf("ResolveName(inputs): %v", err)
+	}
+	if want := (workbook.Area{Sheet: "Sheet1", R1: 1, C1: 1, R2: 9, C2: 3}); area != want {
+		t.Errorf("ResolveName(inputs) = %+v, want %+v", area, want)
+	}
+	area, err = wb.ResolveName("'Data Sheet'!Local")
+	if err != nil {
+		t.Fatalf("ResolveName('Data Sheet'!Local): %v", err)
+	}
+	if want := (workbook.Area{Sheet: "Data Sheet"}); area != want {
+		t.Errorf("ResolveName('Data Sheet'!Local) = %+v, want %+v", area, want)
+	}
+	for _, name := range []string{"Local", "Rate", "Multi", "Nope", "Nosheet!Local"} {
+		if _, err := wb.ResolveName(name); err == nil {
+			t.Errorf("ResolveName(%q) succeeded, want error", name)
+		}
+	}
+}
+
+// ── Range / NamedRange ────────────────────────────────────────────────────────
+
+// buildRangeXLSB builds a workbook with one sheet named "Q1 Sales" (no
+// DIMENSION record) holding r*10+c in A1:C5, except B3 which is absent, and a
+// workbook-scoped name "Block" referring to $B$2:$C$3.
+func buildRangeXLSB(t *testing.T) []byte {
+	t.Helper()
+	var wb bytes.Buffer
+	biff12WriteRec(&wb, 0x0183, nil)
+	biff12WriteRec(&wb, 0x018F, nil)
+	biff12WriteRec(&wb, 0x019C, concatBytes(biff12Le32(0), biff12Le32(1),
+		biff12EncStr("rId1"), biff12EncStr("Q1 Sales")))
+	biff12WriteRec(&wb, 0x0190, nil)
+	biff12WriteRec(&wb, 0x02E1, nil)
+	biff12WriteRec(&wb, 0x02E5, nil)
+	biff12WriteRec(&wb, 0x02EA, concatBytes(biff12Le32(1), biff12Le32(0), biff12Le32(0), biff12Le32(0)))
+	biff12WriteRec(&wb, 0x02E2, nil)
+	biff12WriteRec(&wb, 0x0027, buildNameRecord("Block", 0, 0xFFFFFFFF, ptgArea3d(0, 1, 2, 1, 2), ""))
+	biff12WriteRec(&wb, 0x0184, nil)
+
+	var ws bytes.Buffer
+	biff12WriteRec(&ws, 0x0181, nil)
+	biff12WriteRec(&ws, 0x0191, nil)
+	for r := range 5 {
+		biff12WriteRec(&ws, 0x0000, biff12Le32(uint32(r)))
+		for c := range 3 {
+			if r == 2 && c == 1 {
+				// A blank record with the default style.
+				biff12WriteRec(&ws, 0x0001, concatBytes(biff12Le32(uint32(c)), biff12Le32(0)))
+				continue
+			}
+			var v [8]byte
+			binary.LittleEndian.PutUint64(v[:], math.Float64bits(float64(r*10+c)))
+			biff12WriteRec(&ws, 0x0005, concatBytes(biff12Le32(uint32(c)), biff12Le32(0), v[:]))
+		}
+	}
+	biff12WriteRec(&ws, 0x0192, nil)
+	biff12WriteRec(&ws, 0x0182, nil)
+	return buildSheetXLSB(t, ws.Bytes(), map[string][]byte{"xl/workbook.bin": wb.Bytes()})
+}
+
+// gridValues returns the V of every grid cell, checking that each cell's
+// absolute position matches its grid slot.
+func gridValues(t *testing.T, grid [][]worksheet.Cell, r0, c0 int) [][]any {
+	t.Helper()
+	out := make([][]any, len(grid))
+	for i, row := range grid {
+		for j, c := range row {
+			if c.R != r0+i || c.C != c0+j {
+				t.Errorf("grid[%d][%d] at (%d,%d), want (%d,%d)", i, j, c.R, c.C, r0+i, c0+j)
+			}
+			out[i] = append(out[i], c.V)
+		}
+	}
+	return out
+}
+
+func TestWorkbookRange(t *testing.T) {
+	data := buildRangeXLSB(t)
+	wb, err := workbook.OpenReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("OpenReader: %v", err)
+	}
+	defer wb.Close()
+
+	tests := []struct {
+		ref    string
+		r0, c0 int
+		want   [][]any
+	}{
+		{"'Q1 Sales'!B2:C3", 1, 1, [][]any{{11.0, 12.0}, {nil, 22.0}}},
+		{"'q1 sales'!$C$3:$B$2", 1, 1, [][]any{{11.0, 12.0}, {nil, 22.0}}},
+		{"'Q1 Sales'!$A$1", 0, 0, [][]any{{0.0}}},
+		{"'Q1 Sales'!A:A", 0, 0, [][]any{{0.0}, {10.0}, {20.0}, {30.0}, {40.0}}},
+		{"'Q1 Sales'!3:3", 2, 0, [][]any{{20.0, nil, 22.0}}},
+		{"'Q1 Sales'!B2:XFD1048576", 1, 1, [][]any{{11.0, 12.0}, {nil, 22.0}, {31.0, 32.0}, {41.0, 42.0}}},
+		{"'Q1 Sales'!E7:F7", 6, 4, [][]any{}},
+	}
+	for _, tc := range tests {
+		grid, err := wb.Range(tc.ref)
+		if err != nil {
+			t.Errorf("Range(%q): %v", tc.ref, err)
+			continue
+		}
+		if got := gridValues(t, grid, tc.r0, tc.c0); fmt.Sprint(got) != fmt.Sprint(tc.want) {
+			t.Errorf("Range(%q) = %v, want %v", tc.ref, got, tc.want)
+		}
+	}
+
+	// Blank records are kept as Rows reports them; only positions without a
+	// record are filled in.
+	grid, err := wb.Range("'Q1 Sales'!B3:D3")
+	if err != nil {
+		t.Fatalf("Range(B3:D3): %v", err)
+	}
+	if len(grid) != 1 || len(grid[0]) != 2 || grid[0][0].Kind != worksheet.KindBlank || grid[0][1].Kind != worksheet.KindNumber {
+		t.Errorf("Range(B3:D3) = %+v, want a blank B3 and a number C3", grid)
+	}
+
+	// A range far down the sheet is reached through the row index.
+	num := func(col uint32, v float64) []byte {
+		var b [8]byte
+		binary.LittleEndian.PutUint64(b[:], math.Float64bits(v))
+		return concatBytes(biff12Le32(col), biff12Le32(0), b[:])
+	}
+	var ws bytes.Buffer
+	biff12WriteRec(&ws, 0x0181, nil)
+	biff12WriteRec(&ws, 0x0191, nil)
+	for _, r := range []uint32{0, 99998, 99999, 100000} {
+		biff12WriteRec(&ws, 0x0000, biff12Le32(r))
+		biff12WriteRec(&ws, 0x0005, num(1, float64(r)))
+	}
+	biff12WriteRec(&ws, 0x0192, nil)
+	biff12WriteRec(&ws, 0x0182, nil)
+	deep := buildSheetXLSB(t, ws.Bytes(), nil)
+	dwb, err := workbook.OpenReader(bytes.NewReader(deep), int64(len(deep)))
+	if err != nil {
+		t.Fatalf("OpenReader: %v", err)
+	}
+	defer dwb.Close()
+	grid, err = dwb.Range("Sheet1!A100000:C100000")
+	if err != nil {
+		t.Fatalf("Range(A100000:C100000): %v", err)
+	}
+	if got := gridValues(t, grid, 99999, 0); fmt.Sprint(got) != fmt.Sprint([][]any{{nil, 99999.0}}) {
+		t.Errorf("Range(A100000:C100000) = %v", got)
+	}
+
+	grid, err = wb.NamedRange("block")
+	if err != nil {
+		t.Fatalf("NamedRange(block): %v", err)
+	}
+	if got := gridValues(t, grid, 1, 1); fmt.Sprint(got) != fmt.Sprint([][]any{{11.0, 12.0}, {nil, 22.0}}) {
+		t.Errorf("NamedRange(block) = %v", got)
+	}
+
+	for _, ref := range []string{"A1", "Nope!A1", "'Q1 Sales'!A", "'Q1 Sales'!A1:3", "'Q1 Sales'!A0", "'Q1 Sales'!XFE1", "'Q1 Sales!A1"} {
+		if _, err := wb.Range(ref); err == nil {
+			t.Errorf("Range(%q) succeeded, want error", ref)
+		}
+	}
+}
+
+// ── cellref ───────────────────────────────────────────────────────────────────
+
+func TestCellref(t *testing.T) {
+	for col, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 701: "ZZ", 702: "AAA", cellref.MaxCol: "XFD"} {
+		if got := cellref.ColumnName(col); got != want {
+			t.Errorf("ColumnName(%d) = %q, want %q", col, got, want)
+		}
+		if got, err := cellref.ColumnIndex(want); err != nil || got != col {
+			t.Errorf("ColumnIndex(%q) = %d, %v; want %d", want, got, err, col)
+		}
+	}
+	if _, err := cellref.ColumnIndex("XFE"); err == nil {
+		t.Error("ColumnIndex(XFE) succeeded, want error")
+	}
+
+	ref, err := cellref.ParseRef("$b3")
+	if err != nil || ref != (cellref.Ref{Row: 2, Col: 1, ColAbs: true}) {
+		t.Errorf("ParseRef($b3) = %+v, %v", ref, err)
+	}
+	if got := ref.String(); got != "$B3" {
+		t.Errorf("Ref.String() = %q, want $B3", got)
+	}
+	if got := ref.R1C1(3, 0); got != "R[-1]C2" {
+		t.Errorf("Ref.R1C1 = %q, want R[-1]C2", got)
+	}
+
+	ranges := []struct {
+		in, want string
+		first    cellref.Ref
+		last     cellref.Ref
+	}{
+		{"B2", "B2", cellref.Ref{Row: 1, Col: 1}, cellref.Ref{Row: 1, Col: 1}},
+		{"D10:B2", "B2:D10", cellref.Ref{Row: 1, Col: 1}, cellref.Ref{Row: 9, Col: 3}},
+		{"Sheet1!$A$1:$C$3", "Sheet1!$A$1:$C$3", cellref.Ref{RowAbs: true, ColAbs: true}, cellref.Ref{Row: 2, Col: 2, RowAbs: true, ColAbs: true}},
+		{"'Q1 Sales'!A:B", "'Q1 Sales'!A:B", cellref.Ref{}, cellref.Ref{Row: cellref.MaxRow, Col: 1}},
+		{"'It''s'!$3:5", "'It''s'!$3:5", cellref.Ref{Row: 2, RowAbs: true}, cellref.Ref{Row: 4, Col: cellref.MaxCol}},
+		{"AB12!C1", "'AB12'!C1", cellref.Ref{Col: 2}, cellref.Ref{Col: 2}},
+	}
+	for _, tc := range ranges {
+		rng, err := cellref.ParseRange(tc.in)
+		if err != nil {
+			t.Errorf("ParseRange(%q): %v", tc.in, err)
+			continue
+		}
+		if rng.First != tc.first || rng.Last != tc.last {
+			t.Errorf("ParseRange(%q) = %+v..%+v, want %+v..%+v", tc.in, rng.First, rng.Last, tc.first, tc.last)
+		}
+		if got := rng.String(); got != tc.want {
+			t.Errorf("ParseRange(%q).String() = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+	for _, in := range []string{"", "A", "A0", "A1:3", "1", "'Sheet!A1", "!A1", "A1048577"} {
+		if _, err := cellref.ParseRange(in); err == nil {
+			t.Errorf("ParseRange(%q) succeeded, want error", in)
+		}
+	}
+
+	r1c1 := []struct {
+		in   string
+		want string
+	}{
+		{"R1C1", "$A$1"},
+		{"R[-1]C[2]", "F4"},
+		{"RC", "D5"},
+		{"R2C:R[1]C3", "$C$2:D6"},
+		{"C2:C[1]", "$B:E"},
+		{"Sheet1!R3", "Sheet1!$3:$3"},
+	}
+	for _, tc := range r1c1 {
+		rng, err := cellref.ParseR1C1(tc.in, 4, 3) // base D5
+		if err != nil {
+			t.Errorf("ParseR1C1(%q): %v", tc.in, err)
+			continue
+		}
+		if got := rng.String(); got != tc.want {
+			t.Errorf("ParseR1C1(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+		back, err := cellref.ParseR1C1(rng.R1C1(4, 3), 4, 3)
+		if err != nil || back != rng {
+			t.Errorf("R1C1 round trip of %q: %q → %+v, %v", tc.in, rng.R1C1(4, 3), back, err)
+		}
+	}
+
+	rng, _ := cellref.ParseRange("B2:C3")
+	var visited []string
+	for r, c := range rng.Cells() {
+		visited = append(visited, cellref.CellName(r, c))
+	}
+	if got := fmt.Sprint(visited); got != "[B2 C2 B3 C3]" {
+		t.Errorf("Cells() visited %s", got)
+	}
+	if !rng.Contains(2, 2) || rng.Contains(3, 1) || rng.Rows() != 2 || rng.Cols() != 2 {
+		t.Errorf("Contains/Rows/Cols wrong for %s", rng)
+	}
+}
+
+func TestWorksheetRangeStrings(t *testing.T) {
+	var ws bytes.Buffer
+	biff12WriteRec(&ws, 0x0181, nil)
+	biff12WriteRec(&ws, 0x0194, concatBytes(biff12Le32(0), biff12Le32(9), biff12Le32(0), biff12Le32(2)))
+	biff12WriteRec(&ws, 0x0191, nil)
+	biff12WriteRec(&ws, 0x0192, nil)
+	biff12WriteRec(&ws, 0x01B0, concatBytes(biff12Le32(1), biff12Le32(2), biff12Le32(0), biff12Le32(1)))
+	biff12WriteRec(&ws, 0x03EE, concatBytes(biff12Le32(2), biff12Le32(2), biff12Le32(1), biff12Le32(1),
+		biff12EncStr("rId1"), biff12EncStr(""), biff12EncStr("Visit"), biff12EncStr("Example")))
+	biff12WriteRec(&ws, 0x03EE, concatBytes(biff12Le32(0), biff12Le32(1), biff12Le32(0), biff12Le32(1),
+		biff12Le32(0xFFFFFFFF), biff12EncStr("'Data Sheet'!A1"), biff12EncStr(""), biff12EncStr("")))
+	biff12WriteRec(&ws, 0x0182, nil)
+
+	relsXML := `<?xml version="1.0" encoding="UTF-8"?>` +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="hyperlink" Target="https://example.com" TargetMode="External"/>` +
+		`</Relationships>`
+	data := buildSheetXLSB(t, ws.Bytes(), map[string][]byte{"xl/worksheets/_rels/sheet1.bin.rels": []byte(relsXML)})
+	wb, err := workbook.OpenReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("OpenReader: %v", err)
+	}
+	defer wb.Close()
+	sheet, err := wb.Sheet(1)
+	if err != nil {
+		t.Fatalf("Sheet(1): %v", err)
+	}
+
+	if sheet.Dimension == nil || sheet.Dimension.String() != "A1:C10" {
+		t.Errorf("Dimension = %v, want A1:C10", sheet.Dimension)
+	}
+	if len(sheet.MergeCells) != 1 || sheet.MergeCells[0].String() != "A2:B3" {
+		t.Errorf("MergeCells = %v, want [A2:B3]", sheet.MergeCells)
+	}
+	if got := sheet.Hyperlinks; len(got) != 1 || got[[2]int{2, 1}] != "rId1" {
+		t.Errorf("Hyperlinks = %v, want only B3 → rId1", got)
+	}
+}
+
+// ── Random-access Row / Cell ──────────────────────────────────────────────────
+
+func TestWorksheetRowCell(t *testing.T) {
+	num := func(col uint32, v float64) []byte {
+		var b [8]byte
+		binary.LittleEndian.PutUint64(b[:], math.Float64bits(v))
+		return concatBytes(biff12Le32(col), biff12Le32(0), b[:])
+	}
+	var ws bytes.Buffer
+	biff12WriteRec(&ws, 0x0181, nil)
+	biff12WriteRec(&ws, 0x0194, concatBytes(biff12Le32(0), biff12Le32(999), biff12Le32(0), biff12Le32(1)))
+	biff12WriteRec(&ws, 0x0191, nil)
+	for r := range 1000 {
+		if r%10 == 3 {
+			continue // rows 4, 14, … have no record
+		}
+		biff12WriteRec(&ws, 0x0000, biff12Le32(uint32(r)))
+		biff12WriteRec(&ws, 0x0005, num(0, float64(r)))
+		if r == 500 {
+			// A duplicate ROW record continues the row; E501 lies beyond the
+			// declared Dimension.
+			biff12WriteRec(&ws, 0x0000, biff12Le32(uint32(r)))
+			biff12WriteRec(&ws, 0x0005, num(4, -1))
+		}
+	}
+	biff12WriteRec(&ws, 0x0192, nil)
+	biff12WriteRec(&ws, 0x0182, nil)
+
+	data := buildSheetXLSB(t, ws.Bytes(), nil)
+	wb, err := workbook.OpenReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("OpenReader: %v", err)
+	}
+	defer wb.Close()
+	sheet, err := wb.Sheet(1)
+	if err != nil {
+		t.Fatalf("Sheet(1): %v", err)
+	}
+
+	for _, r := range []int{999, 0, 500, 42} {
+		c, err := sheet.Cell(r, 0)
+		if err != nil || c.V != float64(r) || c.R != r || c.C != 0 {
+			t.Errorf("Cell(%d, 0) = %+v, %v; want V=%d", r, c, err, r)
+		}
+	}
+	row, err := sheet.Row(500)
+	if err != nil || len(row) != 5 || row[4].V != -1.0 || row[0].V != 500.0 {
+		t.Errorf("Row(500) = %+v, %v", row, err)
+	}
+	if c, err := sheet.Cell(500, 3); err != nil || c.V != nil || c.R != 500 || c.C != 3 {
+		t.Errorf("Cell(500, 3) = %+v, %v; want empty D501", c, err)
+	}
+	row, err = sheet.Row(13)
+	if err != nil || len(row) != 2 || row[0].V != nil || row[0].R != 13 {
+		t.Errorf("Row(13) = %+v, %v; want empty row", row, err)
+	}
+	if c, err := sheet.Cell(5000, 9); err != nil || c.V != nil {
+		t.Errorf("Cell(5000, 9) = %+v, %v; want empty cell", c, err)
+	}
+	if _, err := sheet.Cell(-1, 0); err == nil {
+		t.Error("Cell(-1, 0) succeeded, want error")
+	}
+	if _, err := sheet.Cell(0, cellref.MaxCol+1); err == nil {
+		t.Error("Cell(0, MaxCol+1) succeeded, want error")
+	}
+
+	// Shared and array formulas resolve even when the row holding the
+	// anchor is never decoded.
+	data = buildFormulaXLSB(t)
+	wb2, err := workbook.OpenReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("OpenReader: %v", err)
+	}
+	defer wb2.Close()
+	sheet, err = wb2.Sheet(1)
+	if err != nil {
+		t.Fatalf("Sheet(1): %v", err)
+	}
+	for pos, want := range map[[2]int]string{{2, 3}: "{=SUM(A1:A2*B1:B2)}", {1, 2}: "=B2*2", {0, 0}: "=SUM(A1:B3)*Sheet2!$C$4"} {
+		if c, err := sheet.Cell(pos[0], pos[1]); err != nil || c.Formula != want {
+			t.Errorf("Cell%v Formula = %q, %v; want %q", pos, c.Formula, err, want)
+		}
+	}
+}
+
+// ── Row metadata ──────────────────────────────────────────────────────────────
+
+func TestWorksheetRowInfo(t *testing.T) {
+	rowHdr := func(r, ixfe uint32, twips uint16, f1, f2, f3 byte, spans ...uint32) []byte {
+		b := concatBytes(biff12Le32(r), biff12Le32(ixfe), biff12Le16(twips), []byte{f1, f2, f3},
+			biff12Le32(uint32(len(spans)/2)))
+		for _, v := range spans {
+			b = append(b, biff12Le32(v)...)
+		}
+		return b
+	}
+	var ws bytes.Buffer
+	biff12WriteRec(&ws, 0x0181, nil)
+	biff12WriteRec(&ws, 0x0191, nil)
+	biff12WriteRec(&ws, 0x0000, rowHdr(0, 0, 300, 0, 0, 0, 0, 2))
+	// Row 2: custom height 30pt, hidden, outline level 2, custom style XF 5.
+	biff12WriteRec(&ws, 0x0000, rowHdr(2, 5, 600, 0x02, 0x02|0x10|0x20|0x40, 0x01, 0, 0, 3, 7))
+	// Row 3: collapsed summary row at level 1.
+	biff12WriteRec(&ws, 0x0000, rowHdr(3, 0, 300, 0x01, 0x01|0x08, 0))
+	biff12WriteRec(&ws, 0x0000, biff12Le32(4)) // legacy 4-byte ROW record
+	// Row 5: an XF index above MaxInt32 falls back to the default style.
+	biff12WriteRec(&ws, 0x0000, rowHdr(5, 0xFFFFFFFF, 300, 0, 0, 0))
+	biff12WriteRec(&ws, 0x0192, nil)
+	biff12WriteRec(&ws, 0x0182, nil)
+
+	data := buildSheetXLSB(t, ws.Bytes(), nil)
+	wb, err := workbook.OpenReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("OpenReader: %v", err)
+	}
+	defer wb.Close()
+	sheet, err := wb.Sheet(1)
+	if err != nil {
+		t.Fatalf("Sheet(1): %v", err)
+	}
+
+	want := []worksheet.RowInfo{
+		{R: 0, Height: 15, Spans: [][2]int{{0, 2}}},
+		{R: 2, Height: 30, CustomHeight: true, Hidden: true, OutlineLevel: 2, Style: 5, CustomStyle: true,
+			ThickBottom: true, ShowPhonetic: true, Spans: [][2]int{{0, 0}, {3, 7}}},
+		{R: 3, Height: 15, OutlineLevel: 1, Collapsed: true, ThickTop: true},
+		{R: 4},
+		{R: 5, Height: 15},
+	}
+	infos, err := sheet.RowInfos()
+	if err != nil {
+		t.Fatalf("RowInfos: %v", err)
+	}
+	if fmt.Sprintf("%+v", infos) != fmt.Sprintf("%+v", want) {
+		t.Errorf("RowInfos =\n%+v\nwant\n%+v", infos, want)
+	}
+	if info, ok, err := sheet.RowInfo(2); err != nil || !ok || !info.Hidden || info.OutlineLevel != 2 {
+		t.Errorf("RowInfo(2) = %+v, %v, %v", info, ok, err)
+	}
+	if _, ok, err := sheet.RowInfo(1); err != nil || ok {
+		t.Errorf("RowInfo(1) ok = %v, %v; want false", ok, err)
+	}
+}
+
+// ── Column metadata ───────────────────────────────────────────────────────────
+
+func TestWorksheetColumns(t *testing.T) {
+	colInfo := func(c1, c2, width, ixfe uint32, flags uint16) []byte {
+		return concatBytes(biff12Le32(c1), biff12Le32(c2), biff12Le32(width), biff12Le32(ixfe), biff12Le16(flags))
+	}
+	build := func(fmtInfo []byte) *worksheet.Worksheet {
+		t.Helper()
+		var ws bytes.Buffer
+		biff12WriteRec(&ws, 0x0181, nil)
+		if fmtInfo != nil {
+			biff12WriteRec(&ws, 0x03E5, fmtInfo)
+		}
+		biff12WriteRec(&ws, 0x003C, colInfo(0, 0, 20*256, 3, 0x0002|0x0004))
+		biff12WriteRec(&ws, 0x003C, colInfo(2, 4, 9*256, 0, 0x0001|0x0200|0x1000))
+		biff12WriteRec(&ws, 0x003C, concatBytes(biff12Le32(6), biff12Le32(6), biff12Le32(5*256), biff12Le32(0))) // no flags
+		biff12WriteRec(&ws, 0x0191, nil)
+		biff12WriteRec(&ws, 0x0192, nil)
+		biff12WriteRec(&ws, 0x0182, nil)
+		sheet, err := worksheet.New("Sheet1", ws.Bytes(), nil, nil, nil, nil)
+		if err != nil {
+			t.Fatalf("worksheet.New: %v", err)
+		}
+		return sheet
+	}
+
+	sheet := build(nil)
+	want := []worksheet.Col{
+		{C1: 0, C2: 0, Width: 20, Style: 3, CustomWidth: true, BestFit: true},
+		{C1: 2, C2: 4, Width: 9, Hidden: true, OutlineLevel: 2, Collapsed: true},
+		{C1: 6, C2: 6, Width: 5},
+	}
+	if fmt.Sprintf("%+v", sheet.Cols) != fmt.Sprintf("%+v", want) {
+		t.Errorf("Cols =\n%+v\nwant\n%+v", sheet.Cols, want)
+	}
+	if got := sheet.ColumnAt(3); got != want[1] {
+		t.Errorf("ColumnAt(3) = %+v, want %+v", got, want[1])
+	}
+	for c, w := range map[int]float64{0: 20, 1: 8.7109375, 3: 0, 6: 5} {
+		if got := sheet.ColumnWidth(c); got != w {
+			t.Errorf("ColumnWidth(%d) = %v, want %v", c, got, w)
+		}
+	}
+
+	// BrtWsFmtInfo with an explicit default width, then with only a base width.
+	sheet = build(concatBytes(biff12Le32(12*256), biff12Le16(8), biff12Le16(300), biff12Le16(0), []byte{0, 0}))
+	if got := sheet.ColumnAt(1); got != (worksheet.Col{C1: 1, C2: 1, Width: 12}) {
+		t.Errorf("ColumnAt(1) with dxGCol = %+v", got)
+	}
+	sheet = build(concatBytes(biff12Le32(0xFFFFFFFF), biff12Le16(10), biff12Le16(300), biff12Le16(0), []byte{0, 0}))
+	if got := sheet.ColumnWidth(5); got != 10.7109375 {
+		t.Errorf("ColumnWidth(5) with base width 10 = %v, want 10.7109375", got)
+	}
+}
+
+// ── Sheet format defaults ─────────────────────────────────────────────────────
+
+func TestWorksheetFormat(t *testing.T) {
+	build := func(fmtInfo []byte) *worksheet.Worksheet {
+		t.Helper()
+		var ws bytes.Buffer
+		biff12WriteRec(&ws, 0x0181, nil)
+		if fmtInfo != nil {
+			biff12WriteRec(&ws, 0x03E5, fmtInfo)
+		}
+		biff12WriteRec(&ws, 0x0191, nil)
+		biff12WriteRec(&ws, 0x0192, nil)
+		biff12WriteRec(&ws, 0x0182, nil)
+		sheet, err := worksheet.New("Sheet1", ws.Bytes(), nil, nil, nil, nil)
+		if err != nil {
+			t.Fatalf("worksheet.New: %v", err)
+		}
+		return sheet
+	}
+
+	if sheet := build(nil); sheet.Format != nil {
+		t.Errorf("Format = %+v, want nil without SHEETFORMATPR", sheet.Format)
+	}
+
+	// dxGCol unset, base width 8, 18pt custom height, zero-height rows with
+	// thick bottom borders, outline levels 3 (rows) and 1 (columns).
+	sheet := build(concatBytes(biff12Le32(0xFFFFFFFF), biff12Le16(8), biff12Le16(360),
+		biff12Le16(0x0001|0x0002|0x0008), []byte{3, 1}))
+	want := worksheet.SheetFormat{
+		DefaultColWidth:  8.7109375,
+		BaseColWidth:     8,
+		DefaultRowHeight: 18,
+		CustomHeight:     true,
+		ZeroHeight:       true,
+		ThickBottom:      true,
+		OutlineLevelRow:  3,
+		OutlineLevelCol:  1,
+	}
+	if sheet.Format == nil || *sheet.Format != want {
+		t.Errorf("Format = %+v, want %+v", sheet.Format, want)
+	}
+
+	sheet = build(concatBytes(biff12Le32(12*256+128), biff12Le16(10), biff12Le16(300), biff12Le16(0), []byte{0, 0}))
+	if sheet.Format == nil || sheet.Format.DefaultColWidth != 12.5 || sheet.Format.DefaultRowHeight != 15 {
+		t.Errorf("Format = %+v, want 12.5 wide, 15pt rows", sheet.Format)
+	}
+	if got := sheet.ColumnWidth(3); got != 12.5 {
+		t.Errorf("ColumnWidth(3) = %v, want 12.5", got)
+	}
+
+	if sheet := build([]byte{0xFF, 0xFF}); sheet.Format != nil {
+		t.Errorf("truncated SHEETFORMATPR: Format = %+v, want nil", sheet.Format)
+	}
+}
+
+// ── Sheet views ───────────────────────────────────────────────────────────────
+
+func TestWorksheetViews(t *testing.T) {
+	wsView := func(flags uint16, xlView, rwTop, colLeft uint32, icv byte, scales [4]uint16, wbView uint32) []byte {
+		b := concatBytes(biff12Le16(flags), biff12Le32(xlView), biff12Le32(rwTop), biff12Le32(colLeft), []byte{icv, 0, 0, 0})
+		for _, s := range scales {
+			b = append(b, biff12Le16(s)...)
+		}
+		return append(b, biff12Le32(wbView)...)
+	}
+	xnum := func(v float64) []byte {
+		var b [8]byte
+		binary.LittleEndian.PutUint64(b[:], math.Float64bits(v))
+		return b[:]
+	}
+	sel := func(pnn, r, c, iref uint32, rfx ...uint32) []byte {
+		b := concatBytes(biff12Le32(pnn), biff12Le32(r), biff12Le32(c), biff12Le32(iref), biff12Le32(uint32(len(rfx)/4)))
+		for _, v := range rfx {
+			b = append(b, biff12Le32(v)...)
+		}
+		return b
+	}
+
+	var ws bytes.Buffer
+	biff12WriteRec(&ws, 0x0181, nil)
+	biff12WriteRec(&ws, 0x0185, nil) // SHEETVIEWS
+	// View 0: gridlines, headings, zeros, outline symbols, default grid
+	// colour, selected tab; zoom 85%; rows 1-2 and column A frozen.
+	biff12WriteRec(&ws, 0x0189, wsView(0x0004|0x0008|0x0010|0x0040|0x0100|0x0200, 0, 0, 0, 64, [4]uint16{85, 85, 0, 0}, 0))
+	biff12WriteRec(&ws, 0x0197, concatBytes(xnum(1), xnum(2), biff12Le32(2), biff12Le32(1), biff12Le32(0), []byte{0x03}))
+	biff12WriteRec(&ws, 0x0198, sel(3, 0, 0, 0, 0, 0, 0, 0))
+	biff12WriteRec(&ws, 0x0198, sel(0, 4, 2, 1, 2, 2, 1, 1, 4, 6, 2, 3))
+	biff12WriteRec(&ws, 0x018A, nil)
+	// View 1: page layout, right-to-left, gridline colour 10, whitespace
+	// hidden, zoom 0 (default), no panes.
+	biff12WriteRec(&ws, 0x0189, wsView(0x0020|0x0400, 2, 9, 3, 10, [4]uint16{0, 0, 60, 75}, 1))
+	biff12WriteRec(&ws, 0x018A, nil)
+	biff12WriteRec(&ws, 0x0186, nil) // SHEETVIEWS end
+	biff12WriteRec(&ws, 0x0191, nil)
+	biff12WriteRec(&ws, 0x0192, nil)
+	biff12WriteRec(&ws, 0x0182, nil)
+	sheet, err := worksheet.New("Sheet1", ws.Bytes(), nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("worksheet.New: %v", err)
+	}
+	if len(sheet.Views) != 2 {
+		t.Fatalf("len(Views) = %d, want 2", len(sheet.Views))
+	}
+
+	v := sheet.Views[0]
+	if !v.ShowGridLines || !v.ShowHeaders || !v.ShowZeros || !v.ShowOutlineSymbols || !v.TabSelected ||
+		!v.ShowWhitespace || v.ShowFormulas || v.RightToLeft || v.GridColor != -1 || v.Zoom != 85 || v.ZoomNormal != 85 {
+		t.Errorf("Views[0] = %+v", v)
+	}
+	wantPane := worksheet.Pane{XSplit: 1, YSplit: 2, TopRow: 2, LeftCol: 1, Active: worksheet.PaneBottomRight, Frozen: true, FrozenNoSplit: true}
+	if v.Pane == nil || *v.Pane != wantPane {
+		t.Errorf("Views[0].Pane = %+v, want %+v", v.Pane, wantPane)
+	}
+	if v.FrozenRows() != 2 || v.FrozenCols() != 1 {
+		t.Errorf("FrozenRows/FrozenCols = %d/%d, want 2/1", v.FrozenRows(), v.FrozenCols())
+	}
+	if len(v.Selections) != 2 {
+		t.Fatalf("len(Selections) = %d, want 2", len(v.Selections))
+	}
+	s := v.Selections[1]
+	if s.Pane != worksheet.PaneBottomRight || s.ActiveRow != 4 || s.ActiveCol != 2 || s.ActiveRange != 1 ||
+		fmt.Sprint(s.Ranges) != "[B3 C5:D7]" {
+		t.Errorf("Selections[1] = %+v", s)
+	}
+	if v.Selections[0].Pane != worksheet.PaneTopLeft {
+		t.Errorf("Selections[0].Pane = %v, want PaneTopLeft", v.Selections[0].Pane)
+	}
+
+	v = sheet.Views[1]
+	if v.Type != worksheet.ViewPageLayout || !v.RightToLeft || v.ShowWhitespace || v.GridColor != 10 ||
+		v.Zoom != 100 || v.ZoomPageBreakPreview != 60 || v.ZoomPageLayout != 75 || v.TopRow != 9 || v.LeftCol != 3 ||
+		v.WorkbookView != 1 || v.Pane != nil || v.FrozenRows() != 0 {
+		t.Errorf("Views[1] = %+v", v)
+	}
+}
+
+// ── Sheet properties ──────────────────────────────────────────────────────────
+
+func TestSheetProperties(t *testing.T) {
+	wsProp := func(flags uint32, color []byte, rwSync, colSync uint32, codeName string) []byte {
+		return concatBytes([]byte{byte(flags), byte(flags >> 8), byte(flags >> 16)}, color,
+			biff12Le32(rwSync), biff12Le32(colSync), biff12EncStr(codeName))
+	}
+	// Theme colour 4 with tint +0.4 (13107/32767 ≈ 0.4).
+	themeColor := concatBytes([]byte{3 << 1, 4}, biff12Le16(13107), []byte{0, 0, 0, 0xFF})
+	autoColor := make([]byte, 8)
+	build := func(pad int, prop []byte) []byte {
+		var ws bytes.Buffer
+		biff12WriteRec(&ws, 0x0181, nil)
+		if pad > 0 {
+			biff12WriteRec(&ws, 0x0FFF, make([]byte, pad)) // unknown record, skipped
+		}
+		if prop != nil {
+			biff12WriteRec(&ws, 0x0193, prop)
+		}
+		biff12WriteRec(&ws, 0x0191, nil)
+		biff12WriteRec(&ws, 0x0192, nil)
+		biff12WriteRec(&ws, 0x0182, nil)
+		return ws.Bytes()
+	}
+
+	// fRowSumsBelow, fColSumsRight, fFitToPage, fShowOutlineSymbols,
+	// fFilterMode; scrolling synchronised from C4.
+	flags := uint32(1<<6 | 1<<7 | 1<<8 | 1<<10 | 1<<12 | 1<<16)
+	ws, err := worksheet.New("Summary", build(0, wsProp(flags, themeColor, 3, 2, "shtSummary")), nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("worksheet.New: %v", err)
+	}
+	p := ws.Properties
+	if p == nil {
+		t.Fatal("Properties = nil")
+	}
+	if p.CodeName != "shtSummary" || !p.SummaryBelow || !p.SummaryRight || !p.FitToPage || !p.ShowOutlineSymbols ||
+		!p.FilterMode || !p.SyncHorizontal || p.SyncVertical || p.SyncRow != 3 || p.SyncCol != 2 || p.Dialog {
+		t.Errorf("Properties = %+v", p)
+	}
+	if c := p.TabColor; c == nil || c.Type != styles.ColorTheme || c.Index != 4 || math.Abs(c.Tint-0.4) > 1e-4 {
+		t.Errorf("TabColor = %+v, want theme 4 tint 0.4", c)
+	}
+
+	ws, err = worksheet.New("Plain", build(0, wsProp(0, autoColor, 0xFFFFFFFF, 0xFFFFFFFF, "")), nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("worksheet.New: %v", err)
+	}
+	if p := ws.Properties; p == nil || p.TabColor != nil || p.SyncRow != 0 || p.CodeName != "" {
+		t.Errorf("Properties = %+v, want no tab colour", p)
+	}
+
+	// The code name is also available from the workbook, even when SHEETPR
+	// is not within the first few kilobytes of the sheet part.
+	for _, pad := range []int{0, 10000} {
+		data := buildSheetXLSB(t, build(pad, wsProp(flags, themeColor, 0, 0, "shtSummary")), nil)
+		wb, err := workbook.OpenReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatalf("OpenReader: %v", err)
+		}
+		if cn, err := wb.SheetCodeName("sheet1"); err != nil || cn != "shtSummary" {
+			t.Errorf("pad %d: SheetCodeName = %q, %v", pad, cn, err)
+		}
+		if sheet, err := wb.SheetByCodeName("SHTSUMMARY"); err != nil || sheet.Name != "Sheet1" {
+			t.Errorf("pad %d: SheetByCodeName: %v", pad, err)
+		}
+		if _, err := wb.SheetByCodeName("Sheet1"); err == nil {
+			t.Errorf("pad %d: SheetByCodeName(Sheet1) succeeded, want error", pad)
+		}
+		wb.Close()
+	}
+}
+
+// ── Page setup ────────────────────────────────────────────────────────────────
+
+func TestWorksheetPageSetup(t *testing.T) {
+	xnum := func(v float64) []byte {
+		var b [8]byte
+		binary.LittleEndian.PutUint64(b[:], math.Float64bits(v))
+		return b[:]
+	}
+	nullable := func(s string) []byte {
+		if s == "" {
+			return biff12Le32(0xFFFFFFFF)
+		}
+		return biff12EncStr(s)
+	}
+	var ws bytes.Buffer
+	biff12WriteRec(&ws, 0x0181, nil)
+	biff12WriteRec(&ws, 0x0191, nil)
+	biff12WriteRec(&ws, 0x0192, nil)
+	biff12WriteRec(&ws, 0x03DD, biff12Le16(0x0001|0x0008)) // centered horizontally, gridlines
+	biff12WriteRec(&ws, 0x03DC, concatBytes(xnum(0.7), xnum(0.7), xnum(0.75), xnum(0.75), xnum(0.3), xnum(0.3)))
+	// A4, 80%, 600x600 dpi, 2 copies, first page 5, fit 1 wide; landscape,
+	// black and white, comments at end, errors as dashes, first page used.
+	biff12WriteRec(&ws, 0x03DE, concatBytes(biff12Le32(9), biff12Le32(80), biff12Le32(600), biff12Le32(600),
+		biff12Le32(2), biff12Le32(5), biff12Le32(1), biff12Le32(0),
+		biff12Le16(0x0002|0x0008|0x0020|0x0080|0x0100|2<<9), nullable("rId3")))
+	biff12WriteRec(&ws, 0x03DF, concatBytes(biff12Le16(0x0002|0x0004),
+		nullable(`&L&"Arial,Bold"&14Report&C&A&RPage &P of &N`), nullable("&CConfidential && internal"),
+		nullable(""), nullable(""), nullable("&CCover"), nullable("")))
+	biff12WriteRec(&ws, 0x0182, nil)
+	sheet, err := worksheet.New("Sheet1", ws.Bytes(), nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("worksheet.New: %v", err)
+	}
+
+	if o := sheet.PrintOptions; o == nil || *o != (worksheet.PrintOptions{HorizontalCentered: true, GridLines: true}) {
+		t.Errorf("PrintOptions = %+v", o)
+	}
+	if m := sheet.PageMargins; m == nil || *m != (worksheet.PageMargins{Left: 0.7, Right: 0.7, Top: 0.75, Bottom: 0.75, Header: 0.3, Footer: 0.3}) {
+		t.Errorf("PageMargins = %+v", m)
+	}
+	wantPS := worksheet.PageSetup{
+		PaperSize: 9, Orientation: worksheet.OrientationLandscape, Scale: 80, FitToWidth: 1,
+		FirstPageNumber: 5, UseFirstPageNumber: true, Copies: 2, HorizontalDPI: 600, VerticalDPI: 600,
+		BlackAndWhite: true, Comments: worksheet.PrintCommentsAtEnd, Errors: worksheet.PrintErrorsDash,
+		PrinterSettingsRID: "rId3",
+	}
+	if ps := sheet.PageSetup; ps == nil || *ps != wantPS {
+		t.Errorf("PageSetup = %+v, want %+v", ps, wantPS)
+	}
+	// One flag bit at a time, so that neighbouring bits cannot stand in for
+	// each other (fNoColor used to be read as fNoOrient).
+	flagTests := []struct {
+		flags uint16
+		want  worksheet.PageSetup
+	}{
+		{0x0000, worksheet.PageSetup{Orientation: worksheet.OrientationPortrait}},
+		{0x0001, worksheet.PageSetup{Orientation: worksheet.OrientationPortrait, OverThenDown: true}},
+		{0x0002, worksheet.PageSetup{Orientation: worksheet.OrientationLandscape}},
+		{0x0008, worksheet.PageSetup{Orientation: worksheet.OrientationPortrait, BlackAndWhite: true}},
+		{0x0010, worksheet.PageSetup{Orientation: worksheet.OrientationPortrait, Draft: true}},
+		{0x0020, worksheet.PageSetup{Orientation: worksheet.OrientationPortrait, Comments: worksheet.PrintCommentsAsDisplayed}},
+		{0x0042, worksheet.PageSetup{Orientation: worksheet.OrientationDefault}},
+		{0x0080, worksheet.PageSetup{Orientation: worksheet.OrientationPortrait, UseFirstPageNumber: true}},
+		{0x0100, worksheet.PageSetup{Orientation: worksheet.OrientationPortrait}}, // fEndNotes without fNotes
+		{3 << 9, worksheet.PageSetup{Orientation: worksheet.OrientationPortrait, Errors: worksheet.PrintErrorsNA}},
+	}
+	for _, tt := range flagTests {
+		var b bytes.Buffer
+		biff12WriteRec(&b, 0x0181, nil)
+		biff12WriteRec(&b, 0x0191, nil)
+		biff12WriteRec(&b, 0x0192, nil)
+		biff12WriteRec(&b, 0x03DE, concatBytes(make([]byte, 32), biff12Le16(tt.flags), nullable("")))
+		biff12WriteRec(&b, 0x0182, nil)
+		sh, err := worksheet.New("Sheet1", b.Bytes(), nil, nil, nil, nil)
+		if err != nil {
+			t.Fatalf("worksheet.New: %v", err)
+		}
+		if ps := sh.PageSetup; ps == nil || *ps != tt.want {
+			t.Errorf("flags %#04x: PageSetup = %+v, want %+v", tt.flags, ps, tt.want)
+		}
+	}
+
+	hf := sheet.HeaderFooter
+	if hf == nil || !hf.DifferentFirst || !hf.ScaleWithDoc || hf.DifferentOddEven || hf.FirstHeader != "&CCover" || hf.EvenHeader != "" {
+		t.Fatalf("HeaderFooter = %+v", hf)
+	}
+
+	h := worksheet.ParseHeaderFooter(hf.OddHeader)
+	if len(h.Left) != 1 || h.Left[0].Text != "Report" || h.Left[0].Font != (worksheet.HFFont{Name: "Arial", Style: "Bold", Size: 14}) {
+		t.Errorf("Left = %+v", h.Left)
+	}
+	if len(h.Center) != 1 || h.Center[0].Field != worksheet.HFSheetName {
+		t.Errorf("Center = %+v", h.Center)
+	}
+	v := worksheet.HFValues{Page: 3, Pages: 7, Sheet: "Sheet1", Time: time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)}
+	if got := v.Render(h.Right); got != "Page 3 of 7" {
+		t.Errorf("Render(Right) = %q", got)
+	}
+	if got := v.Render(worksheet.ParseHeaderFooter(hf.OddFooter).Center); got != "Confidential & internal" {
+		t.Errorf("Render(footer) = %q", got)
+	}
+
+	parts := worksheet.ParseHeaderFooter("&BBold&B plain &KFF0000red &P+1 &D &T &Q").Center
+	if got := v.Render(parts); got != "Bold plain red 4 2026-03-01 09:30 &Q" {
+		t.Errorf("Render = %q", got)
+	}
+	if !parts[0].Font.Bold || parts[1].Font.Bold || parts[2].Font.Color != "FF0000" || parts[3].Offset != 1 {
+		t.Errorf("parts = %+v", parts)
+	}
+}
+
+// ── Print area, titles and page breaks ────────────────────────────────────────
+
+func TestWorksheetPrintSettings(t *testing.T) {
+	union := []byte{0x10}
+	data := buildNamesXLSB(t,
+		buildNameRecord("_xlnm.Print_Area", 0x20, 1, concatBytes(
+			ptgArea3d(1, 0, 9, 0, 3), ptgArea3d(1, 20, 29, 0, 3), union), ""),
+		buildNameRecord("_xlnm.Print_Titles", 0x20, 1, concatBytes(
+			ptgArea3d(1, 0, 0xFFFFF, 0, 0), ptgArea3d(1, 0, 1, 0, 0x3FFF), union), ""),
+		// Scoped to Sheet1 but pointing at another sheet: ignored.
+		buildNameRecord("_xlnm.Print_Area", 0x20, 0, ptgArea3d(1, 0, 0, 0, 0), ""),
+	)
+	wb, err := workbook.OpenReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("OpenReader: %v", err)
+	}
+	defer wb.Close()
+
+	sheet, err := wb.SheetByName("Data Sheet")
+	if err != nil {
+		t.Fatalf("SheetByName: %v", err)
+	}
+	var got []string
+	for _, r := range sheet.PrintArea() {
+		got = append(got, r.String())
+	}
+	if want := []string{"'Data Sheet'!$A$1:$D$10", "'Data Sheet'!$A$21:$D$30"}; !slices.Equal(got, want) {
+		t.Errorf("PrintArea = %q, want %q", got, want)
+	}
+	pt := sheet.PrintTitles()
+	if pt.Rows == nil || pt.Rows.String() != "'Data Sheet'!$1:$2" {
+		t.Errorf("PrintTitles.Rows = %v", pt.Rows)
+	}
+	if pt.Cols == nil || pt.Cols.String() != "'Data Sheet'!$A:$A" {
+		t.Errorf("PrintTitles.Cols = %v", pt.Cols)
+	}
+
+	sheet1, err := wb.SheetByName("Sheet1")
+	if err != nil {
+		t.Fatalf("SheetByName: %v", err)
+	}
+	if a, pt := sheet1.PrintArea(), sheet1.PrintTitles(); a != nil || pt.Rows != nil || pt.Cols != nil {
+		t.Errorf("Sheet1: PrintArea = %v, PrintTitles = %+v", a, pt)
+	}
+
+	brk := func(at, lo, hi, man uint32) []byte {
+		return concatBytes(biff12Le32(at), biff12Le32(lo), biff12Le32(hi), biff12Le32(man), biff12Le32(0))
+	}
+	var ws bytes.Buffer
+	biff12WriteRec(&ws, 0x0181, nil)
+	biff12WriteRec(&ws, 0x0191, nil)
+	biff12WriteRec(&ws, 0x0192, nil)
+	biff12WriteRec(&ws, 0x0388, concatBytes(biff12Le32(2), biff12Le32(2)))
+	biff12WriteRec(&ws, 0x038C, brk(10, 0, 0x3FFF, 1))
+	biff12WriteRec(&ws, 0x038C, brk(25, 0, 0x3FFF, 1))
+	biff12WriteRec(&ws, 0x0389, nil)
+	biff12WriteRec(&ws, 0x038A, concatBytes(biff12Le32(1), biff12Le32(0)))
+	biff12WriteRec(&ws, 0x038C, brk(4, 0, 0xFFFFF, 0))
+	biff12WriteRec(&ws, 0x038C, brk(5, 0, 0x100000, 1)) // out of range: dropped
+	biff12WriteRec(&ws, 0x038B, nil)
+	biff12WriteRec(&ws, 0x038C, brk(7, 0, 0, 1)) // outside a collection: ignored
+	biff12WriteRec(&ws, 0x0182, nil)
+	s, err := worksheet.New("Sheet1", ws.Bytes(), nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("worksheet.New: %v", err)
+	}
+	pb := s.PageBreaks()
+	wantRows := []worksheet.PageBreak{{At: 10, Max: 0x3FFF, Manual: true}, {At: 25, Max: 0x3FFF, Manual: true}}
+	if !slices.Equal(pb.Rows, wantRows) {
+		t.Errorf("PageBreaks.Rows = %+v, want %+v", pb.Rows, wantRows)
+	}
+	if wantCols := []worksheet.PageBreak{{At: 4, Max: 0xFFFFF}}; !slices.Equal(pb.Cols, wantCols) {
+		t.Errorf("PageBreaks.Cols = %+v, want %+v", pb.Cols, wantCols)
+	}
+	if a := s.PrintArea(); a != nil {
+		t.Errorf("PrintArea without workbook = %v", a)
+	}
+}
+
+// ── Protection ────────────────────────────────────────────────────────────────
+
+func TestProtection(t *testing.T) {
+	blob := func(b []byte) []byte { return concatBytes(biff12Le32(uint32(len(b))), b) }
+	isoPwd := func(hash, salt []byte, alg string) []byte {
+		return concatBytes(blob(hash), blob(salt), biff12EncStr(alg))
+	}
+	flags := func(v ...uint32) []byte {
+		var b []byte
+		for _, f := range v {
+			b = append(b, biff12Le32(f)...)
+		}
+		return b
+	}
+	// Default Excel protection: everything blocked except selecting cells.
+	defaults := flags(1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 0, 1, 1, 1, 0)
+	hash, salt := []byte{1, 2, 3, 4}, []byte{9, 8}
+
+	var ws bytes.Buffer
+	biff12WriteRec(&ws, 0x0181, nil)
+	biff12WriteRec(&ws, 0x0191, nil)
+	biff12WriteRec(&ws, 0x0192, nil)
+	biff12WriteRec(&ws, 0x0896, concatBytes(biff12Le32(100000), defaults, isoPwd(hash, salt, "SHA-512")))
+	biff12WriteRec(&ws, 0x0498, concatBytes(biff12Le16(0xCC3D), biff12Le32(1),
+		biff12Le32(1), biff12Le32(4), biff12Le32(1), biff12Le32(2), biff12EncStr("Inputs"), biff12Le32(0)))
+	biff12WriteRec(&ws, 0x0897, concatBytes(biff12Le32(2),
+		biff12Le32(0), biff12Le32(0), biff12Le32(0), biff12Le32(0),
+		biff12Le32(9), biff12Le32(9), biff12Le32(3), biff12Le32(3),
+		biff12EncStr("Notes"), blob([]byte{0xAA}), biff12Le32(1000), isoPwd(hash, nil, "SHA-256")))
+	biff12WriteRec(&ws, 0x0182, nil)
+
+	var wbBin bytes.Buffer
+	biff12WriteRec(&wbBin, 0x0183, nil)
+	biff12WriteRec(&wbBin, 0x0895, concatBytes(biff12Le32(100000), biff12Le32(0), biff12Le16(0x0001),
+		isoPwd(hash, salt, "SHA-512"), isoPwd(nil, nil, "")))
+	biff12WriteRec(&wbBin, 0x018F, nil)
+	biff12WriteRec(&wbBin, 0x019C, concatBytes(biff12Le32(0), biff12Le32(1),
+		biff12EncStr("rId1"), biff12EncStr("Sheet1")))
+	biff12WriteRec(&wbBin, 0x0190, nil)
+	biff12WriteRec(&wbBin, 0x0184, nil)
+
+	// xf[0] locked (bit 12), xf[1] unlocked and hidden (bit 13), xf[2] short.
+	var st bytes.Buffer
+	biff12WriteRec(&st, 0x0296, nil)
+	biff12WriteRec(&st, 0x04E9, nil)
+	biff12WriteRec(&st, 0x002F, concatBytes(make([]byte, 12), biff12Le16(0x1000), make([]byte, 2)))
+	biff12WriteRec(&st, 0x002F, concatBytes(make([]byte, 12), biff12Le16(0x2000), make([]byte, 2)))
+	biff12WriteRec(&st, 0x002F, make([]byte, 4))
+	biff12WriteRec(&st, 0x04EA, nil)
+	biff12WriteRec(&st, 0x0297, nil)
+
+	data := buildSheetXLSB(t, ws.Bytes(), map[string][]byte{
+		"xl/workbook.bin": wbBin.Bytes(),
+		"xl/styles.bin":   st.Bytes(),
+	})
+	wb, err := workbook.OpenReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("OpenReader: %v", err)
+	}
+	defer wb.Close()
+
+	bp := wb.Protection
+	if bp == nil || !bp.LockStructure || bp.LockWindows || bp.LockRevision {
+		t.Fatalf("Protection = %+v", bp)
+	}
+	if p := bp.Password; p.Algorithm != "SHA-512" || p.SpinCount != 100000 || !bytes.Equal(p.Hash, hash) || !bytes.Equal(p.Salt, salt) || !p.IsSet() {
+		t.Errorf("Protection.Password = %+v", p)
+	}
+	if bp.RevisionsPassword.IsSet() {
+		t.Errorf("RevisionsPassword = %+v, want unset", bp.RevisionsPassword)
+	}
+
+	sheet, err := wb.Sheet(1)
+	if err != nil {
+		t.Fatalf("Sheet: %v", err)
+	}
+	sp := sheet.Protection
+	if sp == nil || !sp.Protected || sp.Password.Algorithm != "SHA-512" || sp.Password.SpinCount != 100000 {
+		t.Fatalf("Protection = %+v", sp)
+	}
+	if !sp.AllowSelectLockedCells || !sp.AllowSelectUnlockedCells || sp.AllowFormatCells || sp.AllowSort || sp.AllowEditObjects {
+		t.Errorf("Protection allows = %+v", sp)
+	}
+
+	if len(sheet.ProtectedRanges) != 2 {
+		t.Fatalf("ProtectedRanges = %+v", sheet.ProtectedRanges)
+	}
+	pr := sheet.ProtectedRanges[0]
+	if pr.Name != "Inputs" || len(pr.Ranges) != 1 || pr.Ranges[0].String() != "B2:C5" || pr.Password.Legacy != 0xCC3D || pr.SecurityDescriptor != nil {
+		t.Errorf("ProtectedRanges[0] = %+v", pr)
+	}
+	pr = sheet.ProtectedRanges[1]
+	if pr.Name != "Notes" || len(pr.Ranges) != 2 || pr.Ranges[1].String() != "D10" ||
+		pr.Password.Algorithm != "SHA-256" || pr.Password.SpinCount != 1000 || !bytes.Equal(pr.SecurityDescriptor, []byte{0xAA}) {
+		t.Errorf("ProtectedRanges[1] = %+v", pr)
+	}
+
+	// Legacy sheet protection with nothing blocked but the contents.
+	var ws2 bytes.Buffer
+	biff12WriteRec(&ws2, 0x0181, nil)
+	biff12WriteRec(&ws2, 0x0497, concatBytes(biff12Le16(0x83AF), flags(1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0)))
+	biff12WriteRec(&ws2, 0x0182, nil)
+	s2, err := worksheet.New("Sheet1", ws2.Bytes(), nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("worksheet.New: %v", err)
+	}
+	if p := s2.Protection; p == nil || !p.Protected || p.Password.Legacy != 0x83AF || p.Password.Algorithm != "" || !p.AllowFormatCells || !p.AllowPivotTables {
+		t.Errorf("legacy Protection = %+v", p)
+	}
+
+	want := []struct{ locked, hidden bool }{{true, false}, {false, true}, {true, false}}
+	for i, w := range want {
+		if xf := wb.Styles[i]; xf.Locked != w.locked || xf.Hidden != w.hidden {
+			t.Errorf("Styles[%d] locked/hidden = %v/%v, want %v/%v", i, xf.Locked, xf.Hidden, w.locked, w.hidden)
+		}
+	}
+}
+
+// ── Encrypted workbooks ───────────────────────────────────────────────────────
+
+// cfbStream is one root-level stream of a compound file built by buildCFB.
+type cfbStream struct {
+	name string
+	data []byte
+}
+
+// buildCFB assembles a version 3 compound file (512-byte sectors) holding
+// the given streams under the root storage.  Streams shorter than 4096 bytes
+// go to the mini stream, as MS-CFB requires.
+func buildCFB(t *testing.T, streams ...cfbStream) []byte {
+	t.Helper()
+	const (
+		sect     = 512
+		mini     = 64
+		free     = 0xFFFFFFFF
+		eoc      = 0xFFFFFFFE
+		fatSect  = 0xFFFFFFFD
+		noStream = 0xFFFFFFFF
+	)
+	nSect := func(n, size int) int { return (n + size - 1) / size }
+
+	var miniStream []byte
+	var miniFAT []uint32
+	starts := make([]uint32, len(streams))
+	var big [][]byte
+	for i, s := range streams {
+		if len(s.data) >= 4096 {
+			big = append(big, s.data)
+			continue
+		}
+		first := len(miniFAT)
+		starts[i] = uint32(first)
+		n := nSect(len(s.data), mini)
+		for j := range n {
+			next := uint32(first + j + 1)
+			if j == n-1 {
+				next = eoc
+			}
+			miniFAT = append(miniFAT, next)
+		}
+		miniStream = append(miniStream, s.data...)
+		miniStream = append(miniStream, make([]byte, n*mini-len(s.data))...)
+	}
+
+	dirS := nSect(len(streams)+1, 4)
+	mfS := nSect(len(miniFAT)*4, sect)
+	msS := nSect(len(miniStream), sect)
+	bigS := 0
+	for _, b := range big {
+		bigS += nSect(len(b), sect)
+	}
+	fatS := 1
+	for fatS*(sect/4) < fatS+dirS+mfS+msS+bigS {
+		fatS++
+	}
+	fat := make([]uint32, fatS*(sect/4))
+	for i := range fat {
+		fat[i] = free
+	}
+	next := uint32(fatS)
+	chain := func(n int) uint32 {
+		if n == 0 {
+			return eoc
+		}
+		first := next
+		for j := range n {
+			fat[first+uint32(j)] = first + uint32(j) + 1
+		}
+		fat[first+uint32(n)-1] = eoc
+		next += uint32(n)
+		return first
+	}
+	for i := range fatS {
+		fat[i] = fatSect
+	}
+	dirStart := chain(dirS)
+	mfStart := chain(mfS)
+	msStart := chain(msS)
+	bi := 0
+	for i, s := range streams {
+		if len(s.data) >= 4096 {
+			starts[i] = chain(nSect(len(big[bi]), sect))
+			bi++
+		}
+	}
+
+	var out bytes.Buffer
+	hdr := make([]byte, sect)
+	copy(hdr, []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1})
+	binary.LittleEndian.PutUint16(hdr[0x18:], 0x3E)
+	binary.LittleEndian.PutUint16(hdr[0x1A:], 3)
+	binary.LittleEndian.PutUint16(hdr[0x1C:], 0xFFFE)
+	binary.LittleEndian.PutUint16(hdr[0x1E:], 9)
+	binary.LittleEndian.PutUint16(hdr[0x20:], 6)
+	binary.LittleEndian.PutUint32(hdr[0x2C:], uint32(fatS))
+	binary.LittleEndian.PutUint32(hdr[0x30:], dirStart)
+	binary.LittleEndian.PutUint32(hdr[0x38:], 4096)
+	binary.LittleEndian.PutUint32(hdr[0x3C:], mfStart)
+	binary.LittleEndian.PutUint32(hdr[0x40:], uint32(mfS))
+	binary.LittleEndian.PutUint32(hdr[0x44:], eoc)
+	for i := range 109 {
+		v := uint32(free)
+		if i < fatS {
+			v = uint32(i)
+		}
+		binary.LittleEndian.PutUint32(hdr[0x4C+4*i:], v)
+	}
+	out.Write(hdr)
+
+	pad := func() {
+		if r := out.Len() % sect; r != 0 {
+			out.Write(make([]byte, sect-r))
+		}
+	}
+	for _, v := range fat {
+		out.Write(biff12Le32(v))
+	}
+	dirEntry := func(name string, typ byte, child, right, start uint32, size int) {
+		e := make([]byte, 128)
+		u := utf16.Encode([]rune(name))
+		for i, c := range u {
+			binary.LittleEndian.PutUint16(e[2*i:], c)
+		}
+		if name != "" {
+			binary.LittleEndian.PutUint16(e[64:], uint16(2*len(u)+2))
+		}
+		e[66], e[67] = typ, 1
+		binary.LittleEndian.PutUint32(e[68:], noStream)
+		binary.LittleEndian.PutUint32(e[72:], right)
+		binary.LittleEndian.PutUint32(e[76:], child)
+		binary.LittleEndian.PutUint32(e[116:], start)
+		binary.LittleEndian.PutUint32(e[120:], uint32(size))
+		out.Write(e)
+	}
+	dirEntry("Root Entry", 5, 1, noStream, msStart, len(miniStream))
+	for i, s := range streams {
+		right := uint32(i + 2)
+		if i == len(streams)-1 {
+			right = noStream
+		}
+		dirEntry(s.name, 2, noStream, right, starts[i], len(s.data))
+	}
+	for range dirS*4 - len(streams) - 1 {
+		dirEntry("", 0, noStream, noStream, 0, 0)
+	}
+	for _, v := range miniFAT {
+		out.Write(biff12Le32(v))
+	}
+	pad()
+	out.Write(miniStream)
+	pad()
+	for _, b := range big {
+		out.Write(b)
+		pad()
+	}
+	return out.Bytes()
+}
+
+// utf16le encodes s as UTF-16LE.
+func utf16le(s string) []byte {
+	var b []byte
+	for _, c := range utf16.Encode([]rune(s)) {
+		b = binary.LittleEndian.AppendUint16(b, c)
+	}
+	return b
+}
+
+// iterHash computes H(salt + password) rehashed n times with the iteration
+// number prefixed, the key-derivation core of MS-OFFCRYPTO.
+func iterHash(newHash func() hash.Hash, salt, password []byte, n int) []byte {
+	h := newHash()
+	h.Write(salt)
+	h.Write(password)
+	sum := h.Sum(nil)
+	for i := range n {
+		h.Reset()
+		h.Write(biff12Le32(uint32(i)))
+		h.Write(sum)
+		sum = h.Sum(nil)
+	}
+	return sum
+}
+
+// encryptAgile wraps pkg in a compound file using Agile encryption with
+// AES-256 and SHA-512.
+func encryptAgile(t *testing.T, pkg []byte, password string) []byte {
+	t.Helper()
+	keySalt := bytes.Repeat([]byte{0x11}, 16)
+	pwSalt := bytes.Repeat([]byte{0x22}, 16)
+	secret := bytes.Repeat([]byte{0x33}, 32)
+	verifier := bytes.Repeat([]byte{0x44}, 16)
+	const spin = 1000
+
+	cbc := func(key, iv, data []byte) []byte {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data = append([]byte(nil), data...)
+		if r := len(data) % 16; r != 0 {
+			data = append(data, make([]byte, 16-r)...)
+		}
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(data, data)
+		return data
+	}
+	h := iterHash(sha512.New, pwSalt, utf16le(password), spin)
+	keyFor := func(blockKey []byte) []byte {
+		s := sha512.Sum512(append(append([]byte(nil), h...), blockKey...))
+		return s[:32]
+	}
+	verifierHash := sha512.Sum512(verifier)
+	encInput := cbc(keyFor([]byte{0xFE, 0xA7, 0xD2, 0x76, 0x3B, 0x4B, 0x9E, 0x79}), pwSalt, verifier)
+	encValue := cbc(keyFor([]byte{0xD7, 0xAA, 0x0F, 0x6D, 0x30, 0x61, 0x34, 0x4E}), pwSalt, verifierHash[:])
+	encKey := cbc(keyFor([]byte{0x14, 0x6E, 0x0B, 0xE7, 0xAB, 0xAC, 0xD0, 0xD6}), pwSalt, secret)
+
+	encPkg := binary.LittleEndian.AppendUint64(nil, uint64(len(pkg)))
+	for i := 0; i*4096 < len(pkg); i++ {
+		seg := pkg[i*4096 : min((i+1)*4096, len(pkg))]
+		iv := sha512.Sum512(append(append([]byte(nil), keySalt...), biff12Le32(uint32(i))...))
+		encPkg = append(encPkg, cbc(secret, iv[:16], seg)...)
+	}
+
+	b64 := base64.StdEncoding.EncodeToString
+	params := `saltSize="16" blockSize="16" keyBits="256" hashSize="64" cipherAlgorithm="AES" ` +
+		`cipherChaining="ChainingModeCBC" hashAlgorithm="SHA512" `
+	xmlInfo := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<encryption xmlns="http://schemas.microsoft.com/office/2006/encryption" ` +
+		`xmlns:p="http://schemas.microsoft.com/office/2006/keyEncryptor/password">` +
+		`<keyData ` + params + `saltValue="` + b64(keySalt) + `"/>` +
+		`<keyEncryptors><keyEncryptor uri="http://schemas.microsoft.com/office/2006/keyEncryptor/password">` +
+		`<p:encryptedKey spinCount="` + fmt.Sprint(spin) + `" ` + params + `saltValue="` + b64(pwSalt) + `" ` +
+		`encryptedVerifierHashInput="` + b64(encInput) + `" encryptedVerifierHashValue="` + b64(encValue) + `" ` +
+		`encryptedKeyValue="` + b64(encKey) + `"/></keyEncryptor></keyEncryptors></encryption>`
+	info := concatBytes(biff12Le16(4), biff12Le16(4), biff12Le32(0x40), []byte(xmlInfo))
+	return buildCFB(t, cfbStream{"EncryptionInfo", info}, cfbStream{"EncryptedPackage", encPkg})
+}
+
+// encryptStandard wraps pkg in a compound file using Standard encryption
+// with AES-128.
+func encryptStandard(t *testing.T, pkg []byte, password string) []byte {
+	t.Helper()
+	salt := bytes.Repeat([]byte{0x55}, 16)
+	verifier := bytes.Repeat([]byte{0x66}, 16)
+
+	h := iterHash(sha1.New, salt, utf16le(password), 50000)
+	hf := sha1.Sum(append(h, biff12Le32(0)...))
+	var buf1, buf2 [64]byte
+	for i := range buf1 {
+		buf1[i], buf2[i] = 0x36, 0x5C
+		if i < len(hf) {
+			buf1[i] ^= hf[i]
+			buf2[i] ^= hf[i]
+		}
+	}
+	x1 := sha1.Sum(buf1[:])
+	key := x1[:16]
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecb := func(data []byte) []byte {
+		out := append([]byte(nil), data...)
+		if r := len(out) % 16; r != 0 {
+			out = append(out, make([]byte, 16-r)...)
+		}
+		for i := 0; i < len(out); i += 16 {
+			block.Encrypt(out[i:i+16], out[i:i+16])
+		}
+		return out
+	}
+	vh := sha1.Sum(verifier)
+
+	csp := utf16le("Microsoft Enhanced RSA and AES Cryptographic Provider\x00")
+	hdr := concatBytes(biff12Le32(0x24), biff12Le32(0), biff12Le32(0x660E), biff12Le32(0x8004),
+		biff12Le32(128), biff12Le32(0x18), biff12Le32(0), biff12Le32(0), csp)
+	info := concatBytes(biff12Le16(3), biff12Le16(2), biff12Le32(0x24), biff12Le32(uint32(len(hdr))), hdr,
+		biff12Le32(16), salt, ecb(verifier), biff12Le32(20), ecb(vh[:]))
+	encPkg := concatBytes(binary.LittleEndian.AppendUint64(nil, uint64(len(pkg))), ecb(pkg))
+	return buildCFB(t, cfbStream{"EncryptionInfo", info}, cfbStream{"EncryptedPackage", encPkg})
+}
+
+func TestOpenEncrypted(t *testing.T) {
+	var ws bytes.Buffer
+	biff12WriteRec(&ws, 0x0181, nil)
+	biff12WriteRec(&ws, 0x0191, nil)
+	biff12WriteRec(&ws, 0x0000, biff12Le32(0))
+	var v [8]byte
+	binary.LittleEndian.PutUint64(v[:], math.Float64bits(42))
+	biff12WriteRec(&ws, 0x0005, concatBytes(biff12Le32(0), biff12Le32(0), v[:]))
+	biff12WriteRec(&ws, 0x0192, nil)
+	biff12WriteRec(&ws, 0x0182, nil)
+	// An incompressible part makes the package span several 4096-byte
+	// segments and regular (non-mini) sectors.
+	noise := make([]byte, 9000)
+	x := uint32(1)
+	for i := range noise {
+		x = x*1664525 + 1013904223
+		noise[i] = byte(x >> 24)
+	}
+	pkg := buildSheetXLSB(t, ws.Bytes(), map[string][]byte{"xl/media/noise.bin": noise})
+
+	check := func(t *testing.T, wb *workbook.Workbook, err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("open: %v", err)
+		}
+		defer wb.Close()
+		sheet, err := wb.Sheet(1)
+		if err != nil {
+			t.Fatalf("Sheet: %v", err)
+		}
+		c, err := sheet.Cell(0, 0)
+		if err != nil || c.V != 42.0 {
+			t.Errorf("Cell(0, 0) = %v, %v; want 42", c.V, err)
+		}
+	}
+
+	t.Run("agile", func(t *testing.T) {
+		data := encryptAgile(t, pkg, "pässwörd")
+		wb, err := xlsb.OpenReader(bytes.NewReader(data), int64(len(data)), workbook.WithPassword("pässwörd"))
+		check(t, wb, err)
+
+		if _, err := xlsb.OpenReader(bytes.NewReader(data), int64(len(data)), workbook.WithPassword("nope")); !errors.Is(err, xlsb.ErrWrongPassword) {
+			t.Errorf("wrong password: err = %v", err)
+		}
+		if _, err := xlsb.OpenReader(bytes.NewReader(data), int64(len(data))); !errors.Is(err, xlsb.ErrEncrypted) {
+			t.Errorf("no password: err = %v", err)
+		}
+	})
+
+	t.Run("standard file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "std.xlsb")
+		if err := os.WriteFile(path, encryptStandard(t, pkg, "secret"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		wb, err := xlsb.OpenWithPassword(path, "secret")
+		check(t, wb, err)
+		if _, err := xlsb.OpenWithPassword(path, "Secret"); !errors.Is(err, workbook.ErrWrongPassword) {
+			t.Errorf("wrong password: err = %v", err)
+		}
+	})
+
+	t.Run("default password", func(t *testing.T) {
+		data := encryptStandard(t, pkg, "VelvetSweatshop")
+		wb, err := xlsb.OpenReader(bytes.NewReader(data), int64(len(data)))
+		check(t, wb, err)
+	})
+
+	t.Run("unencrypted with password", func(t *testing.T) {
+		wb, err := xlsb.OpenReader(bytes.NewReader(pkg), int64(len(pkg)), workbook.WithPassword("ignored"))
+		check(t, wb, err)
+	})
+}
+
+// TestDecryptKnownAnswer decrypts the start of two packages encrypted by
+// other implementations with the password "password" — an Agile (AES-128,
+// SHA-1) package written by Excel and a Standard (AES-128) one, both from the
+// excelize test suite — so the key derivation is checked against data this
+// package's test helpers did not produce.  Only the EncryptionInfo stream
+// and the first 32 bytes of each EncryptedPackage are kept; they must decrypt
+// to the start of a ZIP local file header.
+func TestDecryptKnownAnswer(t *testing.T) {
+	unhex := func(s string) []byte {
+		b, err := hex.DecodeString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	agileXML := "<?xml version=\"1.0\" encoding=\"UTF-8\" standalone=\"yes\"?>\r\n<encryption " +
+		"xmlns=\"http://schemas.microsoft.com/office/2006/encryption\" " +
+		"xmlns:p=\"http://schemas.microsoft.com/office/2006/keyEncryptor/password\"><keyData " +
+		"saltSize=\"16\" blockSize=\"16\" keyBits=\"128\" hashSize=\"20\" cipherAlgorithm=\"AES\" " +
+		"cipherChaining=\"ChainingModeCBC\" hashAlgorithm=\"SHA1\" " +
+		"saltValue=\"k2gR7PkjsYvJ4akxbSkZRw==\"/><dataIntegrity " +
+		"encryptedHmacKey=\"ib1g3HWMSuedSW1lBdziwsSROcS7P1xZiccdQ6nxBeo=\" " +
+		"encryptedHmacValue=\"hGlWrkGZu+KmMExSFlDmPnqI6PzDrkZns5bcBlv6ep0=\"/><keyEncryptors>" +
+		"<keyEncryptor uri=\"http://schemas.microsoft.com/office/2006/keyEncryptor/password\">" +
+		"<p:encryptedKey spinCount=\"100000\" saltSize=\"16\" blockSize=\"16\" keyBits=\"128\" " +
+		"hashSize=\"20\" cipherAlgorithm=\"AES\" cipherChaining=\"ChainingModeCBC\" " +
+		"hashAlgorithm=\"SHA1\" saltValue=\"WooYCQ6hoDR2gER9zwDVKw==\" " +
+		"encryptedVerifierHashInput=\"bpzabwjHh+u+flxMtspzhg==\" " +
+		"encryptedVerifierHashValue=\"7D2rrrnhSYp/61Hgj6/uPi4GWZZ7if0a7+T/CPN12Kw=\" " +
+		"encryptedKeyValue=\"89l1lwkNYZBuk/kYNeuGWA==\"/></keyEncryptor></keyEncryptors>" +
+		"</encryption>"
+	tests := []struct {
+		name       string
+		info       []byte
+		ciphertext string
+		plaintext  string
+	}{
+		{
+			"agile",
+			concatBytes(biff12Le16(4), biff12Le16(4), biff12Le32(0x40), []byte(agileXML)),
+			"1506358b4e86a738c948fdc20606415e367f0bdb7116f02b89cb9f7d7237c620",
+			"504b03041400060008000000210062ee9d686101000090040000130008025b43",
+		},
+		{
+			"standard",
+			unhex("03000200240000008c00000024000000000000000e66000004800000800000001800000000000000000000004d006900" +
+				"630072006f0073006f0066007400200045006e00680061006e006300650064002000520053004100200061006e006400" +
+				"20004100450053002000430072007900700074006f0067007200610070006800690063002000500072006f0076006900" +
+				"64006500720000001000000028e022c5aca7932a37f964850364a86cf64026300a38b3568be647fb2734e38914000000" +
+				"b31ece0bc62f0418a19e196f24e558afdfc472bc10d7ce719da6714c04d7c1df"),
+			"6d425ca72fa1d2251e48d874fed5082c822a4a9b54a7a6b521e6e0562f3a5d55",
+			"504b0304140000080800402f215166aa82b7e00000003b0200000b0000005f72",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encPkg := concatBytes(binary.LittleEndian.AppendUint64(nil, 32), unhex(tt.ciphertext))
+			// Stream names are matched case-insensitively, as in cfb.ReadStream.
+			data := buildCFB(t, cfbStream{"encryptioninfo", tt.info}, cfbStream{"ENCRYPTEDPACKAGE", encPkg})
+			f, err := cfb.Open(bytes.NewReader(data), int64(len(data)))
+			if err != nil {
+				t.Fatalf("cfb.Open: %v", err)
+			}
+			if !offcrypto.IsEncryptedPackage(f) {
+				t.Error("IsEncryptedPackage = false")
+			}
+			got, err := offcrypto.Decrypt(f, "password")
+			if err != nil {
+				t.Fatalf("Decrypt: %v", err)
+			}
+			if want := unhex(tt.plaintext); !bytes.Equal(got, want) {
+				t.Errorf("Decrypt = %x, want %x", got, want)
+			}
+			if _, err := offcrypto.Decrypt(f, "Password"); !errors.Is(err, offcrypto.ErrPassword) {
+				t.Errorf("wrong password: err = %v", err)
+			}
+		})
+	}
+}
+
+// ── Format detection ──────────────────────────────────────────────────────────
+
+func TestDetect(t *testing.T) {
+	zipOf := func(names ...string) []byte {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		for _, n := range names {
+			zipAddFile(t, zw, n, []byte("<x/>"))
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+	xlsbData := buildMinimalXLSB(t)
+	// The same package with the workbook part stored as "XL/Workbook.bin".
+	mixedCase := func() []byte {
+		zr, err := zip.NewReader(bytes.NewReader(xlsbData), int64(len(xlsbData)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		for _, f := range zr.File {
+			rc, err := f.Open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := io.ReadAll(rc)
+			rc.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			name := f.Name
+			if name == "xl/workbook.bin" {
+				name = "XL/Workbook.bin"
+			}
+			zipAddFile(t, zw, name, data)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}()
+	tests := []struct {
+		name    string
+		data    []byte
+		want    xlsb.Format
+		openErr error // nil when Open succeeds
+	}{
+		{"xlsb", xlsbData, xlsb.FormatXLSB, nil},
+		{"xlsb mixed case", mixedCase, xlsb.FormatXLSB, nil},
+		{"xlsx", zipOf("[Content_Types].xml", "xl/workbook.xml"), xlsb.FormatXLSX, xlsb.ErrNotXLSB},
+		{"zip", zipOf("readme.txt"), xlsb.FormatZIP, xlsb.ErrNotXLSB},
+		{"xls", buildCFB(t, cfbStream{"Workbook", make([]byte, 100)}), xlsb.FormatXLS, xlsb.ErrNotXLSB},
+		{"encrypted", encryptAgile(t, xlsbData, "pw"), xlsb.FormatEncrypted, xlsb.ErrEncrypted},
+		{"text", []byte("id,name\n1,foo\n"), xlsb.FormatUnknown, xlsb.ErrNotXLSB},
+		{"empty", nil, xlsb.FormatUnknown, xlsb.ErrNotXLSB},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := xlsb.Detect(bytes.NewReader(tt.data), int64(len(tt.data)))
+			if err != nil || got != tt.want {
+				t.Errorf("Detect = %v, %v; want %v", got, err, tt.want)
+			}
+			wb, err := xlsb.OpenReader(bytes.NewReader(tt.data), int64(len(tt.data)))
+			if tt.openErr == nil {
+				if err != nil {
+					t.Fatalf("OpenReader: %v", err)
+				}
+				wb.Close()
+			} else if !errors.Is(err, tt.openErr) {
+				t.Errorf("OpenReader err = %v, want %v", err, tt.openErr)
+			}
+		})
+	}
+
+	path := filepath.Join(t.TempDir(), "book.xlsx")
+	if err := os.WriteFile(path, zipOf("xl/workbook.xml"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := xlsb.Open(path); !errors.Is(err, xlsb.ErrNotXLSB) {
+		t.Errorf("Open(.xlsx) err = %v, want ErrNotXLSB", err)
+	}
+	if got := xlsb.FormatXLS.String(); got != "xls" {
+		t.Errorf("FormatXLS.String() = %q", got)
+	}
+}
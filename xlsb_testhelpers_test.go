@@ -78,6 +78,16 @@ func biff12EncStr(s string) []byte {
 	return sb.Bytes()
 }
 
+// biff12RawID converts a record type number as listed in MS-XLSB (e.g. 427
+// for BrtShrFmla) into the raw ID form used by the biff12 constants, so that
+// tests can build records independently of those constants.
+func biff12RawID(n int) int {
+	if n < 0x80 {
+		return n
+	}
+	return n&0x7F | 0x80 | n>>7<<8
+}
+
 // biff12Le32 returns the little-endian 4-byte encoding of v.
 func biff12Le32(v uint32) []byte {
 	b := make([]byte, 4)
@@ -104,3 +114,42 @@ func zipAddFile(t *testing.T, zw *zip.Writer, name string, data []byte) {
 		t.Fatalf("zip write %s: %v", name, err)
 	}
 }
+
+// buildSheetXLSB assembles a minimal .xlsb ZIP with a single sheet named
+// "Sheet1" whose worksheet part is ws (the complete record stream, including
+// the WORKSHEET begin/end records).  Entries in parts are added verbatim and
+// may override the generated "xl/workbook.bin".
+func buildSheetXLSB(t *testing.T, ws []byte, parts map[string][]byte) []byte {
+	t.Helper()
+
+	var wb bytes.Buffer
+	biff12WriteRec(&wb, 0x0183, nil) // WORKBOOK start
+	biff12WriteRec(&wb, 0x018F, nil) // SHEETS start
+	var sheetRec bytes.Buffer
+	sheetRec.Write(biff12Le32(0))
+	sheetRec.Write(biff12Le32(1))
+	sheetRec.Write(biff12EncStr("rId1"))
+	sheetRec.Write(biff12EncStr("Sheet1"))
+	biff12WriteRec(&wb, 0x019C, sheetRec.Bytes())
+	biff12WriteRec(&wb, 0x0190, nil) // SHEETS end
+	biff12WriteRec(&wb, 0x0184, nil) // WORKBOOK end
+
+	var zipBuf bytes.Buffer
+	zw := zip.NewWriter(&zipBuf)
+	relsXML := `<?xml version="1.0" encoding="UTF-8"?>` +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="worksheet" Target="worksheets/sheet1.bin"/>` +
+		`</Relationships>`
+	zipAddFile(t, zw, "xl/_rels/workbook.bin.rels", []byte(relsXML))
+	if _, ok := parts["xl/workbook.bin"]; !ok {
+		zipAddFile(t, zw, "xl/workbook.bin", wb.Bytes())
+	}
+	zipAddFile(t, zw, "xl/worksheets/sheet1.bin", ws)
+	for name, data := range parts {
+		zipAddFile(t, zw, name, data)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zip close: %v", err)
+	}
+	return zipBuf.Bytes()
+}